@@ -0,0 +1,10 @@
+// Command srclib-bash is the srclib toolchain for Bash. Its analysis is
+// implemented by the bashgraph package, and its command line by the cli
+// package.
+package main
+
+import "sourcegraph.com/sourcegraph/srclib-bash/pkg/cli"
+
+func main() {
+	cli.Main()
+}
@@ -0,0 +1,124 @@
+package bashgraph
+
+import (
+	"encoding/json"
+	"strings"
+
+	"sourcegraph.com/sourcegraph/srclib/graph"
+)
+
+// An aliasSite is the definition of an alias by the alias builtin.
+type aliasSite struct {
+	name string
+	// start and end are the offsets of the alias's name.
+	start, end int
+}
+
+// parseAliases finds the alias definitions in s.
+func parseAliases(s *script) {
+	for _, cmd := range s.commands {
+		if unquote(cmd.text) != "alias" {
+			continue
+		}
+		for _, a := range commandArgs(s.words, cmd) {
+			eq := strings.IndexByte(a.text, '=')
+			if eq <= 0 || strings.HasPrefix(a.text, "-") {
+				continue
+			}
+			s.aliases = append(s.aliases, &aliasSite{
+				name:  unquote(a.text[:eq]),
+				start: a.start,
+				end:   a.start + eq,
+			})
+		}
+	}
+}
+
+// An aliasDef is an alias definition in a file of a source unit.
+type aliasDef struct {
+	file *parsedFile
+	src  *source
+	site *aliasSite
+}
+
+// defPath returns the DefPath of the alias.
+func (d *aliasDef) defPath() string {
+	return d.file.defPath() + "/alias:" + d.site.name
+}
+
+// An aliasIndex holds the first definition of every alias in each file of
+// a source unit, in unit order.
+type aliasIndex map[string][]*aliasDef
+
+func newAliasIndex(files []*parsedFile) aliasIndex {
+	idx := aliasIndex{}
+	for _, f := range files {
+		seen := map[string]bool{}
+		for i, s := range f.scripts {
+			for _, site := range s.aliases {
+				if seen[site.name] {
+					continue
+				}
+				seen[site.name] = true
+				idx[site.name] = append(idx[site.name], &aliasDef{file: f, src: f.sources[i], site: site})
+			}
+		}
+	}
+	return idx
+}
+
+// resolve returns the definition of the named alias used in file f,
+// preferring definitions in f itself.
+func (idx aliasIndex) resolve(name string, f *parsedFile) *aliasDef {
+	defs := idx[name]
+	for _, d := range defs {
+		if d.file == f {
+			return d
+		}
+	}
+	if len(defs) > 0 {
+		return defs[0]
+	}
+	return nil
+}
+
+func makeAliasDef(d *aliasDef) (*graph.Def, error) {
+	data, err := json.Marshal(DefData{
+		Name:    d.site.name,
+		Keyword: "alias",
+		Kind:    "alias",
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &graph.Def{
+		DefKey: graph.DefKey{
+			UnitType: "BashDirectory",
+			Unit:     "bash",
+			Path:     d.defPath(),
+		},
+		TreePath: treePath(d.defPath()),
+		Name:     d.site.name,
+		Kind:     "alias",
+		File:     d.file.name,
+		DefStart: uint32(d.src.fileOffset(d.site.start)),
+		DefEnd:   uint32(d.src.fileEnd(d.site.end)),
+		Data:     data,
+	}, nil
+}
+
+// makeAliasRef returns a ref to the alias d from the span start:end of the
+// source src of the named file.
+func makeAliasRef(filename string, src *source, start, end int, d *aliasDef, isDef bool) *graph.Ref {
+	return &graph.Ref{
+		DefUnitType: "BashDirectory",
+		DefUnit:     "bash",
+		DefPath:     d.defPath(),
+		UnitType:    "BashDirectory",
+		Unit:        "bash",
+		Def:         isDef,
+		File:        filename,
+		Start:       uint32(src.fileOffset(start)),
+		End:         uint32(src.fileEnd(end)),
+	}
+}
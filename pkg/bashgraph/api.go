@@ -0,0 +1,132 @@
+package bashgraph
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"sourcegraph.com/sourcegraph/srclib/graph"
+	"sourcegraph.com/sourcegraph/srclib/unit"
+)
+
+// A Result is the analysis of a set of Bash scripts.
+type Result struct {
+	Defs []*graph.Def
+	Refs []*graph.Ref
+	Docs []*graph.Doc
+	// Diagnostics are the problems found in the scripts, such as code
+	// passed to eval that couldn't be parsed.
+	Diagnostics []*FileDiagnostic
+	// Warnings are the issues with how well the scripts were analyzed.
+	Warnings []*Warning
+}
+
+// A FileDiagnostic is a Diagnostic in the named file.
+type FileDiagnostic struct {
+	File string
+	*Diagnostic
+}
+
+// An Analyzer analyzes Bash scripts with a set of options. The zero
+// Analyzer uses the defaults, with settings left unset read from the
+// SRCLIB_BASH_* environment variables and the global config file, as the
+// graph command does without flags. Analyzers don't share state, so
+// several may be used at once, each from any number of goroutines.
+type Analyzer struct {
+	Options Options
+}
+
+// Analyze graphs the named files as a single source unit, so calls,
+// variables and aliases resolve across them. Files that cannot be read are
+// skipped with a warning.
+func (a *Analyzer) Analyze(files []string) (*Result, error) {
+	r := new(Result)
+	opts, err := a.options(r)
+	if err != nil {
+		return nil, err
+	}
+	u := &unit.SourceUnit{
+		Key:  unit.Key{Name: "bash", Type: "BashDirectory"},
+		Info: unit.Info{Files: files},
+	}
+	out, err := graphUnits(unit.SourceUnits{u}, opts)
+	if err != nil {
+		return nil, err
+	}
+	return r.add(out)
+}
+
+// AnalyzeFile graphs data, the contents of the named file, on its own. The
+// file need not exist, so unsaved editor buffers can be analyzed.
+func (a *Analyzer) AnalyzeFile(name string, data []byte) (*Result, error) {
+	r := new(Result)
+	opts, err := a.options(r)
+	if err != nil {
+		return nil, err
+	}
+	out, err := graphBuffer(name, data, opts)
+	if err != nil {
+		return nil, err
+	}
+	return r.add(out)
+}
+
+// options returns a copy of a's options with the settings left unset
+// filled in, so that a itself is not changed. The warnings about the
+// analysis are added to r as analysis-error Warnings, as well as passed to
+// a's Warnf.
+func (a *Analyzer) options(r *Result) (*Options, error) {
+	opts := a.Options
+	if err := opts.applySettings(nil); err != nil {
+		return nil, err
+	}
+	// Files are parsed in parallel, so warnings may come from several
+	// goroutines.
+	var mu sync.Mutex
+	warnf := a.Options.Warnf
+	opts.Warnf = func(format string, args ...interface{}) {
+		mu.Lock()
+		defer mu.Unlock()
+		r.Warnings = append(r.Warnings, &Warning{Code: "analysis-error", Message: fmt.Sprintf(format, args...)})
+		if warnf != nil {
+			warnf(format, args...)
+		}
+	}
+	return &opts, nil
+}
+
+// Analyze analyzes the named files with the zero Analyzer.
+func Analyze(files []string) (*Result, error) {
+	return new(Analyzer).Analyze(files)
+}
+
+// AnalyzeFile analyzes data, the contents of the named file, with the zero
+// Analyzer.
+func AnalyzeFile(name string, data []byte) (*Result, error) {
+	return new(Analyzer).AnalyzeFile(name, data)
+}
+
+// add adds the graph output out to r, and returns r.
+func (r *Result) add(out *graphOutput) (*Result, error) {
+	r.Defs = append(r.Defs, out.Defs...)
+	r.Refs = append(r.Refs, out.Refs...)
+	r.Docs = append(r.Docs, out.Docs...)
+	r.Warnings = append(r.Warnings, out.warnings...)
+	for _, a := range out.Anns {
+		if a.Type != diagnosticAnnType {
+			continue
+		}
+		var d Diagnostic
+		if err := json.Unmarshal(a.Data, &d); err != nil {
+			return nil, fmt.Errorf("Failed to decode diagnostic: %s", err)
+		}
+		r.Diagnostics = append(r.Diagnostics, &FileDiagnostic{File: a.File, Diagnostic: &d})
+	}
+	return r, nil
+}
+
+// Index returns the index of r's defs and refs, to look them up by name,
+// def, file and position.
+func (r *Result) Index() *SymbolIndex {
+	return NewSymbolIndex(r.Defs, r.Refs)
+}
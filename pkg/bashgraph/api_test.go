@@ -0,0 +1,116 @@
+package bashgraph
+
+import (
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestAnalyze(t *testing.T) {
+	r, err := Analyze([]string{filepath.Join("testdata", "graph", "functions.sh")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := false
+	for _, d := range r.Defs {
+		if d.Name == "deploy-app" && d.Kind == "func" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("no def of deploy-app in %d defs", len(r.Defs))
+	}
+	if len(r.Refs) == 0 {
+		t.Error("no refs")
+	}
+}
+
+func TestAnalyzeMissingFile(t *testing.T) {
+	missing := filepath.Join("testdata", "graph", "missing.sh")
+	var logged []string
+	a := &Analyzer{Options: Options{Warnf: func(format string, args ...interface{}) {
+		logged = append(logged, format)
+	}}}
+	r, err := a.Analyze([]string{filepath.Join("testdata", "graph", "functions.sh"), missing})
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := false
+	for _, w := range r.Warnings {
+		if w.Code == "analysis-error" && strings.Contains(w.Message, missing) {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("no warning about %s in %d warnings", missing, len(r.Warnings))
+	}
+	if len(logged) == 0 {
+		t.Error("the Analyzer's Warnf wasn't called")
+	}
+	if len(r.Defs) == 0 {
+		t.Error("the files that could be read weren't graphed")
+	}
+}
+
+func TestAnalyzeFile(t *testing.T) {
+	r, err := AnalyzeFile("buffer.sh", []byte("greet() { echo hi; }\ngreet\neval 'if'\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	calls := 0
+	for _, ref := range r.Refs {
+		if ref.DefPath == "buffer.sh/greet" && !ref.Def {
+			calls++
+		}
+	}
+	if calls != 1 {
+		t.Errorf("got %d refs to greet, want 1", calls)
+	}
+	if len(r.Diagnostics) == 0 {
+		t.Error("no diagnostic for the unparseable eval string")
+	}
+}
+
+// TestAnalyzerOptions checks that each Analyzer graphs with its own
+// options, so analyzers used at once don't affect each other.
+func TestAnalyzerOptions(t *testing.T) {
+	data := []byte("if true; then echo yes; fi\n")
+	keywordRefs := func(r *Result) int {
+		n := 0
+		for _, ref := range r.Refs {
+			if path.Base(ref.DefPath) == "if" {
+				n++
+			}
+		}
+		return n
+	}
+	withKeywords := &Analyzer{Options: Options{Keywords: true}}
+	analyzers := []*Analyzer{withKeywords, new(Analyzer)}
+	results := make([]*Result, len(analyzers))
+	errs := make([]error, len(analyzers))
+	var wg sync.WaitGroup
+	for i, a := range analyzers {
+		wg.Add(1)
+		go func(i int, a *Analyzer) {
+			defer wg.Done()
+			results[i], errs[i] = a.AnalyzeFile("keywords.sh", data)
+		}(i, a)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := keywordRefs(results[0]); n != 1 {
+		t.Errorf("with Keywords: got %d refs to if, want 1", n)
+	}
+	if n := keywordRefs(results[1]); n != 0 {
+		t.Errorf("without Keywords: got %d refs to if, want none", n)
+	}
+	if withKeywords.Options.LocalManRepo != "" {
+		t.Errorf("analyzing changed the Analyzer's options to %+v", withKeywords.Options)
+	}
+}
@@ -0,0 +1,243 @@
+package bashgraph
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// A bashFeature is a use of a feature of Bash that POSIX sh doesn't have.
+type bashFeature struct {
+	// name describes the feature, as in "associative array (-A)".
+	name string
+	// version is the Bash version that introduced the feature, or "" if
+	// all versions in use have it.
+	version string
+	// start and end are the offsets of the use in its file.
+	start, end int
+}
+
+// bashCommandVersions are the Bash versions that introduced builtins and
+// keywords.
+var bashCommandVersions = map[string]string{
+	"readarray": "4.0", "mapfile": "4.0", "coproc": "4.0",
+}
+
+// bashCommands are the builtins and keywords of Bash that POSIX sh doesn't
+// have and that every Bash version in use has.
+var bashCommands = map[string]bool{
+	"source": true, "declare": true, "typeset": true, "shopt": true, "let": true,
+	"pushd": true, "popd": true, "dirs": true, "disown": true, "select": true,
+}
+
+// declOptVersions are the Bash versions that introduced the options of
+// declare, typeset and local.
+var declOptVersions = map[byte]struct{ name, version string }{
+	'A': {"associative array (-A)", "4.0"},
+	'l': {"lowercase attribute (-l)", "4.0"},
+	'u': {"uppercase attribute (-u)", "4.0"},
+	'g': {"global declaration (-g)", "4.2"},
+	'n': {"nameref (-n)", "4.3"},
+}
+
+// shoptVersions are the Bash versions that introduced shell options.
+var shoptVersions = map[string]string{
+	"globstar": "4.0", "autocd": "4.0", "checkjobs": "4.0", "dirspell": "4.0",
+	"lastpipe": "4.2", "direxpand": "4.2",
+	"inherit_errexit": "4.4", "localvar_inherit": "5.0", "assoc_expand_once": "5.0",
+}
+
+// bashVarVersions are the Bash versions that introduced special variables.
+var bashVarVersions = map[string]string{
+	"EPOCHSECONDS": "5.0", "EPOCHREALTIME": "5.0", "BASH_ARGV0": "5.0",
+	"SRANDOM": "5.1",
+}
+
+// braceRange matches brace expansions of sequences, as in {1..10}.
+var braceRange = regexp.MustCompile(`\{[^{}\s]*\.\.[^{}\s]*\}`)
+
+// caseModification and transformation match the expansions that change
+// the case of a value, as in ${name,,} or ${1^}, and that transform it, as
+// in ${name@Q}.
+var (
+	caseModification = regexp.MustCompile(`\$\{[A-Za-z0-9_@*]+(\[[^]]*\])?[,^]`)
+	transformation   = regexp.MustCompile(`\$\{[A-Za-z0-9_@*]+(\[[^]]*\])?@[QEPAaKkUuL]\}`)
+)
+
+// commandKeywords are the reserved words that a command may follow.
+var commandKeywords = map[string]bool{
+	"if": true, "then": true, "elif": true, "else": true, "while": true, "until": true,
+	"do": true, "!": true, "{": true, "time": true,
+}
+
+// posixShells are the interpreters of #! lines that run POSIX sh rather
+// than Bash.
+var posixShells = map[string]bool{
+	"sh": true, "dash": true, "ash": true, "posh": true,
+}
+
+// bashFeatures returns the uses of Bash features in f, which is written for
+// Bash, in the order they appear in each of its sources.
+func bashFeatures(f *parsedFile) []*bashFeature {
+	if f.dialect != "bash" {
+		return nil
+	}
+	var features []*bashFeature
+	for i, s := range f.scripts {
+		src := f.sources[i]
+		add := func(name, version string, start, end int) {
+			features = append(features, &bashFeature{name: name, version: version, start: src.fileOffset(start), end: src.fileEnd(end)})
+		}
+
+		for j, w := range s.words {
+			var next *word
+			if j+1 < len(s.words) && s.words[j+1].start == w.end {
+				next = &s.words[j+1]
+			}
+			atCommand := j == 0 || s.words[j-1].isControlOp() || !s.words[j-1].op && commandKeywords[s.words[j-1].text]
+			switch {
+			case w.op && w.text == "&>" && next != nil && next.text == ">":
+				add("append of both outputs (&>>)", "4.0", w.start, next.end)
+			case w.op && w.text == "&>":
+				add("redirection of both outputs (&>)", "", w.start, w.end)
+			case w.op && w.text == "|&":
+				add("pipe of both outputs (|&)", "4.0", w.start, w.end)
+			case w.op && w.text == "<<<":
+				add("here-string (<<<)", "", w.start, w.end)
+			case w.op && (w.text == ";" || w.text == ";;") && next != nil && next.text == "&":
+				add("case fall-through ("+w.text+"&)", "4.0", w.start, next.end)
+			case w.op:
+			case atCommand && (w.text == "[[" || w.text == "function"):
+				add(w.text+" keyword", "", w.start, w.end)
+			case atCommand && strings.HasPrefix(w.text, "(("):
+				add("arithmetic command ((...))", "", w.start, w.end)
+			case next != nil && next.op && strings.HasPrefix(next.text, ">") && isFDVariable(w.text):
+				add("file descriptor allocation ({var}>)", "4.1", w.start, w.end)
+			case isProcessSubstitution(w):
+				add("process substitution", "", w.start, w.end)
+			case strings.Contains(w.text, "=(") && isArrayAssignment(w.text[:strings.Index(w.text, "=(")+1]):
+				add("array", "", w.start, w.end)
+			case strings.Contains(w.text, "$'"):
+				add("ANSI-C quoting ($'...')", "", w.start, w.end)
+			case !strings.ContainsAny(w.text, `"'`) && braceRange.MatchString(w.text):
+				add("brace expansion ({a..b})", "", w.start, w.end)
+			case caseModification.MatchString(w.text):
+				add("case modification (${name,,})", "4.0", w.start, w.end)
+			case transformation.MatchString(w.text):
+				add("parameter transformation (${name@Q})", "4.4", w.start, w.end)
+			}
+		}
+
+		for _, cmd := range s.commands {
+			name := unquote(cmd.text)
+			args := commandArgs(s.words, cmd)
+			switch {
+			case bashCommandVersions[name] != "":
+				add(name, bashCommandVersions[name], cmd.start, cmd.end)
+			case name == "wait" && len(args) > 0 && unquote(args[0].text) == "-n":
+				add("wait -n", "4.3", cmd.start, args[0].end)
+			case name == "shopt":
+				add(name, "", cmd.start, cmd.end)
+				for _, a := range args {
+					if v := shoptVersions[unquote(a.text)]; v != "" {
+						add("shell option "+unquote(a.text), v, a.start, a.end)
+					}
+				}
+			case bashCommands[name]:
+				add(name, "", cmd.start, cmd.end)
+			}
+			if name == "declare" || name == "typeset" || name == "local" {
+				for _, a := range args {
+					opts := unquote(a.text)
+					if !strings.HasPrefix(opts, "-") {
+						break
+					}
+					for k := 1; k < len(opts); k++ {
+						if o, ok := declOptVersions[opts[k]]; ok {
+							add(o.name, o.version, a.start, a.end)
+						}
+					}
+				}
+			}
+		}
+
+		for _, ref := range s.varRefs {
+			if v := bashVarVersions[ref.name]; v != "" {
+				add("$"+ref.name, v, ref.start, ref.end)
+			}
+			if ref.start < 2 {
+				continue
+			}
+			if src.text[ref.start-1] == '!' && src.text[ref.start-2] == '{' {
+				add("indirect expansion (${!name})", "", ref.start, ref.end)
+				continue
+			}
+			if src.text[ref.start-1] != '{' {
+				continue
+			}
+			rest := src.text[ref.end:]
+			switch {
+			case strings.HasPrefix(rest, "/"):
+				add("pattern substitution (${name/pattern/string})", "", ref.start, ref.end)
+			case strings.HasPrefix(rest, "["):
+				add("array element (${name[i]})", "", ref.start, ref.end)
+			case len(rest) > 1 && rest[0] == ':' && strings.IndexByte("-=?+", rest[1]) < 0:
+				add("substring expansion (${name:offset})", "", ref.start, ref.end)
+			}
+		}
+	}
+	return features
+}
+
+// isFDVariable reports whether text is a variable that a redirection
+// allocates a file descriptor to, as in {fd}>file.
+func isFDVariable(text string) bool {
+	return len(text) > 2 && text[0] == '{' && text[len(text)-1] == '}' && isName(text[1:len(text)-1])
+}
+
+// minBashVersion returns the oldest Bash version that has all the features
+// that f uses, or "" if any version does.
+func minBashVersion(f *parsedFile) string {
+	min := ""
+	for _, feat := range bashFeatures(f) {
+		// Versions are all of the form N.N, so they compare as strings.
+		if feat.version > min {
+			min = feat.version
+		}
+	}
+	return min
+}
+
+// diagnoseBashisms adds a diagnostic annotation to output for each Bash
+// feature used in f, if its #! line runs a POSIX shell such as /bin/sh,
+// which may not be Bash.
+func diagnoseBashisms(f *parsedFile, output *graphOutput) error {
+	line, ok := shebangLine(f.data)
+	if !ok {
+		return nil
+	}
+	interp := shebangInterpreter(line)
+	if !posixShells[interp] {
+		return nil
+	}
+	features := bashFeatures(f)
+	if len(features) == 0 {
+		return nil
+	}
+	lines := newLineIndex(f.data)
+	for _, feat := range features {
+		a, err := makeDiagnosticAnn(f.name, lines, &Diagnostic{
+			Source:  "shellcompat",
+			Code:    "bashism",
+			Level:   "warning",
+			Message: fmt.Sprintf("%s is a Bash feature, but the #! line runs %s", feat.name, interp),
+			Start:   uint32(feat.start),
+			End:     uint32(feat.end),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create bashism diagnostic: %s", err)
+		}
+		output.Anns = append(output.Anns, a)
+	}
+	return nil
+}
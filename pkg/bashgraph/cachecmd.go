@@ -0,0 +1,108 @@
+package bashgraph
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+type CacheCmd struct{}
+
+type CacheCleanCmd struct {
+	OlderThan time.Duration `long:"older-than" description:"only remove the entries that haven't been used for DURATION, such as 720h" value-name:"DURATION"`
+}
+
+type CacheStatsCmd struct {
+	JSON bool `long:"json" description:"output the stats as JSON"`
+}
+
+// CacheStats describes the cache of analyzed files.
+type CacheStats struct {
+	Dir     string
+	Entries int
+	Bytes   int64
+	// Oldest and Newest are when the least and most recently used entries
+	// were last used.
+	Oldest time.Time
+	Newest time.Time
+}
+
+func (c *CacheCleanCmd) Execute(args []string) error {
+	dir := commandOptions().cacheDir()
+	if dir == "" {
+		return fmt.Errorf("The cache is off")
+	}
+	removed := 0
+	cutoff := time.Now().Add(-c.OlderThan)
+	err := walkCache(dir, func(path string, info os.FileInfo) error {
+		if c.OlderThan > 0 && info.ModTime().After(cutoff) {
+			return nil
+		}
+		if err := os.Remove(path); err != nil {
+			return err
+		}
+		removed++
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("Failed to clean cache %s: %s", dir, err)
+	}
+	logInfof("Removed %d entries from %s", removed, dir)
+	return nil
+}
+
+func (c *CacheStatsCmd) Execute(args []string) error {
+	dir := commandOptions().cacheDir()
+	if dir == "" {
+		return fmt.Errorf("The cache is off")
+	}
+	stats := &CacheStats{Dir: dir}
+	err := walkCache(dir, func(path string, info os.FileInfo) error {
+		stats.Entries++
+		stats.Bytes += info.Size()
+		if t := info.ModTime(); stats.Oldest.IsZero() || t.Before(stats.Oldest) {
+			stats.Oldest = t
+		}
+		if t := info.ModTime(); t.After(stats.Newest) {
+			stats.Newest = t
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("Failed to read cache %s: %s", dir, err)
+	}
+
+	if c.JSON {
+		if err := json.NewEncoder(os.Stdout).Encode(stats); err != nil {
+			return fmt.Errorf("Failed to output cache stats: %s", err)
+		}
+		return nil
+	}
+	fmt.Printf("Directory: %s\n", stats.Dir)
+	fmt.Printf("Entries:   %d\n", stats.Entries)
+	fmt.Printf("Size:      %d bytes\n", stats.Bytes)
+	if stats.Entries > 0 {
+		fmt.Printf("Oldest:    %s\n", stats.Oldest.Format(time.RFC3339))
+		fmt.Printf("Newest:    %s\n", stats.Newest.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// walkCache calls fn for each entry of the cache in dir, which may not
+// exist yet.
+func walkCache(dir string, fn func(path string, info os.FileInfo) error) error {
+	return filepath.Walk(filepath.Join(dir, "parse"), func(path string, info os.FileInfo, err error) error {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		return fn(path, info)
+	})
+}
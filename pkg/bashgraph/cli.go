@@ -0,0 +1,10 @@
+package bashgraph
+
+// SetGlobalOptions sets the options that every command of the srclib-bash
+// command line tool takes, which it sets before running one: the cache of
+// analyzed files of the commands not given Options of their own, and what
+// is logged. Library users set Options instead.
+func SetGlobalOptions(cache CacheOptions, log LogOptions) {
+	cacheOpts = cache
+	logOpts = log
+}
@@ -0,0 +1,89 @@
+package bashgraph
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"sourcegraph.com/sourcegraph/srclib/graph"
+	"sourcegraph.com/sourcegraph/srclib/unit"
+)
+
+// commandMapConfigKey is the key of the Srcfile Config setting that names
+// a command map file.
+const commandMapConfigKey = "bashCommandMap"
+
+// A CommandTarget is the def that runs of a command are linked to.
+type CommandTarget struct {
+	DefRepo     string
+	DefUnitType string
+	DefUnit     string
+	DefPath     string
+}
+
+// A commandMap maps command names to the defs they are linked to, taking
+// precedence over man pages. It lets organizations link their internal
+// tools to their own documentation.
+type commandMap map[string]*CommandTarget
+
+// readCommandMap reads a command map from the named JSON file, which holds
+// an object mapping command names to CommandTargets.
+func readCommandMap(name string) (commandMap, error) {
+	data, err := ioutil.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	var m commandMap
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+	for cmd, t := range m {
+		if t == nil || t.DefRepo == "" || t.DefUnitType == "" || t.DefUnit == "" || t.DefPath == "" {
+			return nil, fmt.Errorf("target of command %s must set DefRepo, DefUnitType, DefUnit and DefPath", cmd)
+		}
+	}
+	return m, nil
+}
+
+// unitCommandMap returns the command map for u: the [commands] entries of
+// the global config file, overridden by the one its command_map names,
+// overridden by the one named in u's config (set in the Srcfile),
+// overridden by the one named by the SRCLIB_BASH_COMMAND_MAP environment
+// variable, overridden by flag, the one given to the graph command's
+// --command-map option.
+func unitCommandMap(u *unit.SourceUnit, flag string) (commandMap, error) {
+	m := commandMap{}
+	for cmd, t := range loadGlobalConfig().commands {
+		m[cmd] = t
+	}
+	for _, name := range []string{globalSetting("COMMAND_MAP"), u.Config[commandMapConfigKey], os.Getenv(envPrefix + "COMMAND_MAP"), flag} {
+		if name == "" {
+			continue
+		}
+		cm, err := readCommandMap(name)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to read command map %s: %s", name, err)
+		}
+		for cmd, t := range cm {
+			m[cmd] = t
+		}
+	}
+	return m, nil
+}
+
+// makeTargetRef returns a ref from the command name cmd in the source src
+// of the named file to the target t.
+func makeTargetRef(filename string, src *source, cmd word, t *CommandTarget) *graph.Ref {
+	return &graph.Ref{
+		DefRepo:     t.DefRepo,
+		DefUnitType: t.DefUnitType,
+		DefUnit:     t.DefUnit,
+		DefPath:     t.DefPath,
+		UnitType:    "BashDirectory",
+		Unit:        "bash",
+		File:        filename,
+		Start:       uint32(src.fileOffset(cmd.start)),
+		End:         uint32(src.fileEnd(cmd.end)),
+	}
+}
@@ -0,0 +1,68 @@
+package bashgraph
+
+import "strings"
+
+// A completion is the registration of a completion function for commands,
+// as in complete -F _mytool mytool.
+type completion struct {
+	src *source
+	// handler is the name of the completion function.
+	handler word
+	// commands are the names of the commands it completes.
+	commands []word
+}
+
+// completeOptsWithArg are the options of the complete builtin that take an
+// argument.
+const completeOptsWithArg = "oAGWFCXPS"
+
+// completions returns the completion functions registered in f with
+// complete -F.
+func completions(f *parsedFile) []*completion {
+	var comps []*completion
+	for i, s := range f.scripts {
+		for _, cmd := range s.commands {
+			if unquote(cmd.text) != "complete" {
+				continue
+			}
+			c := &completion{src: f.sources[i]}
+			args := commandArgs(s.words, cmd)
+			for j := 0; j < len(args); j++ {
+				a := args[j]
+				if strings.HasPrefix(a.text, "-") && len(a.text) > 1 {
+					opt := a.text[len(a.text)-1]
+					if strings.IndexByte(completeOptsWithArg, opt) >= 0 && j+1 < len(args) {
+						if opt == 'F' {
+							c.handler = args[j+1]
+						}
+						j++
+					}
+					continue
+				}
+				c.commands = append(c.commands, a)
+			}
+			if c.handler.text != "" && isLiteralCommand(c.handler) {
+				comps = append(comps, c)
+			}
+		}
+	}
+	return comps
+}
+
+// completedCommands returns the names of the commands that each function
+// in funcs completes, by the function, as registered in files.
+func completedCommands(files []*parsedFile, funcs funcIndex) map[*function][]string {
+	completed := map[*function][]string{}
+	for _, f := range files {
+		for _, c := range completions(f) {
+			d := funcs.resolve(unquote(c.handler.text), f)
+			if d == nil {
+				continue
+			}
+			for _, cmd := range c.commands {
+				completed[d.fn] = append(completed[d.fn], unquote(cmd.text))
+			}
+		}
+	}
+	return completed
+}
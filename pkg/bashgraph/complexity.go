@@ -0,0 +1,57 @@
+package bashgraph
+
+// branchWords are the reserved words that add a decision point to a
+// function.
+var branchWords = map[string]bool{
+	"elif":   true,
+	"for":    true,
+	"if":     true,
+	"select": true,
+	"until":  true,
+	"while":  true,
+}
+
+// complexity returns the cyclomatic complexity of fn, defined in s: one
+// plus the number of if, elif, loop and case branches and && and || lists
+// in its body. Branches in nested functions count only towards the nested
+// function.
+func (s *script) complexity(fn *function) int {
+	n := 1
+	pos := commandPositions(s.words)
+	// cases holds, for each case command being scanned, whether a pattern
+	// list is expected next.
+	var cases []bool
+	for i, w := range s.words {
+		if w.start < fn.start || w.end > fn.end {
+			continue
+		}
+		inFunc := s.enclosingFunc(w) == fn
+		inPattern := len(cases) > 0 && cases[len(cases)-1]
+		switch {
+		case w.op && inPattern:
+			if w.text == ")" {
+				cases[len(cases)-1] = false
+				if inFunc {
+					n++
+				}
+			}
+		case w.op && (w.text == ";;" || w.text == ";&" || w.text == ";;&"):
+			if len(cases) > 0 {
+				cases[len(cases)-1] = true
+			}
+		case w.op && (w.text == "&&" || w.text == "||"):
+			if inFunc {
+				n++
+			}
+		case w.op:
+		case w.text == "esac" && (inPattern || pos[i]) && len(cases) > 0:
+			cases = cases[:len(cases)-1]
+		case inPattern:
+		case pos[i] && w.text == "case":
+			cases = append(cases, true)
+		case pos[i] && branchWords[w.text] && inFunc:
+			n++
+		}
+	}
+	return n
+}
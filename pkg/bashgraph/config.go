@@ -0,0 +1,147 @@
+package bashgraph
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+
+	"sourcegraph.com/sourcegraph/srclib/unit"
+)
+
+// envPrefix is the prefix of the environment variables that configure the
+// toolchain, such as SRCLIB_BASH_EXCLUDES. Settings given with flags take
+// precedence over the environment, which takes precedence over the
+// Srcfile's Config, which takes precedence over the global config file.
+const envPrefix = "SRCLIB_BASH_"
+
+// stringSetting returns the value of a setting: flag if it is set, or else
+// the value of the environment variable envPrefix+env if it is set, or else
+// the value of key in config, a Srcfile's Config, if it is set, or else the
+// value in the global config file.
+func stringSetting(flag, env string, config map[string]string, key string) string {
+	if flag != "" {
+		return flag
+	}
+	if v := os.Getenv(envPrefix + env); v != "" {
+		return v
+	}
+	if v := config[key]; v != "" {
+		return v
+	}
+	return globalSetting(env)
+}
+
+// listSetting returns the value of a setting that is a list, with the same
+// precedence as stringSetting. Lists in the environment and in config are
+// separated by commas, or are JSON arrays.
+func listSetting(flag []string, env string, config map[string]string, key string) []string {
+	if len(flag) > 0 {
+		return flag
+	}
+	return splitList(stringSetting("", env, config, key))
+}
+
+// intSetting returns the value of a setting that is a number, with the same
+// precedence as stringSetting, where a flag of 0 is not set.
+func intSetting(flag int, env string, config map[string]string, key string) (int, error) {
+	if flag != 0 {
+		return flag, nil
+	}
+	s := stringSetting("", env, config, key)
+	if s == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("Invalid %s setting %q: %s", strings.ToLower(env), s, err)
+	}
+	return n, nil
+}
+
+// splitList splits s, a comma-separated list or a JSON array of strings,
+// into its non-empty elements.
+func splitList(s string) []string {
+	var list []string
+	if strings.HasPrefix(strings.TrimSpace(s), "[") && json.Unmarshal([]byte(s), &list) == nil {
+		return list
+	}
+	for _, e := range strings.Split(s, ",") {
+		if e = strings.TrimSpace(e); e != "" {
+			list = append(list, e)
+		}
+	}
+	return list
+}
+
+// checkChoice checks that the value of the named setting is one of choices.
+func checkChoice(name, value string, choices ...string) error {
+	for _, c := range choices {
+		if value == c {
+			return nil
+		}
+	}
+	return fmt.Errorf("Invalid %s setting %q: must be one of %s", name, value, strings.Join(choices, ", "))
+}
+
+// unitsConfig returns the Srcfile Config of units. srclib copies the same
+// Config to every source unit, so it is that of the first.
+func unitsConfig(units unit.SourceUnits) map[string]string {
+	if len(units) == 0 {
+		return nil
+	}
+	return units[0].Config
+}
+
+// readTreeConfig reads the Config of the source tree that srclib passes to
+// scan on STDIN, as a JSON object with a Config property, converting its
+// values to strings. It returns nil if STDIN is a terminal or empty.
+func readTreeConfig() (map[string]string, error) {
+	if info, err := os.Stdin.Stat(); err != nil || info.Mode()&os.ModeCharDevice != 0 {
+		return nil, nil
+	}
+	data, err := ioutil.ReadAll(os.Stdin)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read STDIN: %s", err)
+	}
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil, nil
+	}
+	var tree struct {
+		Config map[string]interface{}
+	}
+	if err := json.Unmarshal(data, &tree); err != nil {
+		return nil, fmt.Errorf("Failed to parse the source tree config on STDIN: %s", err)
+	}
+	return stringConfig(tree.Config)
+}
+
+// stringConfig returns the source tree config raw, as decoded from JSON,
+// with its values converted to strings by configString.
+func stringConfig(raw map[string]interface{}) (map[string]string, error) {
+	config := map[string]string{}
+	for k, v := range raw {
+		s, err := configString(v)
+		if err != nil {
+			return nil, err
+		}
+		config[k] = s
+	}
+	return config, nil
+}
+
+// configString converts v, a config value, to a string: strings as they
+// are, and other values as JSON.
+func configString(v interface{}) (string, error) {
+	if s, ok := v.(string); ok {
+		return s, nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
@@ -0,0 +1,158 @@
+package bashgraph
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var corpus = flag.String("corpus", "", "graph the projects fetched into this directory by testdata/corpus/fetch.sh")
+
+// corpusTolerance is how far the counts of a project's files, defs and refs
+// may be from its baseline in testdata/corpus/counts.json, as a fraction of
+// the baseline.
+const corpusTolerance = 0.1
+
+// corpusCounts are the aggregate counts of graphing a project.
+type corpusCounts struct {
+	Files int
+	Defs  int
+	Refs  int
+}
+
+// TestCorpus graphs the projects listed in testdata/corpus/projects.txt,
+// which must have been fetched into the directory given with -corpus. It
+// checks that every def and ref spans a range of its file, and that the
+// counts of files, defs and refs are close to the project's baseline. With
+// -update, it records the counts as the new baselines instead.
+func TestCorpus(t *testing.T) {
+	if *corpus == "" {
+		t.Skip("no -corpus directory given")
+	}
+	root, err := filepath.Abs(*corpus)
+	if err != nil {
+		t.Fatal(err)
+	}
+	names, err := corpusProjects()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	countsFile, err := filepath.Abs(filepath.Join("testdata", "corpus", "counts.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	baselines := map[string]*corpusCounts{}
+	if data, err := ioutil.ReadFile(countsFile); err == nil {
+		if err := json.Unmarshal(data, &baselines); err != nil {
+			t.Fatalf("%s: %s", countsFile, err)
+		}
+	} else if !*update {
+		t.Fatalf("%s (run go test -corpus DIR -update to create it)", err)
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	for _, name := range names {
+		counts, err := graphCorpusProject(t, filepath.Join(root, name))
+		if err != nil {
+			t.Errorf("%s: %s", name, err)
+			continue
+		}
+		if *update {
+			baselines[name] = counts
+			continue
+		}
+		base, ok := baselines[name]
+		if !ok {
+			t.Errorf("%s: no baseline (run go test -corpus DIR -update to record it)", name)
+			continue
+		}
+		check := func(what string, got, want int) {
+			if diff := float64(got - want); diff > corpusTolerance*float64(want) || -diff > corpusTolerance*float64(want) {
+				t.Errorf("%s: %d %s, want %d within %.0f%%", name, got, what, want, corpusTolerance*100)
+			}
+		}
+		check("files", counts.Files, base.Files)
+		check("defs", counts.Defs, base.Defs)
+		check("refs", counts.Refs, base.Refs)
+	}
+
+	if *update {
+		data, err := json.MarshalIndent(baselines, "", "  ")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(countsFile, append(data, '\n'), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// corpusProjects returns the names of the projects in
+// testdata/corpus/projects.txt.
+func corpusProjects() ([]string, error) {
+	f, err := os.Open(filepath.Join("testdata", "corpus", "projects.txt"))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var names []string
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		fields := strings.Fields(sc.Text())
+		if len(fields) > 0 && !strings.HasPrefix(fields[0], "#") {
+			names = append(names, fields[0])
+		}
+	}
+	return names, sc.Err()
+}
+
+// graphCorpusProject scans and graphs the project in dir, reporting defs
+// and refs that don't span a range of their files, and returns its counts.
+func graphCorpusProject(t *testing.T, dir string) (*corpusCounts, error) {
+	units, err := new(ScanCmd).scan(dir)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chdir(dir); err != nil {
+		return nil, err
+	}
+	out, err := graphUnits(units, testOptions())
+	if err != nil {
+		return nil, err
+	}
+
+	sizes := map[string]uint32{}
+	size := func(file string) uint32 {
+		s, ok := sizes[file]
+		if !ok {
+			if info, err := os.Stat(file); err == nil {
+				s = uint32(info.Size())
+			}
+			sizes[file] = s
+		}
+		return s
+	}
+	name := filepath.Base(dir)
+	for _, def := range out.Defs {
+		if def.DefStart > def.DefEnd || def.DefEnd > size(def.File) {
+			t.Errorf("%s: def %s spans %d-%d in %d bytes", name, def.Path, def.DefStart, def.DefEnd, size(def.File))
+		}
+	}
+	for _, ref := range out.Refs {
+		if ref.Start > ref.End || ref.End > size(ref.File) {
+			t.Errorf("%s: %s: ref to %s spans %d-%d in %d bytes", name, ref.File, ref.DefPath, ref.Start, ref.End, size(ref.File))
+		}
+	}
+	return &corpusCounts{Files: len(units[0].Files), Defs: len(out.Defs), Refs: len(out.Refs)}, nil
+}
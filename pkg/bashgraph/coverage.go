@@ -0,0 +1,250 @@
+package bashgraph
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"sourcegraph.com/sourcegraph/srclib/unit"
+)
+
+type CoverageCmd struct {
+	Skipped bool `long:"skipped" description:"list the spans of skipped bytes in each file"`
+}
+
+// A Coverage counts the non-whitespace bytes of shell code in a file (or,
+// for the total, in all files) by the construct that covers them. A byte
+// covered by several constructs is counted once, for the first of defs,
+// refs, strings and comments that covers it.
+type Coverage struct {
+	File     string `json:",omitempty"`
+	Bytes    int
+	Defs     int
+	Refs     int
+	Strings  int
+	Comments int
+	Skipped  int
+	// Coverage is the fraction of Bytes that are not skipped.
+	Coverage float64
+	// SkippedSpans are the spans of skipped bytes, with --skipped.
+	SkippedSpans []*Span `json:",omitempty"`
+}
+
+// A Span is a range of byte offsets in a file.
+type Span struct {
+	Start int
+	End   int
+}
+
+// CoverageReport is the output of the coverage command.
+type CoverageReport struct {
+	Files []*Coverage
+	Total *Coverage
+}
+
+func (c *CoverageCmd) Execute(args []string) error {
+	units, err := readSourceUnits()
+	if err != nil {
+		return err
+	}
+
+	report, err := unitsCoverage(units, c.Skipped, commandOptions())
+	if err != nil {
+		return fmt.Errorf("Failed to compute coverage: %s", err)
+	}
+
+	bytes, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("Failed to marshal coverage: %s", err)
+	}
+	if _, err := os.Stdout.Write(bytes); err != nil {
+		return fmt.Errorf("Failed to output coverage: %s", err)
+	}
+	return nil
+}
+
+// The constructs that cover a byte, in the order they take precedence.
+const (
+	notCovered = iota
+	defCovered
+	refCovered
+	stringCovered
+	commentCovered
+	skippedCode
+)
+
+func unitsCoverage(units unit.SourceUnits, skipped bool, opts *Options) (*CoverageReport, error) {
+	out, err := graphUnits(units, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &CoverageReport{Files: []*Coverage{}, Total: &Coverage{}}
+	for _, u := range units {
+		for _, name := range u.Files {
+			f, err := parseFile(name, opts)
+			if err != nil {
+				return nil, err
+			}
+			covered := make([]int, len(f.data))
+			mark := func(start, end, construct int) {
+				for i := start; i < end && i < len(covered); i++ {
+					switch {
+					case construct == skippedCode && covered[i] == notCovered,
+						covered[i] != notCovered && construct < covered[i]:
+						covered[i] = construct
+					}
+				}
+			}
+
+			// Only the bytes of the shell sources in the file count.
+			for _, src := range f.sources {
+				for j := range src.text {
+					mark(src.fileOffset(j), src.fileEnd(j+1), skippedCode)
+				}
+				words, comments, bodies := lexWords(src.text)
+				for _, w := range allWords(words) {
+					for _, q := range quotedSpans(w) {
+						mark(src.fileOffset(q.Start), src.fileEnd(q.End), stringCovered)
+					}
+				}
+				for _, b := range bodies {
+					mark(src.fileOffset(b.start), src.fileEnd(b.end), stringCovered)
+				}
+				for _, c := range comments {
+					mark(src.fileOffset(c.start), src.fileEnd(c.end), commentCovered)
+				}
+			}
+			for _, def := range out.Defs {
+				if def.File == name {
+					mark(int(def.DefStart), int(def.DefEnd), defCovered)
+				}
+			}
+			for _, ref := range out.Refs {
+				if ref.File == name {
+					mark(int(ref.Start), int(ref.End), refCovered)
+				}
+			}
+
+			fc := fileCoverage(f, covered, skipped)
+			report.Files = append(report.Files, fc)
+			report.Total.add(fc)
+		}
+	}
+	report.Total.setCoverage()
+	return report, nil
+}
+
+// fileCoverage counts the non-whitespace bytes of f by the construct that
+// covers them, as recorded in covered.
+func fileCoverage(f *parsedFile, covered []int, skipped bool) *Coverage {
+	c := &Coverage{File: f.name}
+	for i, construct := range covered {
+		if construct == notCovered || isSpace(f.data[i]) {
+			continue
+		}
+		c.Bytes++
+		switch construct {
+		case defCovered:
+			c.Defs++
+		case refCovered:
+			c.Refs++
+		case stringCovered:
+			c.Strings++
+		case commentCovered:
+			c.Comments++
+		case skippedCode:
+			c.Skipped++
+			if !skipped {
+				continue
+			}
+			if n := len(c.SkippedSpans); n > 0 && onlySpaceBetween(f.data, c.SkippedSpans[n-1].End, i) {
+				c.SkippedSpans[n-1].End = i + 1
+			} else {
+				c.SkippedSpans = append(c.SkippedSpans, &Span{Start: i, End: i + 1})
+			}
+		}
+	}
+	c.setCoverage()
+	return c
+}
+
+// add adds the counts of fc to c.
+func (c *Coverage) add(fc *Coverage) {
+	c.Bytes += fc.Bytes
+	c.Defs += fc.Defs
+	c.Refs += fc.Refs
+	c.Strings += fc.Strings
+	c.Comments += fc.Comments
+	c.Skipped += fc.Skipped
+}
+
+// setCoverage sets c.Coverage from its counts. Code with no bytes is fully
+// covered.
+func (c *Coverage) setCoverage() {
+	c.Coverage = 1
+	if c.Bytes > 0 {
+		c.Coverage = float64(c.Bytes-c.Skipped) / float64(c.Bytes)
+	}
+}
+
+// allWords returns words along with the words in the command and process
+// substitutions in them, recursively.
+func allWords(words []word) []word {
+	var all []word
+	for _, w := range words {
+		all = append(all, w)
+		for _, sub := range substitutionWords(w) {
+			all = append(all, allWords(sub)...)
+		}
+	}
+	return all
+}
+
+// quotedSpans returns the spans of the quoted strings in w, including their
+// quotes, as offsets in the source w is in. Strings in substitutions are
+// left to the words of the substitutions.
+func quotedSpans(w word) []Span {
+	if w.op {
+		return nil
+	}
+	var spans []Span
+	text := w.text
+	for i := 0; i < len(text); {
+		switch ch := text[i]; {
+		case ch == '\\':
+			i += 2
+		case ch == '\'':
+			j := scanSingleQuoted(text, i)
+			spans = append(spans, Span{Start: w.start + i, End: w.start + j})
+			i = j
+		case ch == '"':
+			j := scanDoubleQuoted(text, i+1)
+			if j > len(text) {
+				j = len(text)
+			}
+			spans = append(spans, Span{Start: w.start + i, End: w.start + j})
+			i = j
+		case ch == '$' && i+1 < len(text) && (text[i+1] == '(' || text[i+1] == '{'):
+			// The strings in substitutions are in their own words.
+			i = scanSubstitution(text, i+1)
+		default:
+			i++
+		}
+	}
+	return spans
+}
+
+// onlySpaceBetween reports whether data[start:end] is all whitespace.
+func onlySpaceBetween(data []byte, start, end int) bool {
+	for _, b := range data[start:end] {
+		if !isSpace(b) {
+			return false
+		}
+	}
+	return true
+}
+
+func isSpace(b byte) bool {
+	return b == ' ' || b == '\t' || b == '\n' || b == '\r' || b == '\f' || b == '\v'
+}
@@ -0,0 +1,99 @@
+package bashgraph
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// isCrontabFile reports whether path is a crontab: a file named crontab or
+// with a .crontab or .cron extension, or any file in a cron.d directory.
+func isCrontabFile(path string) bool {
+	name := filepath.Base(path)
+	switch {
+	case name == "crontab", strings.HasSuffix(name, ".crontab"), strings.HasSuffix(name, ".cron"):
+		return true
+	case filepath.Base(filepath.Dir(path)) == "cron.d":
+		return !strings.HasPrefix(name, ".")
+	}
+	return false
+}
+
+// isSystemCrontab reports whether the crontab at path is in the system
+// format, which has a user name field before the command.
+func isSystemCrontab(path string) bool {
+	path = filepath.ToSlash(path)
+	return filepath.Base(filepath.Dir(path)) == "cron.d" || path == "etc/crontab" || strings.HasSuffix(path, "/etc/crontab")
+}
+
+// crontabSources returns the command of every entry in a crontab as a shell
+// source.
+func crontabSources(name string, data []byte) []*source {
+	fields := 5
+	if isSystemCrontab(name) {
+		fields++
+	}
+
+	var sources []*source
+	start := 0
+	for start < len(data) {
+		end := start
+		for end < len(data) && data[end] != '\n' {
+			end++
+		}
+		if src := crontabEntrySource(data, start, end, fields); src != nil {
+			sources = append(sources, src)
+		}
+		start = end + 1
+	}
+	return sources
+}
+
+// crontabEntrySource returns the command of the crontab line data[start:end],
+// which has the given number of fields before the command, or nil if the
+// line is not an entry.
+func crontabEntrySource(data []byte, start, end, fields int) *source {
+	i := skipBlanks(data, start, end)
+	if i == end || data[i] == '#' {
+		return nil
+	}
+	if data[i] == '@' {
+		// @reboot, @daily and friends replace the five time fields.
+		fields -= 4
+	} else if isAssignment(string(data[i:end])) {
+		return nil
+	}
+	for f := 0; f < fields; f++ {
+		for i < end && data[i] != ' ' && data[i] != '\t' {
+			i++
+		}
+		i = skipBlanks(data, i, end)
+	}
+	if i == end {
+		return nil
+	}
+
+	// An unescaped % ends the command; the rest of the line is its input.
+	var b sourceBuilder
+	for j := i; j < end; j++ {
+		switch {
+		case data[j] == '%':
+			b.end = j
+			return b.source()
+		case data[j] == '\\' && j+1 < end && data[j+1] == '%':
+			b.addDecoded("%", j, j+2)
+			j++
+		case data[j] == '\r' && j+1 == end:
+		default:
+			b.addByte(data[j], j)
+		}
+	}
+	b.end = end
+	return b.source()
+}
+
+func skipBlanks(data []byte, i, end int) int {
+	for i < end && (data[i] == ' ' || data[i] == '\t') {
+		i++
+	}
+	return i
+}
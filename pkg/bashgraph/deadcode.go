@@ -0,0 +1,103 @@
+package bashgraph
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"sourcegraph.com/sourcegraph/srclib/graph"
+	"sourcegraph.com/sourcegraph/srclib/unit"
+)
+
+type DeadcodeCmd struct{}
+
+func (c *DeadcodeCmd) Execute(args []string) error {
+	units, err := readSourceUnits()
+	if err != nil {
+		return err
+	}
+
+	out, err := deadcodeUnits(units, commandOptions())
+	if err != nil {
+		return fmt.Errorf("Failed to find dead code: %s", err)
+	}
+
+	if err := json.NewEncoder(os.Stdout).Encode(out); err != nil {
+		return fmt.Errorf("Failed to output dead code data: %s", err)
+	}
+	return nil
+}
+
+func deadcodeUnits(units unit.SourceUnits, opts *Options) (*graph.Output, error) {
+	output := graph.Output{}
+	for _, u := range units {
+		files := parseUnit(u, opts)
+		funcs := newFuncIndex(files)
+
+		// Functions are used by DefPath, since the definitions of a
+		// function redefined in a file share a def.
+		used := map[string]bool{}
+		handlers := map[string]bool{}
+		for _, f := range files {
+			for _, c := range funcs.calls(f) {
+				used[c.def.defPath()] = true
+			}
+			for _, s := range f.scripts {
+				for name := range handlerFuncs(s) {
+					handlers[name] = true
+				}
+			}
+		}
+
+		for _, f := range files {
+			li := newLineIndex(f.data)
+			for i, s := range f.scripts {
+				for _, fn := range s.functions {
+					if used[f.defPath()+"/"+fn.name] || handlers[fn.name] {
+						continue
+					}
+					a, err := makeDiagnosticAnn(f.name, li, &Diagnostic{
+						Source:  "deadcode",
+						Code:    "unused-function",
+						Level:   "warning",
+						Message: fmt.Sprintf("function %s is never called", fn.name),
+						Start:   uint32(f.sources[i].fileOffset(fn.start)),
+						End:     uint32(f.sources[i].fileEnd(fn.end)),
+					})
+					if err != nil {
+						return nil, err
+					}
+					output.Anns = append(output.Anns, a)
+				}
+			}
+		}
+	}
+	return &output, nil
+}
+
+// handlerFuncs returns the names of the functions that s registers to be
+// run other than by calling them: trap handlers, hooks, completion functions
+// and functions exported to child processes.
+func handlerFuncs(s *script) map[string]bool {
+	names := map[string]bool{}
+	for _, hook := range hookWords(s) {
+		for _, w := range commandWords(hook) {
+			names[unquote(w.text)] = true
+		}
+	}
+	for _, w := range funcExports(s) {
+		names[unquote(w.text)] = true
+	}
+	for _, cmd := range s.commands {
+		if name := unquote(cmd.text); name != "complete" && name != "compgen" {
+			continue
+		}
+		args := commandArgs(s.words, cmd)
+		for i, a := range args {
+			if a.text == "-F" && i+1 < len(args) {
+				names[unquote(args[i+1].text)] = true
+			}
+		}
+	}
+	return names
+}
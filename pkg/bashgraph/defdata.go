@@ -0,0 +1,76 @@
+package bashgraph
+
+import (
+	"html"
+	"strings"
+)
+
+// funcSignature returns the header of the function d as written, from the
+// function keyword or name up to its body, as in "function deploy" or
+// "deploy()".
+func funcSignature(d *funcDef) string {
+	if d.fn.bodyStart <= d.fn.start || d.fn.bodyStart > len(d.src.text) {
+		return d.fn.name + "()"
+	}
+	return strings.Join(strings.Fields(d.src.text[d.fn.start:d.fn.bodyStart]), " ")
+}
+
+// varSignature returns the declaration of the variable d without its
+// value, such as "declare -A ports" or "local -r name", and its type:
+// "array", "associative array", "integer" or "nameref", or "" for a plain
+// variable. Values are left out since they may be long or secret.
+func varSignature(d *varDecl) (sig, typ string) {
+	text, site := d.src.text, d.site
+	parts := []string{site.name}
+	var opts string
+	if declCommands[site.keyword] || dialectDeclCommands[d.file.dialect][site.keyword] {
+		lineStart := strings.LastIndexByte(text[:site.start], '\n') + 1
+		fields := strings.Fields(text[lineStart:site.start])
+		for i := len(fields) - 1; i >= 0; i-- {
+			if unquote(fields[i]) == site.keyword {
+				for _, f := range fields[i+1:] {
+					if strings.HasPrefix(f, "-") || strings.HasPrefix(f, "+") {
+						parts = append(parts[:len(parts)-1], f, site.name)
+						opts += f[1:]
+					}
+				}
+				break
+			}
+		}
+	}
+	if site.keyword != "" {
+		parts = append([]string{site.keyword}, parts...)
+	}
+
+	rest := text[site.end:]
+	switch {
+	case strings.Contains(opts, "A"):
+		typ = "associative array"
+	case strings.Contains(opts, "a"), strings.HasPrefix(rest, "=("), strings.HasPrefix(rest, "+=("):
+		typ = "array"
+	case strings.Contains(opts, "n") && site.keyword != "export", site.keyword == "nameref", site.nameref != "":
+		typ = "nameref"
+	case strings.Contains(opts, "i"), site.keyword == "integer":
+		typ = "integer"
+	case site.keyword == "mapfile", site.keyword == "readarray", site.keyword == "coproc" && !strings.HasSuffix(site.name, "_PID"):
+		typ = "array"
+	}
+	return strings.Join(parts, " "), typ
+}
+
+// docHTML returns the comment directly above the line of offset in data as
+// an HTML snippet, with a paragraph for each run of comment lines separated
+// by an empty # line, or "" if there is no comment.
+func docHTML(data []byte, offset int) string {
+	doc := commentBefore(data, offset)
+	if doc == "" {
+		return ""
+	}
+	var paras []string
+	for _, p := range strings.Split(doc, "\n\n") {
+		if p = strings.TrimSpace(p); p != "" {
+			paras = append(paras, "<p>"+html.EscapeString(p)+"</p>")
+		}
+	}
+	return strings.Join(paras, "\n")
+}
@@ -0,0 +1,163 @@
+package bashgraph
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+
+	"sourcegraph.com/sourcegraph/srclib/unit"
+)
+
+type DepsGraphCmd struct {
+	Format string `long:"format" description:"output format" choice:"json" choice:"dot" default:"json"`
+}
+
+// A DepsGraph is the graph of source statements between files.
+type DepsGraph struct {
+	Files []string
+	Edges []*DepsEdge
+	// Cycles are the sets of files that (transitively) source each other.
+	Cycles [][]string `json:",omitempty"`
+}
+
+// A DepsEdge records that From sources To.
+type DepsEdge struct {
+	From string
+	To   string
+}
+
+func (c *DepsGraphCmd) Execute(args []string) error {
+	units, err := readSourceUnits()
+	if err != nil {
+		return err
+	}
+
+	g := depsGraph(units, commandOptions())
+	var out []byte
+	switch c.Format {
+	case "dot":
+		out = g.dot()
+	default:
+		out, err = json.MarshalIndent(g, "", "  ")
+		if err != nil {
+			return fmt.Errorf("Failed to marshal dependency graph: %s", err)
+		}
+	}
+	if _, err := os.Stdout.Write(out); err != nil {
+		return fmt.Errorf("Failed to output dependency graph: %s", err)
+	}
+	return nil
+}
+
+func depsGraph(units unit.SourceUnits, opts *Options) *DepsGraph {
+	files := map[string]bool{}
+	edges := map[DepsEdge]bool{}
+	for _, u := range units {
+		for _, f := range parseUnit(u, opts) {
+			from := f.defPath()
+			files[from] = true
+			for _, inc := range includes(f) {
+				to := f.root.defPath(inc.path)
+				files[to] = true
+				edges[DepsEdge{From: from, To: to}] = true
+			}
+		}
+	}
+
+	g := &DepsGraph{Files: sortedKeys(files)}
+	for e := range edges {
+		e := e
+		g.Edges = append(g.Edges, &e)
+	}
+	sort.Sort(depsEdges(g.Edges))
+	g.Cycles = g.cycles()
+	return g
+}
+
+// cycles returns the strongly connected components of g that contain a
+// cycle, using Tarjan's algorithm.
+func (g *DepsGraph) cycles() [][]string {
+	succ := map[string][]string{}
+	for _, e := range g.Edges {
+		succ[e.From] = append(succ[e.From], e.To)
+	}
+
+	var (
+		index   = map[string]int{}
+		lowlink = map[string]int{}
+		onStack = map[string]bool{}
+		stack   []string
+		cycles  [][]string
+	)
+	var visit func(v string)
+	visit = func(v string) {
+		index[v] = len(index)
+		lowlink[v] = index[v]
+		stack = append(stack, v)
+		onStack[v] = true
+		selfLoop := false
+		for _, w := range succ[v] {
+			if w == v {
+				selfLoop = true
+			}
+			if _, seen := index[w]; !seen {
+				visit(w)
+				if lowlink[w] < lowlink[v] {
+					lowlink[v] = lowlink[w]
+				}
+			} else if onStack[w] && index[w] < lowlink[v] {
+				lowlink[v] = index[w]
+			}
+		}
+		if lowlink[v] != index[v] {
+			return
+		}
+		var scc []string
+		for {
+			w := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[w] = false
+			scc = append(scc, w)
+			if w == v {
+				break
+			}
+		}
+		if len(scc) > 1 || selfLoop {
+			sort.Strings(scc)
+			cycles = append(cycles, scc)
+		}
+	}
+	for _, f := range g.Files {
+		if _, seen := index[f]; !seen {
+			visit(f)
+		}
+	}
+	return cycles
+}
+
+// dot returns g in the Graphviz DOT language.
+func (g *DepsGraph) dot() []byte {
+	var buf bytes.Buffer
+	buf.WriteString("digraph deps {\n")
+	for _, f := range g.Files {
+		fmt.Fprintf(&buf, "\t%q;\n", f)
+	}
+	for _, e := range g.Edges {
+		fmt.Fprintf(&buf, "\t%q -> %q;\n", e.From, e.To)
+	}
+	buf.WriteString("}\n")
+	return buf.Bytes()
+}
+
+type depsEdges []*DepsEdge
+
+func (es depsEdges) Len() int      { return len(es) }
+func (es depsEdges) Swap(i, j int) { es[i], es[j] = es[j], es[i] }
+func (es depsEdges) Less(i, j int) bool {
+	if es[i].From != es[j].From {
+		return es[i].From < es[j].From
+	}
+	return es[i].To < es[j].To
+}
@@ -0,0 +1,74 @@
+package bashgraph
+
+import (
+	"encoding/json"
+
+	"sourcegraph.com/sourcegraph/srclib/ann"
+)
+
+// diagnosticAnnType is the type of annotations that carry a Diagnostic.
+const diagnosticAnnType = "diagnostic"
+
+// A Diagnostic is a problem found in a file, emitted as the Data of an
+// annotation.
+type Diagnostic struct {
+	// Source is the tool or analysis that found the problem.
+	Source string
+	// Code identifies the kind of problem, such as "SC2086".
+	Code    string `json:",omitempty"`
+	Level   string
+	Message string
+	// Start and End are the byte offsets of the problem in the file, which
+	// may span several lines, such as a whole function.
+	Start uint32
+	End   uint32
+	// StartPos and EndPos are the line and column positions of Start and
+	// End. They are filled in by makeDiagnosticAnn.
+	StartPos Position
+	EndPos   Position
+	// Related are other locations involved in the problem.
+	Related []*Location `json:",omitempty"`
+}
+
+// A Location is a span of a file.
+type Location struct {
+	File  string
+	Start uint32
+	End   uint32
+}
+
+// makeDiagnosticAnn returns an annotation for d, which was found in the named
+// file whose lines are indexed by li. The annotation covers every line that
+// d's span touches.
+func makeDiagnosticAnn(filename string, li *lineIndex, d *Diagnostic) (*ann.Ann, error) {
+	var startLine, endLine int
+	d.StartPos, d.EndPos, startLine, endLine = spanLines(li, d.Start, d.End)
+	data, err := json.Marshal(d)
+	if err != nil {
+		return nil, err
+	}
+	return &ann.Ann{
+		UnitType:  "BashDirectory",
+		Unit:      "bash",
+		File:      filename,
+		StartLine: uint32(startLine),
+		EndLine:   uint32(endLine),
+		Type:      diagnosticAnnType,
+		Data:      data,
+	}, nil
+}
+
+// spanLines returns the positions of start and end, offsets in the file
+// whose lines are indexed by li, and the first and last lines that the
+// span between them touches.
+func spanLines(li *lineIndex, start, end uint32) (startPos, endPos Position, startLine, endLine int) {
+	startLine, startCol := li.position(int(start))
+	endLine, endCol := li.position(int(end))
+	startPos = Position{Line: startLine, Column: startCol}
+	endPos = Position{Line: endLine, Column: endCol}
+	if endCol == 1 && endLine > startLine {
+		// The span ends with a newline, so its last line is the one before.
+		endLine--
+	}
+	return startPos, endPos, startLine, endLine
+}
@@ -0,0 +1,98 @@
+package bashgraph
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// shellDialect returns the shell that the named file is written for: "zsh"
+// for files with a .zsh extension, zsh startup files such as .zshrc, and
+// scripts whose #! line runs zsh, "ksh" for files with a .ksh extension and
+// scripts whose #! line runs a Korn shell, or else "bash".
+//
+// The lexer tolerates zsh syntax such as glob qualifiers and parameter
+// expansion flags in any file, since it is invalid Bash anyway; the dialect
+// decides which commands are builtins and which declare variables.
+func shellDialect(name string, data []byte) string {
+	base := filepath.Base(name)
+	switch {
+	case strings.HasSuffix(base, ".zsh"):
+		return "zsh"
+	case base == ".zshrc" || base == ".zshenv" || base == ".zprofile" || base == ".zlogin" || base == ".zlogout":
+		return "zsh"
+	case strings.HasSuffix(base, ".ksh"):
+		return "ksh"
+	}
+	if line, ok := shebangLine(data); ok {
+		switch shebangInterpreter(line) {
+		case "zsh":
+			return "zsh"
+		case "ksh", "ksh88", "ksh93", "mksh", "pdksh":
+			return "ksh"
+		}
+	}
+	return "bash"
+}
+
+// zshBuiltins are the commands built into zsh but not Bash.
+var zshBuiltins = map[string]bool{
+	"autoload": true, "bindkey": true, "chdir": true, "compdef": true,
+	"disable": true, "echotc": true, "echoti": true, "emulate": true,
+	"float": true, "functions": true, "getln": true, "integer": true,
+	"limit": true, "noglob": true, "print": true, "pushln": true,
+	"r": true, "rehash": true, "sched": true, "setopt": true, "unfunction": true,
+	"unhash": true, "unlimit": true, "unsetopt": true, "vared": true,
+	"whence": true, "where": true, "which": true, "zcompile": true,
+	"zformat": true, "zle": true, "zmodload": true, "zparseopts": true,
+	"zregexparse": true, "zstyle": true,
+}
+
+// kshBuiltins are the commands built into ksh but not Bash, including the
+// aliases that ksh predefines for typeset.
+var kshBuiltins = map[string]bool{
+	"autoload": true, "float": true, "functions": true, "hist": true,
+	"integer": true, "nameref": true, "print": true, "r": true,
+	"whence": true,
+}
+
+// dialectDeclCommands are the commands of each dialect, besides
+// declCommands, whose arguments declare variables.
+var dialectDeclCommands = map[string]map[string]bool{
+	"ksh": {"float": true, "integer": true, "nameref": true},
+	"zsh": {"float": true, "integer": true},
+}
+
+// isBuiltin reports whether the command name is built into the shell that f
+// is written for.
+func isBuiltin(f *parsedFile, name string) bool {
+	switch f.dialect {
+	case "zsh":
+		return shellBuiltins[name] || zshBuiltins[name]
+	case "ksh":
+		return shellBuiltins[name] || kshBuiltins[name]
+	}
+	return shellBuiltins[name]
+}
+
+// autoloadedFuncs returns the names of the functions that f marks for
+// autoloading from the fpath of zsh or ksh with autoload, as in "autoload
+// -Uz compinit", which are defined outside f.
+func autoloadedFuncs(f *parsedFile) map[string]bool {
+	funcs := map[string]bool{}
+	if f.dialect == "bash" {
+		return funcs
+	}
+	for _, s := range f.scripts {
+		for _, cmd := range s.commands {
+			if unquote(cmd.text) != "autoload" {
+				continue
+			}
+			for _, arg := range commandArgs(s.words, cmd) {
+				if name := unquote(arg.text); !strings.HasPrefix(name, "-") && !strings.HasPrefix(name, "+") {
+					funcs[name] = true
+				}
+			}
+		}
+	}
+	return funcs
+}
@@ -0,0 +1,305 @@
+package bashgraph
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CacheOptions are the options that control the cache of analyzed files,
+// which all commands share.
+type CacheOptions struct {
+	CacheDir string `long:"cache-dir" description:"directory of the cache of analyzed files, shared by all commands and repositories, or off to disable it (default: srclib-bash in $XDG_CACHE_HOME or ~/.cache)" value-name:"DIR"`
+}
+
+// cacheOpts are the cache options of the command line, set by
+// SetGlobalOptions.
+var cacheOpts CacheOptions
+
+// cacheDir returns the directory of the cache of analyzed files: o's
+// CacheDir, set by the --cache-dir option, or else SRCLIB_BASH_CACHE_DIR,
+// or else the cache_dir of the global config file, or else srclib-bash in
+// the user's cache directory. It returns "" if the cache is off. The
+// Srcfile can't set it, so that a repository can't make the toolchain
+// write elsewhere.
+func (o *Options) cacheDir() string {
+	dir := stringSetting(o.CacheDir, "CACHE_DIR", nil, "")
+	if dir == "off" {
+		return ""
+	}
+	if dir == "" {
+		dir = os.Getenv("XDG_CACHE_HOME")
+		if dir == "" {
+			home, err := os.UserHomeDir()
+			if err != nil {
+				return ""
+			}
+			dir = filepath.Join(home, ".cache")
+		}
+		return filepath.Join(dir, "srclib-bash")
+	}
+	if strings.HasPrefix(dir, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		dir = filepath.Join(home, dir[2:])
+	}
+	return dir
+}
+
+var (
+	analyzerVersionOnce sync.Once
+	analyzerVersionHash string
+)
+
+// analyzerVersion returns the hash of the running executable, which cache
+// keys include so that a rebuilt analyzer doesn't use what an older one
+// cached. It returns "" if the executable can't be read.
+func analyzerVersion() string {
+	analyzerVersionOnce.Do(func() {
+		name, err := os.Executable()
+		if err != nil {
+			return
+		}
+		f, err := os.Open(name)
+		if err != nil {
+			return
+		}
+		defer f.Close()
+		h := sha256.New()
+		if _, err := io.Copy(h, f); err != nil {
+			return
+		}
+		analyzerVersionHash = hex.EncodeToString(h.Sum(nil))
+	})
+	return analyzerVersionHash
+}
+
+// parseCachePath returns the path in the cache in dir of the parse of
+// data, the contents of the named file, or "" if dir is "", as when the
+// cache is off. Parses are keyed
+// by the analyzer version, the file's name, which decides how shell code is
+// extracted from it, and the hash of its contents, in a directory named by
+// the first two digits of the key.
+func parseCachePath(dir, name string, data []byte) string {
+	version := analyzerVersion()
+	if dir == "" || version == "" {
+		return ""
+	}
+	h := sha256.New()
+	io.WriteString(h, version+"\x00"+name+"\x00")
+	h.Write(data)
+	key := hex.EncodeToString(h.Sum(nil))
+	return filepath.Join(dir, "parse", key[:2], key[2:])
+}
+
+// parseCachedData parses data, the contents of the named file, as
+// parseData does, using and filling the cache of analyzed files in dir.
+// Failing to use the cache is logged as debug messages only.
+func parseCachedData(dir, name string, data []byte) (*parsedFile, error) {
+	path := parseCachePath(dir, name, data)
+	if path == "" {
+		return parseData(name, data)
+	}
+	if cached, err := ioutil.ReadFile(path); err == nil {
+		var c cachedParse
+		if err := gob.NewDecoder(bytes.NewReader(cached)).Decode(&c); err == nil {
+			// The modification time records when the entry was last used,
+			// for cache clean --older-than.
+			now := time.Now()
+			os.Chtimes(path, now, now)
+			return c.parsedFile(name, data), nil
+		}
+		logDebugf("Ignoring invalid cache entry %s", path)
+	}
+
+	f, err := parseData(name, data)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(newCachedParse(f)); err != nil {
+		logDebugf("Failed to encode the parse of %s for the cache: %s", name, err)
+		return f, nil
+	}
+	if err := writeCacheFile(path, buf.Bytes()); err != nil {
+		logDebugf("Failed to cache the parse of %s: %s", name, err)
+	}
+	return f, nil
+}
+
+// writeCacheFile writes data to the cache entry at path, through a
+// temporary file, so that processes reading the entry at the same time
+// never see part of it.
+func writeCacheFile(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(path), ".tmp-")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return nil
+}
+
+// A cachedParse is the encoding of a parsedFile in the cache. Pointers
+// between the parts of a script, such as from a local variable to its
+// function, are indexes.
+type cachedParse struct {
+	Dialect string
+	Sources []cachedSource
+	Scripts []cachedScript
+}
+
+type cachedSource struct {
+	Text string
+	// Segments are the text, textLen, host and hostLen of the segments
+	// of the source map.
+	Segments [][4]int
+	End      int
+}
+
+type cachedScript struct {
+	Dialect   string
+	Words     []cachedWord
+	Commands  []cachedWord
+	Functions []cachedFunction
+	Vars      []cachedVarSite
+	VarRefs   []cachedSpan
+	Aliases   []cachedSpan
+}
+
+type cachedWord struct {
+	Text       string
+	Start, End int
+	Op         bool
+}
+
+type cachedFunction struct {
+	Name               string
+	NameStart, NameEnd int
+	Start, End         int
+	BodyStart          int
+	Complexity         int
+}
+
+type cachedVarSite struct {
+	Name       string
+	Start, End int
+	// Local is the index of the function the variable is local to, or -1.
+	Local   int
+	Keyword string
+	Nameref string
+}
+
+// A cachedSpan is a named span, the encoding of a varRef or aliasSite.
+type cachedSpan struct {
+	Name       string
+	Start, End int
+}
+
+func newCachedParse(f *parsedFile) *cachedParse {
+	c := &cachedParse{Dialect: f.dialect}
+	for _, src := range f.sources {
+		cs := cachedSource{Text: src.text, End: src.end}
+		for _, seg := range src.smap {
+			cs.Segments = append(cs.Segments, [4]int{seg.text, seg.textLen, seg.host, seg.hostLen})
+		}
+		c.Sources = append(c.Sources, cs)
+	}
+	for _, s := range f.scripts {
+		cs := cachedScript{Dialect: s.dialect, Words: cachedWords(s.words), Commands: cachedWords(s.commands)}
+		funcIndex := map[*function]int{}
+		for i, fn := range s.functions {
+			funcIndex[fn] = i
+			cs.Functions = append(cs.Functions, cachedFunction{fn.name, fn.nameStart, fn.nameEnd, fn.start, fn.end, fn.bodyStart, fn.complexity})
+		}
+		for _, v := range s.vars {
+			local := -1
+			if i, ok := funcIndex[v.local]; ok {
+				local = i
+			}
+			cs.Vars = append(cs.Vars, cachedVarSite{v.name, v.start, v.end, local, v.keyword, v.nameref})
+		}
+		for _, r := range s.varRefs {
+			cs.VarRefs = append(cs.VarRefs, cachedSpan{r.name, r.start, r.end})
+		}
+		for _, a := range s.aliases {
+			cs.Aliases = append(cs.Aliases, cachedSpan{a.name, a.start, a.end})
+		}
+		c.Scripts = append(c.Scripts, cs)
+	}
+	return c
+}
+
+func cachedWords(words []word) []cachedWord {
+	var cws []cachedWord
+	for _, w := range words {
+		cws = append(cws, cachedWord{w.text, w.start, w.end, w.op})
+	}
+	return cws
+}
+
+// parsedFile returns the parsedFile that c encodes, the parse of data, the
+// contents of the named file.
+func (c *cachedParse) parsedFile(name string, data []byte) *parsedFile {
+	f := &parsedFile{name: name, data: data, dialect: c.Dialect}
+	for _, cs := range c.Sources {
+		src := &source{text: cs.Text, end: cs.End}
+		for _, seg := range cs.Segments {
+			src.smap = append(src.smap, sourceSegment{seg[0], seg[1], seg[2], seg[3]})
+		}
+		f.sources = append(f.sources, src)
+	}
+	for _, cs := range c.Scripts {
+		s := &script{dialect: cs.Dialect, words: wordsOf(cs.Words), commands: wordsOf(cs.Commands)}
+		for _, fn := range cs.Functions {
+			s.functions = append(s.functions, &function{fn.Name, fn.NameStart, fn.NameEnd, fn.Start, fn.End, fn.BodyStart, fn.Complexity})
+		}
+		for _, v := range cs.Vars {
+			site := &varSite{name: v.Name, start: v.Start, end: v.End, keyword: v.Keyword, nameref: v.Nameref}
+			if v.Local >= 0 {
+				site.local = s.functions[v.Local]
+			}
+			s.vars = append(s.vars, site)
+		}
+		for _, r := range cs.VarRefs {
+			s.varRefs = append(s.varRefs, &varRef{r.Name, r.Start, r.End})
+		}
+		for _, a := range cs.Aliases {
+			s.aliases = append(s.aliases, &aliasSite{a.Name, a.Start, a.End})
+		}
+		f.scripts = append(f.scripts, s)
+	}
+	return f
+}
+
+func wordsOf(cws []cachedWord) []word {
+	var words []word
+	for _, cw := range cws {
+		words = append(words, word{cw.Text, cw.Start, cw.End, cw.Op})
+	}
+	return words
+}
@@ -0,0 +1,62 @@
+package bashgraph
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// testCacheDir is the cache of analyzed files that the tests share.
+var testCacheDir string
+
+// testOptions returns the default options, with the tests' cache.
+func testOptions() *Options {
+	return &Options{CacheDir: testCacheDir}
+}
+
+func TestMain(m *testing.M) {
+	// The tests share a cache of their own, so that files parsed by
+	// several tests are read back from it, as do the commands they run.
+	dir, err := ioutil.TempDir("", "srclib-bash-cache")
+	if err != nil {
+		panic(err)
+	}
+	testCacheDir = dir
+	cacheOpts.CacheDir = dir
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+func TestCachedParse(t *testing.T) {
+	names, err := filepath.Glob(filepath.Join("testdata", "graph", "*.sh"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range names {
+		data, err := ioutil.ReadFile(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want, err := parseData(name, data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// Once to fill the cache, once to read from it.
+		for i := 0; i < 2; i++ {
+			got, err := parseCachedData(testCacheDir, name, data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("%s: cached parse differs from the parse", name)
+				break
+			}
+		}
+		if _, err := os.Stat(parseCachePath(testCacheDir, name, data)); err != nil {
+			t.Errorf("%s: not cached: %s", name, err)
+		}
+	}
+}
@@ -0,0 +1,6 @@
+// Package bashgraph analyzes Bash scripts, finding the definitions of their
+// functions, variables and aliases, the references to them and to the man
+// pages of the commands they run, and problems in them. It implements the
+// srclib-bash toolchain, whose commands Main runs, and other Go programs can
+// use it directly with Analyze and AnalyzeFile.
+package bashgraph
@@ -0,0 +1,116 @@
+package bashgraph
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+)
+
+// isDotenvFile reports whether path is a dotenv file of variable
+// assignments: a file named .env, .env.local and the like, or with a .env
+// extension.
+func isDotenvFile(path string) bool {
+	name := filepath.Base(path)
+	return name == ".env" || strings.HasPrefix(name, ".env.") || strings.HasSuffix(name, ".env")
+}
+
+// sourcedDotenvFiles returns the dotenv files that files source, as in
+// source .env or set -a; . config.env, and that aren't among them, parsed
+// for the variables they assign. Files that cannot be read are skipped with
+// a warning.
+func sourcedDotenvFiles(files []*parsedFile, opts *Options) []*parsedFile {
+	seen := map[string]bool{}
+	for _, f := range files {
+		seen[filepath.Clean(f.name)] = true
+	}
+	var envs []*parsedFile
+	for _, f := range files {
+		for _, inc := range includes(f) {
+			if seen[inc.path] || !isDotenvFile(inc.path) {
+				continue
+			}
+			seen[inc.path] = true
+			env, err := parseDotenvFile(inc.path)
+			if err != nil {
+				opts.warnf("Skipping file: %s", err)
+				continue
+			}
+			envs = append(envs, env)
+		}
+	}
+	return envs
+}
+
+// parseDotenvFile reads and parses the named dotenv file.
+func parseDotenvFile(name string) (*parsedFile, error) {
+	data, err := ioutil.ReadFile(name)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read file %s: %s", name, err)
+	}
+	src := dotenvSource(data)
+	return &parsedFile{
+		name:    name,
+		data:    data,
+		dialect: "bash",
+		dotenv:  true,
+		sources: []*source{src},
+		scripts: []*script{parseScript(src.text, "bash")},
+	}, nil
+}
+
+// dotenvSource returns the assignments in the dotenv file data, one
+// NAME=VALUE or export NAME=VALUE per line, as a shell source that assigns
+// the same names. The values are left out: dotenv files don't quote them
+// the way the shell does, and only the names are defs.
+func dotenvSource(data []byte) *source {
+	var b sourceBuilder
+	start := 0
+	for start < len(data) {
+		end := start
+		for end < len(data) && data[end] != '\n' {
+			end++
+		}
+		i := skipBlanks(data, start, end)
+		first := i
+		if strings.HasPrefix(string(data[i:end]), "export") {
+			if j := i + len("export"); j < end && (data[j] == ' ' || data[j] == '\t') {
+				i = skipBlanks(data, j, end)
+			}
+		}
+		nameStart := i
+		for i < end && isNameChar(data[i], i == nameStart) {
+			i++
+		}
+		nameEnd := i
+		if i = skipBlanks(data, i, end); nameEnd > nameStart && i < end && data[i] == '=' {
+			b.add(data, first, nameEnd)
+			b.addDecoded("=\n", nameEnd, nameEnd)
+			if i = skipBlanks(data, i+1, end); i < end && (data[i] == '"' || data[i] == '\'') {
+				// A quoted value may span lines.
+				if close := dotenvQuoteEnd(data, i); close > end {
+					for end < len(data) && (end < close || data[end] != '\n') {
+						end++
+					}
+				}
+			}
+		}
+		start = end + 1
+	}
+	return b.source()
+}
+
+// dotenvQuoteEnd returns the offset of the quote that closes the one at
+// data[i], or len(data) if it is not closed.
+func dotenvQuoteEnd(data []byte, i int) int {
+	q := data[i]
+	for i++; i < len(data); i++ {
+		switch {
+		case data[i] == '\\' && q == '"':
+			i++
+		case data[i] == q:
+			return i
+		}
+	}
+	return len(data)
+}
@@ -0,0 +1,123 @@
+package bashgraph
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"sourcegraph.com/sourcegraph/srclib/graph"
+	"sourcegraph.com/sourcegraph/srclib/unit"
+)
+
+type DuplicatesCmd struct{}
+
+func (c *DuplicatesCmd) Execute(args []string) error {
+	units, err := readSourceUnits()
+	if err != nil {
+		return err
+	}
+
+	out, err := duplicateFuncs(units, commandOptions())
+	if err != nil {
+		return fmt.Errorf("Failed to find duplicate functions: %s", err)
+	}
+
+	if err := json.NewEncoder(os.Stdout).Encode(out); err != nil {
+		return fmt.Errorf("Failed to output duplicate functions: %s", err)
+	}
+	return nil
+}
+
+// duplicateFuncs reports every definition of a function that is defined in
+// more than one file of a unit. Which definition a caller gets depends on
+// the order in which the files are sourced, which is a frequent source of
+// bugs. Redefinitions within a single file are usually deliberate and are
+// not reported.
+func duplicateFuncs(units unit.SourceUnits, opts *Options) (*graph.Output, error) {
+	output := graph.Output{}
+	for _, u := range units {
+		files := parseUnit(u, opts)
+		idx := newFuncIndex(files)
+		lines := map[*parsedFile]*lineIndex{}
+
+		for _, f := range files {
+			for _, s := range f.scripts {
+				for _, fn := range s.functions {
+					defs := idx[fn.name]
+					if !definedInOtherFiles(defs, f) || firstInFile(defs, f).fn != fn {
+						continue
+					}
+
+					var d *funcDef
+					var related []*Location
+					var others []string
+					for _, other := range defs {
+						if other.fn == fn {
+							d = other
+							continue
+						}
+						related = append(related, &Location{
+							File:  other.file.name,
+							Start: uint32(other.src.fileOffset(other.fn.nameStart)),
+							End:   uint32(other.src.fileEnd(other.fn.nameEnd)),
+						})
+						if other.file != f {
+							others = append(others, other.file.name)
+						}
+					}
+
+					if lines[f] == nil {
+						lines[f] = newLineIndex(f.data)
+					}
+					a, err := makeDiagnosticAnn(f.name, lines[f], &Diagnostic{
+						Source:  "duplicates",
+						Code:    "duplicate-function",
+						Level:   "warning",
+						Message: fmt.Sprintf("function %s is also defined in %s", fn.name, strings.Join(uniqueStrings(others), ", ")),
+						Start:   uint32(d.src.fileOffset(fn.nameStart)),
+						End:     uint32(d.src.fileEnd(fn.nameEnd)),
+						Related: related,
+					})
+					if err != nil {
+						return nil, err
+					}
+					output.Anns = append(output.Anns, a)
+				}
+			}
+		}
+	}
+	return &output, nil
+}
+
+// definedInOtherFiles reports whether any of defs is in a file other than f.
+func definedInOtherFiles(defs []*funcDef, f *parsedFile) bool {
+	for _, d := range defs {
+		if d.file != f {
+			return true
+		}
+	}
+	return false
+}
+
+// firstInFile returns the first of defs that is in f.
+func firstInFile(defs []*funcDef, f *parsedFile) *funcDef {
+	for _, d := range defs {
+		if d.file == f {
+			return d
+		}
+	}
+	return nil
+}
+
+func uniqueStrings(ss []string) []string {
+	var unique []string
+	seen := map[string]bool{}
+	for _, s := range ss {
+		if !seen[s] {
+			seen[s] = true
+			unique = append(unique, s)
+		}
+	}
+	return unique
+}
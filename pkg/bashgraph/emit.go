@@ -0,0 +1,229 @@
+package bashgraph
+
+import (
+	"fmt"
+)
+
+// emitScriptDef adds the def of the script f itself.
+func emitScriptDef(f *parsedFile, idx *unitIndex, output *graphOutput) error {
+	def, err := makeScriptDef(f)
+	if err != nil {
+		return fmt.Errorf("failed to create script def: %s", err)
+	}
+	output.Defs = append(output.Defs, def)
+	return nil
+}
+
+// emitCommandDocs adds the refs from the commands in f to their
+// documentation, and diagnostics for the code the scanner skipped.
+func emitCommandDocs(f *parsedFile, idx *unitIndex, output *graphOutput) error {
+	for i, src := range f.sources {
+		skipped, err := graphSource(f.name, src, f.scripts[i], idx, output)
+		if err != nil {
+			return err
+		}
+		if err := diagnoseScanErrors(f, src, skipped, output); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// emitFuncDefs adds the defs of the functions in f, and their def refs. A
+// function defined more than once in f has one def, at its first
+// definition, which the def refs of the later ones point to, as calls do.
+func emitFuncDefs(f *parsedFile, idx *unitIndex, output *graphOutput) error {
+	defined := map[string]*funcDef{}
+	for i, src := range f.sources {
+		for _, fn := range f.scripts[i].functions {
+			if d, ok := defined[fn.name]; ok {
+				output.Refs = append(output.Refs, makeFuncRef(f.name, src, word{start: fn.nameStart, end: fn.nameEnd}, d, true))
+				continue
+			}
+			d := &funcDef{file: f, src: src, fn: fn, completes: idx.completed[fn], exported: idx.exported[fn]}
+			defined[fn.name] = d
+			def, err := makeFuncDef(d)
+			if err != nil {
+				return fmt.Errorf("failed to create function def: %s", err)
+			}
+			output.Defs = append(output.Defs, def)
+			output.Refs = append(output.Refs, makeFuncRef(f.name, src, word{start: fn.nameStart, end: fn.nameEnd}, d, true))
+		}
+	}
+	return nil
+}
+
+// emitVars adds the defs of the variables declared in f, and the refs from
+// their assignments and expansions.
+func emitVars(f *parsedFile, idx *unitIndex, output *graphOutput) error {
+	for i, src := range f.sources {
+		s := f.scripts[i]
+		for _, site := range s.vars {
+			isDecl := idx.vars.isDecl(site)
+			d := idx.vars.resolve(site.name, f, site.local)
+			if isDecl {
+				d = &varDecl{file: f, src: src, site: site}
+				def, err := makeVarDef(d)
+				if err != nil {
+					return fmt.Errorf("failed to create variable def: %s", err)
+				}
+				output.Defs = append(output.Defs, def)
+			}
+			output.Refs = append(output.Refs, makeVarRef(f.name, src, site.start, site.end, d, isDecl))
+			if !isDecl {
+				graphNamerefUse(f, src, site.start, site.end, d, idx, output)
+			}
+		}
+		for _, ref := range s.varRefs {
+			if d := idx.vars.resolve(ref.name, f, s.enclosingFunc(word{start: ref.start, end: ref.end})); d != nil {
+				output.Refs = append(output.Refs, makeVarRef(f.name, src, ref.start, ref.end, d, false))
+				graphNamerefUse(f, src, ref.start, ref.end, d, idx, output)
+			}
+		}
+	}
+	return nil
+}
+
+// emitAliases adds the defs of the aliases defined in f, and the refs from
+// their definitions and the commands that use them.
+func emitAliases(f *parsedFile, idx *unitIndex, output *graphOutput) error {
+	for i, src := range f.sources {
+		s := f.scripts[i]
+		for _, site := range s.aliases {
+			d := idx.aliases.resolve(site.name, f)
+			isDef := d.site == site
+			if isDef {
+				def, err := makeAliasDef(d)
+				if err != nil {
+					return fmt.Errorf("failed to create alias def: %s", err)
+				}
+				output.Defs = append(output.Defs, def)
+			}
+			output.Refs = append(output.Refs, makeAliasRef(f.name, src, site.start, site.end, d, isDef))
+		}
+		for _, cmd := range s.commands {
+			if d := idx.aliases.resolve(unquote(cmd.text), f); d != nil {
+				output.Refs = append(output.Refs, makeAliasRef(f.name, src, cmd.start, cmd.end, d, false))
+			}
+		}
+	}
+	return nil
+}
+
+// emitCommandTargets adds the refs from the commands in f that the command
+// map or a resolver links to defs outside the unit.
+func emitCommandTargets(f *parsedFile, idx *unitIndex, output *graphOutput) error {
+	for i, src := range f.sources {
+		s := f.scripts[i]
+		for _, cmd := range s.commands {
+			if t := idx.commands.lookup(cmd, f.name); t != nil {
+				ref := makeTargetRef(f.name, src, cmd, t)
+				output.Refs = append(output.Refs, ref)
+				if flags := commandFlags(s.words, cmd); len(flags) > 0 {
+					output.flags[ref] = flags
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// emitCalls adds the refs from the calls of functions in f.
+func emitCalls(f *parsedFile, idx *unitIndex, output *graphOutput) error {
+	for _, c := range idx.funcs.calls(f) {
+		ref := makeFuncRef(f.name, c.src, c.word, c.def, false)
+		output.Refs = append(output.Refs, ref)
+		if c.eval {
+			output.lowConfidence[ref] = true
+		}
+	}
+	return nil
+}
+
+// emitEvalDiagnostics adds the diagnostics for the evals in f that run
+// commands that cannot be known statically.
+func emitEvalDiagnostics(f *parsedFile, idx *unitIndex, output *graphOutput) error {
+	return diagnoseEvals(f, output)
+}
+
+// emitBashisms adds the diagnostics for the Bash features used in f, if it
+// is run by a POSIX shell.
+func emitBashisms(f *parsedFile, idx *unitIndex, output *graphOutput) error {
+	return diagnoseBashisms(f, output)
+}
+
+// emitSecurityFindings adds the security annotations for the code that f
+// downloads and runs.
+func emitSecurityFindings(f *parsedFile, idx *unitIndex, output *graphOutput) error {
+	return findSecurityIssues(f, output)
+}
+
+// emitSecrets adds the security annotations for the credentials written in
+// f, with --secrets.
+func emitSecrets(f *parsedFile, idx *unitIndex, output *graphOutput) error {
+	if !idx.opts.Secrets {
+		return nil
+	}
+	return findSecrets(f, output)
+}
+
+// emitIncludes adds the refs from the source statements in f to the
+// scripts they include.
+func emitIncludes(f *parsedFile, idx *unitIndex, output *graphOutput) error {
+	for _, inc := range includes(f) {
+		output.Refs = append(output.Refs, makeIncludeRef(f, inc))
+	}
+	return nil
+}
+
+// emitCompletions adds the refs from the completions registered in f to
+// the completion functions and the commands they complete, which are
+// linked where they are registered.
+func emitCompletions(f *parsedFile, idx *unitIndex, output *graphOutput) error {
+	for _, c := range completions(f) {
+		if d := idx.funcs.resolve(unquote(c.handler.text), f); d != nil {
+			output.Refs = append(output.Refs, makeFuncRef(f.name, c.src, c.handler, d, false))
+		}
+		for _, cmd := range c.commands {
+			if d := idx.funcs.resolve(unquote(cmd.text), f); d != nil {
+				output.Refs = append(output.Refs, makeFuncRef(f.name, c.src, cmd, d, false))
+			} else if t := idx.commands.lookup(cmd, f.name); t != nil {
+				output.Refs = append(output.Refs, makeTargetRef(f.name, c.src, cmd, t))
+			}
+		}
+	}
+	return nil
+}
+
+// emitHooks adds the refs to the functions run by the traps and hooks
+// registered in f, which are linked where they are registered.
+func emitHooks(f *parsedFile, idx *unitIndex, output *graphOutput) error {
+	for i, s := range f.scripts {
+		for _, hook := range hookWords(s) {
+			for _, w := range commandWords(hook) {
+				if !isLiteralCommand(w) {
+					continue
+				}
+				if d := idx.funcs.resolve(unquote(w.text), f); d != nil {
+					output.Refs = append(output.Refs, makeFuncRef(f.name, f.sources[i], w, d, false))
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// emitDeclaredFuncs adds the refs from the names that declaration commands
+// in f are given with -f or -F, as in export -f deploy, to the functions
+// they name.
+func emitDeclaredFuncs(f *parsedFile, idx *unitIndex, output *graphOutput) error {
+	for i, s := range f.scripts {
+		names, _ := funcOperands(s)
+		for _, w := range names {
+			if d := idx.funcs.resolve(unquote(w.text), f); d != nil {
+				output.Refs = append(output.Refs, makeFuncRef(f.name, f.sources[i], w, d, false))
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package bashgraph
+
+// setupCommands are the commands that scripts meant to be sourced run at
+// their top level to set up the shell, rather than to do work.
+var setupCommands = map[string]bool{
+	".": true, ":": true, "[": true, "[[": true, "alias": true,
+	"autoload": true, "bind": true, "compdef": true, "complete": true,
+	"declare": true, "export": true, "false": true, "readonly": true,
+	"return": true, "set": true, "setopt": true, "shopt": true,
+	"source": true, "test": true, "trap": true, "true": true,
+	"typeset": true, "unalias": true, "unset": true, "zmodload": true,
+}
+
+// An entryPoint is the classification of a file as a library, meant to be
+// sourced by other scripts, or an executable, meant to be run.
+type entryPoint struct {
+	// role is "library" or "executable".
+	role string
+	// main is the function that runs the script, called at the top level
+	// as in main "$@", or nil if there is none.
+	main *function
+}
+
+// fileEntryPoint classifies f by the commands at its top level. A file
+// that calls one of its functions with the script's arguments, as in
+// main "$@", is an executable whose main function that is, even if it
+// returns early when sourced. Otherwise a file that returns at its top
+// level, or only defines functions and sets up the shell, is a library.
+func fileEntryPoint(f *parsedFile) entryPoint {
+	var returns, runs bool
+	for _, s := range f.scripts {
+		for _, cmd := range s.commands {
+			if s.enclosingFunc(cmd) != nil {
+				continue
+			}
+			name := unquote(cmd.text)
+			if fn := s.function(name); fn != nil && (name == "main" || passesArgs(s, cmd)) {
+				return entryPoint{role: "executable", main: fn}
+			}
+			switch {
+			case name == "return":
+				returns = true
+			case !setupCommands[name]:
+				runs = true
+			}
+		}
+	}
+	if runs && !returns {
+		return entryPoint{role: "executable"}
+	}
+	return entryPoint{role: "library"}
+}
+
+// function returns the function named name that s defines, or nil.
+func (s *script) function(name string) *function {
+	for _, fn := range s.functions {
+		if fn.name == name {
+			return fn
+		}
+	}
+	return nil
+}
+
+// passesArgs reports whether the command cmd in s is passed all the
+// positional parameters, as in main "$@".
+func passesArgs(s *script, cmd word) bool {
+	for _, a := range commandArgs(s.words, cmd) {
+		switch a.text {
+		case `"$@"`, `$@`, `"${@}"`, `${@}`, `"$*"`, `$*`:
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,163 @@
+package bashgraph
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"sourcegraph.com/sourcegraph/srclib/unit"
+)
+
+type EnvVarsCmd struct {
+	JSON bool `long:"json" description:"output the inventory as JSON"`
+}
+
+// UnitEnvVars are the environment variables that the scripts of a source
+// unit read or export.
+type UnitEnvVars struct {
+	UnitType string
+	Unit     string
+	Vars     []*EnvVar
+}
+
+// An EnvVar is an environment variable that scripts read or export.
+type EnvVar struct {
+	Name string
+	// Status is how the variable is defined: "required" if it is read but
+	// never assigned or given a default, so the environment must set it;
+	// "optional" if a default is given where it is read, as in
+	// ${PORT:-8080}; "exported" if the scripts export it; and "shell" if it
+	// is set by the shell or the login environment, such as HOME.
+	Status string
+	// DefPath is the DefPath of the variable's def, if it is assigned in
+	// the unit.
+	DefPath string        `json:",omitempty"`
+	Reads   []*EnvVarSite `json:",omitempty"`
+	Exports []*EnvVarSite `json:",omitempty"`
+}
+
+// An EnvVarSite is where a variable is read or exported.
+type EnvVarSite struct {
+	File  string
+	Start uint32
+	End   uint32
+	Line  int
+}
+
+func (c *EnvVarsCmd) Execute(args []string) error {
+	units, err := readSourceUnits()
+	if err != nil {
+		return err
+	}
+
+	inventory := envVarInventory(units, commandOptions())
+	if c.JSON {
+		if err := json.NewEncoder(os.Stdout).Encode(inventory); err != nil {
+			return fmt.Errorf("Failed to output environment variables: %s", err)
+		}
+		return nil
+	}
+
+	for _, u := range inventory {
+		fmt.Printf("%s %s\n", u.UnitType, u.Unit)
+		for _, v := range u.Vars {
+			var sites []string
+			for _, s := range append(append([]*EnvVarSite(nil), v.Exports...), v.Reads...) {
+				sites = append(sites, fmt.Sprintf("%s:%d", s.File, s.Line))
+			}
+			fmt.Printf("  %-24s %-9s %s\n", v.Name, v.Status, strings.Join(sites, " "))
+		}
+	}
+	return nil
+}
+
+// envVarInventory returns the environment variables that the scripts of
+// each of units read or export, sorted by name. A global variable is an
+// environment variable if it is exported, or read where it is never
+// assigned, or read with a default value. Reads of locals are left out.
+func envVarInventory(units unit.SourceUnits, opts *Options) []*UnitEnvVars {
+	var inventory []*UnitEnvVars
+	for _, u := range units {
+		files := parseUnit(u, opts)
+		idx := newVarIndex(files)
+		vars := map[string]*EnvVar{}
+		undefined := map[string]bool{}
+		defaulted := map[string]bool{}
+		exported := map[string]bool{}
+		get := func(name string) *EnvVar {
+			v := vars[name]
+			if v == nil {
+				v = &EnvVar{Name: name}
+				vars[name] = v
+			}
+			return v
+		}
+		for _, f := range files {
+			lines := newLineIndex(f.data)
+			site := func(src *source, start, end int) *EnvVarSite {
+				s := &EnvVarSite{File: f.name, Start: uint32(src.fileOffset(start)), End: uint32(src.fileEnd(end))}
+				s.Line, _ = lines.position(int(s.Start))
+				return s
+			}
+			for i, s := range f.scripts {
+				src := f.sources[i]
+				for _, vs := range s.vars {
+					if vs.local != nil {
+						continue
+					}
+					v := get(vs.name)
+					if v.DefPath == "" && idx.isDecl(vs) {
+						v.DefPath = (&varDecl{file: f, src: src, site: vs}).defPath()
+					}
+					if vs.keyword == "export" {
+						v.Exports = append(v.Exports, site(src, vs.start, vs.end))
+						exported[vs.name] = true
+					}
+				}
+				for _, ref := range s.varRefs {
+					d := idx.resolve(ref.name, f, s.enclosingFunc(word{start: ref.start, end: ref.end}))
+					if d != nil && d.site.local != nil {
+						continue
+					}
+					v := get(ref.name)
+					v.Reads = append(v.Reads, site(src, ref.start, ref.end))
+					if d == nil {
+						undefined[ref.name] = true
+					}
+					if hasDefaultValue(src.text, ref) {
+						defaulted[ref.name] = true
+					}
+				}
+			}
+		}
+
+		ue := &UnitEnvVars{UnitType: u.Type, Unit: u.Name}
+		for name, v := range vars {
+			switch {
+			case exported[name]:
+				v.Status = "exported"
+			case defaulted[name]:
+				v.Status = "optional"
+			case !undefined[name]:
+				// The variable is only read where the unit assigns it.
+				continue
+			case isEnvVar(name):
+				v.Status = "shell"
+			default:
+				v.Status = "required"
+			}
+			ue.Vars = append(ue.Vars, v)
+		}
+		sort.Sort(envVarsByName(ue.Vars))
+		inventory = append(inventory, ue)
+	}
+	return inventory
+}
+
+type envVarsByName []*EnvVar
+
+func (vs envVarsByName) Len() int           { return len(vs) }
+func (vs envVarsByName) Swap(i, j int)      { vs[i], vs[j] = vs[j], vs[i] }
+func (vs envVarsByName) Less(i, j int) bool { return vs[i].Name < vs[j].Name }
@@ -0,0 +1,83 @@
+package bashgraph
+
+import (
+	"fmt"
+	"strings"
+)
+
+// evalWords returns the words of the string that the eval command named by
+// cmd evaluates: the words in its string literal arguments, and its other
+// arguments as they are. It also reports whether the commands the string
+// runs are all known, which they aren't if any command name is expanded
+// at run time, as in eval "$CMD --flag".
+func evalWords(words []word, cmd word) ([]word, bool) {
+	var ws []word
+	for _, a := range commandArgs(words, cmd) {
+		if inner, ok := quotedWords(a); ok {
+			ws = append(ws, inner...)
+		} else {
+			ws = append(ws, a)
+		}
+	}
+	if len(ws) == 0 {
+		return nil, true
+	}
+	cmds := commandWords(ws)
+	known := len(cmds) > 0
+	for _, c := range cmds {
+		if !isLiteralCommand(c) {
+			known = false
+		}
+	}
+	return ws, known
+}
+
+// evalCommands returns the eval commands in s.
+func evalCommands(s *script) []word {
+	var evals []word
+	for _, cmd := range s.commands {
+		if unquote(cmd.text) == "eval" {
+			evals = append(evals, cmd)
+		}
+	}
+	return evals
+}
+
+// isLiteralCommand reports whether cmd names a command without expanding
+// anything.
+func isLiteralCommand(cmd word) bool {
+	return !strings.ContainsAny(cmd.text, "$`")
+}
+
+// diagnoseEvals adds a diagnostic annotation to output for each eval in f
+// that runs commands that cannot be known statically.
+func diagnoseEvals(f *parsedFile, output *graphOutput) error {
+	var lines *lineIndex
+	for i, s := range f.scripts {
+		for _, cmd := range evalCommands(s) {
+			if _, known := evalWords(s.words, cmd); known {
+				continue
+			}
+			end := cmd.end
+			if args := commandArgs(s.words, cmd); len(args) > 0 {
+				end = args[len(args)-1].end
+			}
+			if lines == nil {
+				lines = newLineIndex(f.data)
+			}
+			a, err := makeDiagnosticAnn(f.name, lines, &Diagnostic{
+				Source:  "eval",
+				Code:    "dynamic-eval",
+				Level:   "info",
+				Message: "eval runs commands that cannot be determined statically",
+				Start:   uint32(f.sources[i].fileOffset(cmd.start)),
+				End:     uint32(f.sources[i].fileEnd(end)),
+			})
+			if err != nil {
+				return fmt.Errorf("failed to create eval diagnostic: %s", err)
+			}
+			output.Anns = append(output.Anns, a)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package bashgraph
+
+import "strings"
+
+// declArgs splits the arguments of a declaration command such as declare
+// or export into the letters of the options it sets with -, those it unsets
+// with +, and its operands. As in bash, options end at -- or at the first
+// operand.
+func declArgs(args []word) (set, unset string, operands []word) {
+	for i, a := range args {
+		text := unquote(a.text)
+		if text == "--" {
+			return set, unset, args[i+1:]
+		}
+		if len(text) < 2 || text[0] != '-' && text[0] != '+' || !isOptionLetters(text[1:]) {
+			return set, unset, args[i:]
+		}
+		if text[0] == '-' {
+			set += text[1:]
+		} else {
+			unset += text[1:]
+		}
+	}
+	return set, unset, nil
+}
+
+// isOptionLetters reports whether s is made of the letters of options.
+func isOptionLetters(s string) bool {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if !('a' <= c && c <= 'z' || 'A' <= c && c <= 'Z') {
+			return false
+		}
+	}
+	return true
+}
+
+// funcOperands returns the names of the functions that s passes to
+// declaration commands with -f or -F, as in export -f deploy or declare -f
+// deploy, with whether each is exported to child processes.
+func funcOperands(s *script) (names []word, exported []bool) {
+	for _, cmd := range s.commands {
+		name := unquote(cmd.text)
+		if !declCommands[name] && !dialectDeclCommands[s.dialect][name] {
+			continue
+		}
+		set, _, operands := declArgs(commandArgs(s.words, cmd))
+		if !strings.ContainsAny(set, "fF") {
+			continue
+		}
+		exports := name == "export" || strings.Contains(set, "x")
+		for _, a := range operands {
+			if isLiteralCommand(a) {
+				names = append(names, a)
+				exported = append(exported, exports)
+			}
+		}
+	}
+	return names, exported
+}
+
+// funcExports returns the names of the functions that s exports to child
+// processes with export -f, or declare -fx or typeset -fx.
+func funcExports(s *script) []word {
+	var exports []word
+	names, exported := funcOperands(s)
+	for i, w := range names {
+		if exported[i] {
+			exports = append(exports, w)
+		}
+	}
+	return exports
+}
+
+// exportedFuncs returns the functions in funcs that are exported to child
+// processes in files.
+func exportedFuncs(files []*parsedFile, funcs funcIndex) map[*function]bool {
+	exported := map[*function]bool{}
+	for _, f := range files {
+		for _, s := range f.scripts {
+			for _, w := range funcExports(s) {
+				if d := funcs.resolve(unquote(w.text), f); d != nil {
+					exported[d.fn] = true
+				}
+			}
+		}
+	}
+	return exported
+}
@@ -0,0 +1,150 @@
+package bashgraph
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"sourcegraph.com/sourcegraph/srclib/graph"
+)
+
+// emitFileRefs adds the refs from the words in f that name other files in
+// the repository: the scripts that f runs, as in ./scripts/build.sh or bash
+// deploy.sh, and the files that its commands are given by literal relative
+// path, as in cat config/settings.env or --config=conf/app.yml. Files that
+// aren't in the unit get a def of their own, the first time they are
+// linked, so that the refs can be followed.
+func emitFileRefs(f *parsedFile, idx *unitIndex, output *graphOutput) error {
+	for i, s := range f.scripts {
+		src := f.sources[i]
+		linked := map[int]bool{}
+		link := func(w word, path string) error {
+			if linked[w.start] {
+				return nil
+			}
+			linked[w.start] = true
+			return linkFile(f, src, w, path, idx, output)
+		}
+		commands := map[int]bool{}
+		for _, cmd := range s.commands {
+			commands[cmd.start] = true
+		}
+		for _, w := range scriptWords(s.words) {
+			name := unquote(w.text)
+			if !commands[w.start] && !strings.Contains(name, "/") {
+				// A shell looks for the script it is given in the current
+				// directory, as in bash deploy.sh.
+				name = "./" + name
+			}
+			if path, ok := resolveScriptPath(f.name, name); ok {
+				if err := link(w, path); err != nil {
+					return err
+				}
+			}
+		}
+		for _, w := range fileArgs(s) {
+			name := unquote(w.text)
+			if filepath.IsAbs(name) || strings.HasPrefix(name, "~") {
+				continue
+			}
+			if path, ok := resolveScriptPath(f.name, name); ok {
+				if err := link(w, path); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// linkFile adds the ref from the word w in the source src of f to the file
+// at path, which it names, and the def of the file if it isn't in the unit
+// and hasn't been linked before.
+func linkFile(f *parsedFile, src *source, w word, path string, idx *unitIndex, output *graphOutput) error {
+	if !idx.files[path] && !output.fileDefs[path] {
+		if info, err := os.Stat(path); err != nil || info.Size() > hugeFileSize {
+			// Large files are likely data, which isn't read for the
+			// positions of its def.
+			return nil
+		}
+		def, err := makeFileDef(f.root, path)
+		if err != nil {
+			return err
+		}
+		output.Defs = append(output.Defs, def)
+		output.fileDefs[path] = true
+	}
+	output.Refs = append(output.Refs, makeFileRef(f, src, w, path))
+	return nil
+}
+
+// fileArgs returns the words in s that may name files that commands read:
+// their arguments, the values of their options, as in --config=conf/app.yml,
+// and the files their input is redirected from. The arguments of source
+// statements are linked separately, as includes.
+func fileArgs(s *script) []word {
+	var args []word
+	for _, cmd := range s.commands {
+		if name := unquote(cmd.text); name == "source" || name == "." {
+			continue
+		}
+		for _, a := range commandArgs(s.words, cmd) {
+			if strings.HasPrefix(a.text, "-") {
+				eq := strings.IndexByte(a.text, '=')
+				if eq < 0 {
+					continue
+				}
+				a = word{text: a.text[eq+1:], start: a.start + eq + 1, end: a.end}
+			}
+			args = append(args, a)
+		}
+	}
+	for i, w := range s.words {
+		if w.op && w.text == "<" && i+1 < len(s.words) && !s.words[i+1].op {
+			args = append(args, s.words[i+1])
+		}
+	}
+	return args
+}
+
+// makeFileDef returns the def of the file at path, which is not in the unit
+// but is run or read by its scripts, in the repository at root.
+func makeFileDef(root *repoRoot, path string) (*graph.Def, error) {
+	name := filepath.Base(path)
+	data, err := json.Marshal(DefData{
+		Name:    name,
+		Keyword: "file",
+		Kind:    "file",
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &graph.Def{
+		DefKey: graph.DefKey{
+			UnitType: "BashDirectory",
+			Unit:     "bash",
+			Path:     root.defPath(path),
+		},
+		TreePath: treePath(root.defPath(path)),
+		Name:     name,
+		Kind:     "file",
+		File:     path,
+		Data:     data,
+	}, nil
+}
+
+// makeFileRef returns a ref from the word w in the source src of f to the
+// file at path, which it names.
+func makeFileRef(f *parsedFile, src *source, w word, path string) *graph.Ref {
+	return &graph.Ref{
+		DefUnitType: "BashDirectory",
+		DefUnit:     "bash",
+		DefPath:     f.root.defPath(path),
+		UnitType:    "BashDirectory",
+		Unit:        "bash",
+		File:        f.name,
+		Start:       uint32(src.fileOffset(w.start)),
+		End:         uint32(src.fileEnd(w.end)),
+	}
+}
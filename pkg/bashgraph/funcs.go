@@ -0,0 +1,198 @@
+package bashgraph
+
+import (
+	"encoding/json"
+	"runtime"
+	"sync"
+
+	"sourcegraph.com/sourcegraph/srclib/graph"
+	"sourcegraph.com/sourcegraph/srclib/unit"
+)
+
+// A funcDef is a function definition in a file of a source unit.
+type funcDef struct {
+	file *parsedFile
+	src  *source
+	fn   *function
+	// completes are the commands the function is the completion function
+	// of.
+	completes []string
+	// exported is set if the function is exported to child processes with
+	// export -f.
+	exported bool
+}
+
+// defPath returns the DefPath of the function.
+func (d *funcDef) defPath() string {
+	return d.file.defPath() + "/" + d.fn.name
+}
+
+// A funcIndex holds the function definitions of a source unit by name.
+type funcIndex map[string][]*funcDef
+
+func newFuncIndex(files []*parsedFile) funcIndex {
+	idx := funcIndex{}
+	for _, f := range files {
+		for i, s := range f.scripts {
+			for _, fn := range s.functions {
+				idx[fn.name] = append(idx[fn.name], &funcDef{file: f, src: f.sources[i], fn: fn})
+			}
+		}
+	}
+	return idx
+}
+
+// resolve returns the definition that a call of the named function in file
+// f refers to. Definitions in f itself take precedence; otherwise the first
+// definition in the unit is used, since it is usually the only one.
+func (idx funcIndex) resolve(name string, f *parsedFile) *funcDef {
+	defs := idx[name]
+	for _, d := range defs {
+		if d.file == f {
+			return d
+		}
+	}
+	if len(defs) > 0 {
+		return defs[0]
+	}
+	return nil
+}
+
+// A call is a command that runs a function defined in the unit.
+type call struct {
+	src  *source
+	word word
+	def  *funcDef
+	// caller is the function the call is in, or nil if it is at the top
+	// level of its file.
+	caller *function
+	// eval is set for calls in strings passed to eval, which may not be
+	// run as they appear.
+	eval bool
+}
+
+// calls returns the calls of functions in the shell sources of f,
+// including those in the literal strings passed to eval.
+func (idx funcIndex) calls(f *parsedFile) []*call {
+	var calls []*call
+	for i, s := range f.scripts {
+		for _, cmd := range s.commands {
+			if d := idx.resolve(unquote(cmd.text), f); d != nil {
+				calls = append(calls, &call{src: f.sources[i], word: cmd, def: d, caller: s.enclosingFunc(cmd)})
+			}
+			if unquote(cmd.text) != "eval" {
+				continue
+			}
+			words, _ := evalWords(s.words, cmd)
+			for _, c := range commandWords(words) {
+				if d := idx.resolve(unquote(c.text), f); d != nil && isLiteralCommand(c) {
+					calls = append(calls, &call{src: f.sources[i], word: c, def: d, caller: s.enclosingFunc(cmd), eval: true})
+				}
+			}
+		}
+	}
+	return calls
+}
+
+// parseUnit reads and parses the files of u, the Jobs of opts at a time
+// (or one per CPU), reusing those in the parse cache of opts that haven't
+// changed, followed by the dotenv files that they source. Files that
+// cannot be read are skipped with a warning.
+func parseUnit(u *unit.SourceUnit, opts *Options) []*parsedFile {
+	jobs := opts.Jobs
+	if jobs <= 0 {
+		jobs = runtime.NumCPU()
+	}
+	parsed := make([]*parsedFile, len(u.Files))
+	errs := make([]error, len(u.Files))
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for j := 0; j < jobs; j++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				parsed[i], errs[i] = parseUnitFile(u.Files[i], opts)
+			}
+		}()
+	}
+	for i := range u.Files {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+
+	var files []*parsedFile
+	for i, f := range parsed {
+		if errs[i] != nil {
+			opts.warnf("Skipping file: %s", errs[i])
+			continue
+		}
+		files = append(files, f)
+	}
+	files = append(files, sourcedDotenvFiles(files, opts)...)
+
+	// The files are copied to hold the unit's repoRoot, since the parsed
+	// files may be shared through the parse cache.
+	root := new(repoRoot)
+	for i, f := range files {
+		cp := *f
+		cp.root = root
+		files[i] = &cp
+	}
+	return files
+}
+
+func makeFuncDef(d *funcDef) (*graph.Def, error) {
+	var attrs []string
+	if d.exported {
+		attrs = append(attrs, "exported-function")
+	}
+	data, err := json.Marshal(DefData{
+		Name:       d.fn.name,
+		Keyword:    "function",
+		Type:       "()",
+		Kind:       "function",
+		Signature:  funcSignature(d),
+		DocHTML:    docHTML(d.file.data, d.src.fileOffset(d.fn.start)),
+		Complexity: d.fn.complexity,
+		BodyStart:  uint32(d.src.fileOffset(d.fn.bodyStart)),
+		BodyEnd:    uint32(d.src.fileEnd(d.fn.end)),
+		Completes:  d.completes,
+		Attributes: attrs,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &graph.Def{
+		DefKey: graph.DefKey{
+			UnitType: "BashDirectory",
+			Unit:     "bash",
+			Path:     d.defPath(),
+		},
+		TreePath: treePath(d.defPath()),
+		Name:     d.fn.name,
+		Kind:     "func",
+		File:     d.file.name,
+		DefStart: uint32(d.src.fileOffset(d.fn.nameStart)),
+		DefEnd:   uint32(d.src.fileEnd(d.fn.nameEnd)),
+		Exported: d.exported,
+		Data:     data,
+	}, nil
+}
+
+// makeFuncRef returns a ref to the function d from the span of w in the
+// source src of the named file.
+func makeFuncRef(filename string, src *source, w word, d *funcDef, isDef bool) *graph.Ref {
+	return &graph.Ref{
+		DefUnitType: "BashDirectory",
+		DefUnit:     "bash",
+		DefPath:     d.defPath(),
+		UnitType:    "BashDirectory",
+		Unit:        "bash",
+		Def:         isDef,
+		File:        filename,
+		Start:       uint32(src.fileOffset(w.start)),
+		End:         uint32(src.fileEnd(w.end)),
+	}
+}
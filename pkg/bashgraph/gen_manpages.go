@@ -0,0 +1,113 @@
+//go:build ignore
+// +build ignore
+
+// gen_manpages generates manpages.go, the table of man pages that commands
+// are linked to, from a listing of the pages in one or more man page
+// repositories. It also generates the tables of other documentation, such
+// as tldrpages.go, from listings in the same format.
+//
+// The listing has a "repo REPO" line before the paths of the pages in each
+// repository, one per line. A page documents the command named by its file
+// name up to the first dot, so man1p/cat.1p.txt documents cat, unless the
+// line names the command after the path, as in "html_node/Invoking.html grep".
+// When several pages document the same command, the one listed first is
+// used, so the listing gives the precedence of repositories and sections.
+//
+// Usage:
+//
+//	go run gen_manpages.go [-in LISTING] [-out FILE] [-var NAME]
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"log"
+	"os"
+	"path"
+	"sort"
+	"strings"
+)
+
+var (
+	in  = flag.String("in", "manpages.txt", "listing of the man pages")
+	out = flag.String("out", "manpages.go", "output file")
+	v   = flag.String("var", "manPages", "name of the generated variable")
+)
+
+type page struct {
+	repo, path string
+}
+
+func main() {
+	log.SetFlags(0)
+	flag.Parse()
+
+	f, err := os.Open(*in)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer f.Close()
+
+	pages := map[string]page{}
+	var repo string
+	sc := bufio.NewScanner(f)
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimSpace(sc.Text())
+		switch {
+		case line == "" || strings.HasPrefix(line, "#"):
+			continue
+		case strings.HasPrefix(line, "repo "):
+			repo = strings.TrimSpace(strings.TrimPrefix(line, "repo "))
+			continue
+		case repo == "":
+			log.Fatalf("%s:%d: page listed before any repo line", *in, n)
+		}
+		fields := strings.Fields(line)
+		if len(fields) > 2 {
+			log.Fatalf("%s:%d: expected a path and an optional command name", *in, n)
+		}
+		p := fields[0]
+		name := path.Base(p)
+		if i := strings.IndexByte(name, '.'); i >= 0 {
+			name = name[:i]
+		}
+		if len(fields) == 2 {
+			name = fields[1]
+		}
+		if _, ok := pages[name]; !ok {
+			pages[name] = page{repo: repo, path: p}
+		}
+	}
+	if err := sc.Err(); err != nil {
+		log.Fatal(err)
+	}
+
+	names := make([]string, 0, len(pages))
+	for name := range pages {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated by gen_manpages.go from %s; DO NOT EDIT.\n\n", *in)
+	fmt.Fprintf(&buf, "package bashgraph\n\n")
+	fmt.Fprintf(&buf, "// %s maps command names to the pages that document them.\n", *v)
+	fmt.Fprintf(&buf, "var %s = map[string]manPage{\n", *v)
+	for _, name := range names {
+		p := pages[name]
+		fmt.Fprintf(&buf, "\t%q: {%q, %q},\n", name, p.repo, p.path)
+	}
+	fmt.Fprintf(&buf, "}\n")
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := ioutil.WriteFile(*out, src, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
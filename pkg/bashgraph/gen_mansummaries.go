@@ -0,0 +1,354 @@
+//go:build ignore
+// +build ignore
+
+// gen_mansummaries generates mansummaries.go, the table of the one-line
+// descriptions and synopses of the commands that manpages.txt lists, from
+// the NAME and SYNOPSIS sections of their man pages. The pages are fetched
+// from their repositories on GitHub, or, with -man-dir, read from the man
+// pages installed in a directory such as /usr/share/man, for building
+// without network access. Pages that can't be fetched or parsed are
+// skipped, so their commands have no summary.
+//
+// Both the formatted text of man-pages-posix and roff sources, compressed
+// or not, are understood. Synopses that use roff strings or are too long
+// for a hover are left out.
+//
+// Usage:
+//
+//	go run gen_mansummaries.go [-in LISTING] [-out FILE] [-man-dir DIR]
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"flag"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var (
+	in     = flag.String("in", "manpages.txt", "listing of the man pages")
+	out    = flag.String("out", "mansummaries.go", "output file")
+	manDir = flag.String("man-dir", "", "read the pages installed in `DIR` instead of fetching them")
+)
+
+// maxSynopsis is the length of the longest synopsis kept.
+const maxSynopsis = 100
+
+type page struct {
+	repo, path string
+}
+
+type summary struct {
+	description, synopsis string
+}
+
+func main() {
+	log.SetFlags(0)
+	flag.Parse()
+
+	pages, err := readListing(*in)
+	if err != nil {
+		log.Fatal(err)
+	}
+	names := make([]string, 0, len(pages))
+	for name := range pages {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	summaries := map[string]summary{}
+	for _, name := range names {
+		data, err := readPage(name, pages[name])
+		if err != nil {
+			log.Printf("skipping %s: %s", name, err)
+			continue
+		}
+		if s, ok := parsePage(name, data); ok {
+			summaries[name] = s
+		} else {
+			log.Printf("skipping %s: no NAME section", name)
+		}
+	}
+
+	var buf bytes.Buffer
+	// The header names the pages the summaries were made from, since the
+	// pages installed in -man-dir may not be the ones manpages.txt lists.
+	source := "the pages " + *in + " lists"
+	doc := "// manSummaries maps command names to the descriptions and synopses in\n// their man pages.\n"
+	if *manDir != "" {
+		source = fmt.Sprintf("the pages in %s of the commands %s lists", *manDir, *in)
+		doc = fmt.Sprintf("// manSummaries maps command names to the descriptions and synopses in\n"+
+			"// their man pages. They were read from the pages installed in %s,\n"+
+			"// not from the pages refs link to, so they may describe another\n"+
+			"// implementation of a command, such as GNU's.\n", *manDir)
+	}
+	fmt.Fprintf(&buf, "// Code generated by gen_mansummaries.go from %s; DO NOT EDIT.\n\n", source)
+	fmt.Fprintf(&buf, "package bashgraph\n\n")
+	fmt.Fprintf(&buf, "%s", doc)
+	fmt.Fprintf(&buf, "var manSummaries = map[string]manSummary{\n")
+	for _, name := range names {
+		if s, ok := summaries[name]; ok {
+			fmt.Fprintf(&buf, "\t%q: {%q, %q},\n", name, s.description, s.synopsis)
+		}
+	}
+	fmt.Fprintf(&buf, "}\n")
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := ioutil.WriteFile(*out, src, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// readListing returns the page of each command in the listing at name, in
+// the format of manpages.txt, as gen_manpages.go reads it.
+func readListing(name string) (map[string]page, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	pages := map[string]page{}
+	var repo string
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		switch {
+		case line == "" || strings.HasPrefix(line, "#"):
+			continue
+		case strings.HasPrefix(line, "repo "):
+			repo = strings.TrimSpace(strings.TrimPrefix(line, "repo "))
+			continue
+		}
+		fields := strings.Fields(line)
+		name := path.Base(fields[0])
+		if i := strings.IndexByte(name, '.'); i >= 0 {
+			name = name[:i]
+		}
+		if len(fields) == 2 {
+			name = fields[1]
+		}
+		if _, ok := pages[name]; !ok {
+			pages[name] = page{repo: repo, path: fields[0]}
+		}
+	}
+	return pages, sc.Err()
+}
+
+// readPage returns the contents of p, the page of the named command, from
+// its repository or from -man-dir.
+func readPage(name string, p page) ([]byte, error) {
+	if *manDir == "" {
+		url := "https://raw.githubusercontent.com/" + strings.TrimPrefix(p.repo, "github.com/") + "/master/" + p.path
+		resp, err := http.Get(url)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("%s: %s", url, resp.Status)
+		}
+		return ioutil.ReadAll(resp.Body)
+	}
+
+	section := strings.TrimPrefix(path.Dir(p.path), "man")
+	var candidates []string
+	for _, sec := range []string{section, strings.TrimSuffix(section, "p"), "1", "8"} {
+		for _, ext := range []string{".gz", ""} {
+			candidates = append(candidates, filepath.Join(*manDir, "man"+sec[:1], name+"."+sec+ext))
+		}
+	}
+	for _, c := range candidates {
+		data, err := readManFile(c)
+		if err == nil {
+			return followSo(data), nil
+		}
+	}
+	return nil, fmt.Errorf("no page in %s", *manDir)
+}
+
+// readManFile reads the man page at name, decompressing it if it is
+// gzipped.
+func readManFile(name string) ([]byte, error) {
+	data, err := ioutil.ReadFile(name)
+	if err != nil || !strings.HasSuffix(name, ".gz") {
+		return data, err
+	}
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	return ioutil.ReadAll(r)
+}
+
+// followSo returns the page that data includes with .so, as for commands
+// documented on another command's page, or data itself.
+func followSo(data []byte) []byte {
+	line := strings.TrimSpace(string(data))
+	if !strings.HasPrefix(line, ".so ") || strings.Contains(line, "\n") {
+		return data
+	}
+	target := filepath.Join(*manDir, strings.TrimSpace(strings.TrimPrefix(line, ".so ")))
+	for _, name := range []string{target + ".gz", target} {
+		if d, err := readManFile(name); err == nil {
+			return d
+		}
+	}
+	return data
+}
+
+// parsePage returns the description and synopsis of the named command in
+// its page data, which is roff source or formatted text. Synopses of other
+// commands, as on pages that document several, are left out.
+func parsePage(cmd string, data []byte) (summary, bool) {
+	var name, synopsis string
+	if bytes.Contains(data, []byte("\n.SH")) || bytes.HasPrefix(data, []byte(".")) {
+		name = roffSection(data, "NAME", true)
+		synopsis = roffSection(data, "SYNOPSIS", true)
+	} else {
+		name = textSection(data, "NAME")
+		synopsis = textSection(data, "SYNOPSIS")
+	}
+	var desc string
+	for _, sep := range []string{" — ", " — ", " - ", " -- "} {
+		if i := strings.Index(name, sep); i >= 0 {
+			desc = strings.TrimSuffix(strings.TrimSpace(name[i+len(sep):]), ".")
+			break
+		}
+	}
+	if desc == "" {
+		return summary{}, false
+	}
+	if len(synopsis) > maxSynopsis || strings.Contains(synopsis, `\`) || synopsis != cmd && !strings.HasPrefix(synopsis, cmd+" ") {
+		synopsis = ""
+	}
+	return summary{description: desc, synopsis: synopsis}, true
+}
+
+// textSection returns the first line of the section of formatted text data
+// with the given heading.
+func textSection(data []byte, heading string) string {
+	lines := strings.Split(string(data), "\n")
+	for i, line := range lines {
+		if strings.TrimSpace(line) != heading {
+			continue
+		}
+		for _, l := range lines[i+1:] {
+			if l = strings.TrimSpace(l); l != "" {
+				return strings.Join(strings.Fields(strings.Replace(l, "−", "-", -1)), " ")
+			}
+		}
+	}
+	return ""
+}
+
+// breakMacros are the roff requests that end a line of text.
+var breakMacros = regexp.MustCompile(`^\.(br|sp|PP|LP|P|TP|IP|HP|SS|SH|RS|RE|in|ti|nf|fi)\b`)
+
+// fontMacros are the roff requests that set their arguments in a font: one
+// for all arguments, joined by spaces, or alternating between two fonts,
+// joined without space.
+var fontMacros = map[string]bool{
+	"B": false, "I": false, "SM": false, "SB": false,
+	"BR": true, "RB": true, "BI": true, "IB": true, "RI": true, "IR": true,
+}
+
+// roffEscapes are the roff escapes that text keeps as a character or
+// drops.
+var roffEscapes = strings.NewReplacer(
+	`\-`, "-", `\'`, "'", `\|`, "", `\&`, "", `\^`, "", `\/`, "", `\,`, "", `\c`, "",
+	`\(em`, "—", `\(en`, "-", `\(aq`, "'", `\(lq`, `"`, `\(rq`, `"`, `\e`, `\\`, `\ `, " ", `\~`, " ",
+)
+
+// fontEscape matches the roff escapes that change the font.
+var fontEscape = regexp.MustCompile(`\\f(\[[^]]*\]|\(..|.)`)
+
+// roffSection returns the text of the section of the roff source data with
+// the given heading, or, if first is set, only its first line.
+func roffSection(data []byte, heading string, first bool) string {
+	var words []string
+	in := false
+	for _, line := range strings.Split(string(data), "\n") {
+		if strings.HasPrefix(line, ".SH") {
+			if in {
+				break
+			}
+			in = strings.Trim(strings.TrimSpace(strings.TrimPrefix(line, ".SH")), `"`) == heading
+			continue
+		}
+		if !in || strings.HasPrefix(line, `.\"`) || strings.HasPrefix(line, `'\"`) {
+			continue
+		}
+		if breakMacros.MatchString(line) {
+			if first && len(words) > 0 {
+				break
+			}
+			continue
+		}
+		text := line
+		if strings.HasPrefix(line, ".") {
+			fields := roffArgs(line[1:])
+			if len(fields) == 0 {
+				continue
+			}
+			alternate, ok := fontMacros[fields[0]]
+			if !ok {
+				continue
+			}
+			sep := " "
+			if alternate {
+				sep = ""
+			}
+			text = strings.Join(fields[1:], sep)
+		}
+		text = strings.TrimSuffix(text, `\`)
+		text = roffEscapes.Replace(fontEscape.ReplaceAllString(text, ""))
+		words = append(words, strings.Fields(text)...)
+	}
+	return strings.Join(words, " ")
+}
+
+// roffArgs splits the arguments of a roff request, some of which may be
+// quoted.
+func roffArgs(line string) []string {
+	var args []string
+	for line = strings.TrimSpace(line); line != ""; line = strings.TrimSpace(line) {
+		if line[0] == '"' {
+			end := strings.IndexByte(line[1:], '"')
+			if end < 0 {
+				end = len(line) - 1
+			}
+			args = append(args, line[1:end+1])
+			line = line[min(end+2, len(line)):]
+			continue
+		}
+		end := strings.IndexAny(line, " \t")
+		if end < 0 {
+			end = len(line)
+		}
+		args = append(args, line[:end])
+		line = line[end:]
+	}
+	return args
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
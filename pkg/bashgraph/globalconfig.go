@@ -0,0 +1,143 @@
+package bashgraph
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// globalConfigKeys are the settings the global config file may set. They
+// are named like the environment variables, in lower case.
+var globalConfigKeys = map[string]bool{
+	"extensions":    true,
+	"excludes":      true,
+	"docs":          true,
+	"posix_edition": true,
+	"command_map":   true,
+	"jobs":          true,
+	"cache_dir":     true,
+	"resolvers":     true,
+}
+
+// A globalConfig holds the machine-level defaults read from the global
+// config file.
+type globalConfig struct {
+	settings map[string]string
+	commands commandMap
+}
+
+var (
+	globalConfigOnce sync.Once
+	globalConfigData globalConfig
+)
+
+// globalConfigPath returns the path of the global config file: the value
+// of SRCLIB_BASH_CONFIG if it is set, or else srclib-bash/config.toml in
+// the user's config directory ($XDG_CONFIG_HOME or ~/.config).
+func globalConfigPath() string {
+	if name := os.Getenv(envPrefix + "CONFIG"); name != "" {
+		return name
+	}
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "srclib-bash", "config.toml")
+}
+
+// loadGlobalConfig returns the global config, reading it the first time it
+// is called. A missing file is an empty config; an invalid one is logged
+// and ignored, so that a broken machine-level file doesn't stop indexing.
+func loadGlobalConfig() globalConfig {
+	globalConfigOnce.Do(func() {
+		name := globalConfigPath()
+		if name == "" {
+			return
+		}
+		data, err := ioutil.ReadFile(name)
+		if os.IsNotExist(err) {
+			return
+		}
+		if err == nil {
+			globalConfigData, err = parseGlobalConfig(data)
+		}
+		if err != nil {
+			logWarnf("Ignoring global config file %s: %s", name, err)
+			return
+		}
+		logDebugf("Read global config file %s", name)
+	})
+	return globalConfigData
+}
+
+// parseGlobalConfig parses the TOML data of a global config file, whose
+// top-level keys are settings and whose [commands.NAME] tables are inline
+// command map entries.
+func parseGlobalConfig(data []byte) (globalConfig, error) {
+	doc, err := parseTOML(data)
+	if err != nil {
+		return globalConfig{}, err
+	}
+	c := globalConfig{settings: map[string]string{}, commands: commandMap{}}
+	keys := make([]string, 0, len(doc))
+	for k := range doc {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if k == "commands" {
+			if err := parseGlobalCommands(doc[k], c.commands); err != nil {
+				return globalConfig{}, err
+			}
+			continue
+		}
+		if !globalConfigKeys[k] {
+			return globalConfig{}, fmt.Errorf("unknown setting %s", k)
+		}
+		s, err := configString(doc[k])
+		if err != nil {
+			return globalConfig{}, err
+		}
+		c.settings[k] = s
+	}
+	return c, nil
+}
+
+// parseGlobalCommands adds the command targets in v, the [commands] table
+// of a global config file, to m.
+func parseGlobalCommands(v interface{}, m commandMap) error {
+	table, ok := v.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("commands must be a table")
+	}
+	for cmd, v := range table {
+		fields, ok := v.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("commands.%s must be a table", cmd)
+		}
+		field := func(name string) string {
+			s, _ := fields[name].(string)
+			return s
+		}
+		t := &CommandTarget{DefRepo: field("DefRepo"), DefUnitType: field("DefUnitType"), DefUnit: field("DefUnit"), DefPath: field("DefPath")}
+		if t.DefRepo == "" || t.DefUnitType == "" || t.DefUnit == "" || t.DefPath == "" {
+			return fmt.Errorf("target of command %s must set DefRepo, DefUnitType, DefUnit and DefPath", cmd)
+		}
+		m[cmd] = t
+	}
+	return nil
+}
+
+// globalSetting returns the value of the setting of the global config file
+// that corresponds to the environment variable envPrefix+env.
+func globalSetting(env string) string {
+	return loadGlobalConfig().settings[strings.ToLower(env)]
+}
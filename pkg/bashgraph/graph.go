@@ -0,0 +1,1272 @@
+package bashgraph
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/mkovacs/bash/scanner"
+
+	"sourcegraph.com/sourcegraph/srclib/graph"
+	"sourcegraph.com/sourcegraph/srclib/unit"
+)
+
+// Options are the settings of an analysis, which the graph command's flags
+// set. Settings left unset are read from the SRCLIB_BASH_* environment
+// variables, or else from the Srcfile's Config or the global config file,
+// when the analysis starts.
+type Options struct {
+	LocalMan       bool     `long:"local-man" description:"also link commands to the man pages installed on this host"`
+	LocalManRepo   string   `long:"local-man-repo" description:"repository to attribute installed man pages to" default:"localhost/man"`
+	CommandMap     string   `long:"command-map" description:"JSON file mapping command names to the defs to link them to" value-name:"FILE"`
+	Keywords       bool     `long:"keywords" description:"link shell keywords such as if and while to the bash man page"`
+	RemoteCommands bool     `long:"remote-commands" description:"parse the command strings passed to ssh, docker exec, kubectl exec and sh -c"`
+	Secrets        bool     `long:"secrets" description:"flag credentials written in scripts, such as AWS keys and tokens assigned to variables, as security annotations"`
+	GNUDocs        bool     `long:"gnu-docs" description:"link GNU tools run with long options to the GNU manuals instead of man pages"`
+	PosixEdition   string   `long:"posix-edition" description:"POSIX edition whose man pages refs link to" choice:"2008" choice:"2013" choice:"2016" choice:"2017" choice:"2024" value-name:"YEAR"`
+	Docs           []string `long:"docs" description:"documentation to link commands to; may be given more than once" choice:"man" choice:"tldr"`
+	Files          []string `long:"file" description:"graph only FILE of the source units' files; may be given more than once" value-name:"FILE"`
+	IncludeGlobs   []string `long:"include-glob" description:"graph only the files matching PATTERN; may be given more than once" value-name:"PATTERN"`
+	ExcludeGlobs   []string `long:"exclude-glob" description:"don't graph the files matching PATTERN; may be given more than once" value-name:"PATTERN"`
+	Jobs           int      `long:"jobs" short:"j" description:"number of files to parse at once (default: the number of CPUs)" value-name:"N"`
+	Resolvers      []string `long:"resolver" description:"compiled-in resolver to link commands with, in order; may be given more than once (default: all of them, by name)" value-name:"NAME"`
+
+	// CacheDir is the directory of the cache of analyzed files, or off to
+	// disable it, as the --cache-dir option sets it for the commands.
+	CacheDir string
+	// Warnf, if set, is called with the warnings about the analysis, such
+	// as the files that couldn't be read. The commands log them.
+	Warnf func(format string, args ...interface{})
+
+	// cache holds the files parsed already, for the commands that graph
+	// the same files again as they change, or is nil to parse every file
+	// afresh.
+	cache *parseCache
+}
+
+// defaultLocalManRepo is the repository that installed man pages are
+// attributed to by default, as the --local-man-repo option's default says.
+const defaultLocalManRepo = "localhost/man"
+
+type GraphCmd struct {
+	Options
+	Summary  bool   `long:"summary" description:"after the output, print a table of the defs, refs, docs and warnings in each file to STDERR"`
+	Filename string `long:"filename" description:"read the contents of the file NAME from STDIN, instead of source units, and graph it alone" value-name:"NAME"`
+	Watch    bool   `long:"watch" description:"after the output, keep watching the units' files and output the graph of each batch of changed files"`
+	SQLite   string `long:"sqlite" description:"write the output to the SQLite database FILE, replacing the index it holds, instead of STDOUT" value-name:"FILE"`
+}
+
+func (c *GraphCmd) Execute(args []string) error {
+	var out *graphOutput
+	var units unit.SourceUnits
+	if c.Filename != "" {
+		if c.Watch {
+			return fmt.Errorf("--watch can't be used with --filename")
+		}
+		if err := c.applySettings(nil); err != nil {
+			return err
+		}
+		data, err := ioutil.ReadAll(os.Stdin)
+		if err != nil {
+			return fmt.Errorf("Failed to read STDIN: %s", err)
+		}
+		if out, err = graphBuffer(c.Filename, data, &c.Options); err != nil {
+			return fmt.Errorf("Failed to graph %s: %s", c.Filename, err)
+		}
+	} else {
+		var err error
+		if units, err = readSourceUnits(); err != nil {
+			return err
+		}
+		if err := c.applySettings(unitsConfig(units)); err != nil {
+			return err
+		}
+		if err := c.checkFileFilters(units); err != nil {
+			return err
+		}
+		if c.Watch {
+			// Files that haven't changed are not parsed again.
+			c.cache = newParseCache()
+		}
+		if out, err = graphUnits(units, &c.Options); err != nil {
+			return fmt.Errorf("Failed to graph source units: %s", err)
+		}
+	}
+
+	pout, err := withPositions(out, &c.Options)
+	if err != nil {
+		return fmt.Errorf("Failed to compute positions: %s", err)
+	}
+
+	if err := c.writer().write(units, pout); err != nil {
+		return err
+	}
+	if c.Summary {
+		printSummary(os.Stderr, out, isColorTerminal(os.Stderr))
+	}
+	if c.Watch {
+		return c.watchOutput(units)
+	}
+	return nil
+}
+
+// writer returns the graphWriter that writes the output: to STDOUT as JSON
+// or, with --sqlite, to the database.
+func (c *GraphCmd) writer() graphWriter {
+	if c.SQLite != "" {
+		return sqliteGraphWriter{c.SQLite}
+	}
+	return jsonGraphWriter{os.Stdout}
+}
+
+// watchOutput watches the files of units, writing the updates to STDOUT as
+// lines of JSON or, with --sqlite, to the database.
+func (c *GraphCmd) watchOutput(units unit.SourceUnits) error {
+	if c.SQLite == "" {
+		enc := json.NewEncoder(os.Stdout)
+		return c.watch(units, func(update *watchUpdate) error {
+			if err := enc.Encode(update); err != nil {
+				return fmt.Errorf("Failed to output graph data: %s", err)
+			}
+			return nil
+		})
+	}
+	db, err := openSQLite(c.SQLite)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	return c.watch(units, func(update *watchUpdate) error {
+		files := append(append([]string(nil), update.Files...), update.Removed...)
+		return updateSQLite(db, nil, files, update.positionedOutput)
+	})
+}
+
+// readSourceUnits reads the source units to operate on from STDIN.
+func readSourceUnits() (unit.SourceUnits, error) {
+	inputBytes, err := ioutil.ReadAll(os.Stdin)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read STDIN: %s", err)
+	}
+	var units unit.SourceUnits
+	if err := json.NewDecoder(bytes.NewReader(inputBytes)).Decode(&units); err != nil {
+		// Legacy API: try parsing input as a single source unit
+		var u *unit.SourceUnit
+		if err := json.NewDecoder(bytes.NewReader(inputBytes)).Decode(&u); err != nil {
+			return nil, fmt.Errorf("Failed to parse source units from input: %s", err)
+		}
+		units = unit.SourceUnits{u}
+	}
+	if err := os.Stdin.Close(); err != nil {
+		return nil, fmt.Errorf("Failed to close STDIN: %s", err)
+	}
+
+	if len(units) == 0 {
+		return nil, fmt.Errorf("Input contains no source unit data.")
+	}
+	logDebugf("Read %d source units", len(units))
+	return units, nil
+}
+
+// graphOutput is the output of graphing source units, along with data
+// about refs that graph.Ref has no field for.
+type graphOutput struct {
+	graph.Output
+	// flags holds the options passed to the commands that refs to man
+	// pages and command map targets are from.
+	flags map[*graph.Ref][]string
+	// sections holds the sections of the bash man page that refs to shell
+	// keywords link to.
+	sections map[*graph.Ref]string
+	// indirect holds the refs to commands named in the values of variables
+	// that are later run, as in CMD="grep -r"; $CMD.
+	indirect map[*graph.Ref]bool
+	// viaVariable holds the names of the variables that refs to commands
+	// run through variables, as in RSYNC=rsync; $RSYNC, are from.
+	viaVariable map[*graph.Ref]string
+	// lowConfidence holds the refs from strings passed to eval, which may
+	// not be run as they appear.
+	lowConfidence map[*graph.Ref]bool
+	// namerefTarget holds what the namerefs that refs are to refer to, and
+	// viaNameref the names of the namerefs that refs to their targets are
+	// through, as in declare -n out=result; out=1.
+	namerefTarget map[*graph.Ref]string
+	viaNameref    map[*graph.Ref]string
+	// privileged holds why the commands that refs are from require
+	// elevated privileges, as in sudo or cp app.conf /etc.
+	privileged map[*graph.Ref]string
+	// network holds how the commands that refs are from reach the network,
+	// as in curl https://example.com.
+	network map[*graph.Ref]*NetworkUse
+	// fileDefs holds the files outside the source units that have been
+	// given a def, since scripts run or read them.
+	fileDefs map[string]bool
+	// warnings are the issues with how well the files were indexed.
+	warnings []*Warning
+	// elapsed holds the time it took to graph each file, by name.
+	elapsed map[string]time.Duration
+	// contents holds the contents of graphed files that are not read from
+	// disk, such as editor buffers, by name.
+	contents map[string][]byte
+	// firstFileRef is the index in Refs of the first ref of the file being
+	// graphed.
+	firstFileRef int
+}
+
+// fileRefs returns the refs that the emitters have added for the file being
+// graphed so far.
+func (o *graphOutput) fileRefs() []*graph.Ref {
+	return o.Refs[o.firstFileRef:]
+}
+
+// newGraphOutput returns an empty graphOutput.
+func newGraphOutput() *graphOutput {
+	return &graphOutput{
+		flags:         map[*graph.Ref][]string{},
+		sections:      map[*graph.Ref]string{},
+		indirect:      map[*graph.Ref]bool{},
+		viaVariable:   map[*graph.Ref]string{},
+		lowConfidence: map[*graph.Ref]bool{},
+		namerefTarget: map[*graph.Ref]string{},
+		viaNameref:    map[*graph.Ref]string{},
+		privileged:    map[*graph.Ref]string{},
+		network:       map[*graph.Ref]*NetworkUse{},
+		fileDefs:      map[string]bool{},
+		elapsed:       map[string]time.Duration{},
+		contents:      map[string][]byte{},
+	}
+}
+
+// graphUnits graphs units with opts, one after another, into a single
+// output.
+func graphUnits(units unit.SourceUnits, opts *Options) (*graphOutput, error) {
+	output := newGraphOutput()
+
+	for _, u := range units {
+		if err := graphUnit(u, opts, opts.selected, output); err != nil {
+			return nil, err
+		}
+	}
+
+	sortOutput(&output.Output)
+	sort.Sort(warningsByStart(output.warnings))
+	return output, nil
+}
+
+// graphUnit adds the graph with opts of the files of u that selected
+// reports are to be graphed to output. The other files still define the
+// functions, variables and aliases that refs resolve to.
+func graphUnit(u *unit.SourceUnit, opts *Options, selected func(name string) bool, output *graphOutput) error {
+	logDebugf("Graphing unit %s with %d files", u.Name, len(u.Files))
+	files := parseUnit(u, opts)
+	idx, err := defaultPipeline.indexer.index(u, files, opts)
+	if err != nil {
+		return err
+	}
+	for _, f := range files {
+		if selected(f.name) {
+			start := time.Now()
+			if err := graphFile(f, idx, output); err != nil {
+				opts.warnf("Failed to graph %s completely: %s", f.name, err)
+			}
+			output.elapsed[f.name] += time.Since(start)
+			// Positions are computed from the data that was parsed,
+			// without reading the file again.
+			output.contents[f.name] = f.data
+		}
+	}
+	return nil
+}
+
+// applySettings fills in the settings not given with flags, as
+// Options.applySettings does, and those that the global options set.
+func (c *GraphCmd) applySettings(config map[string]string) error {
+	if c.SQLite != "" && sqliteDriver == "" {
+		// Fail before graphing rather than when writing the output.
+		return fmt.Errorf("--sqlite can't be used: %s", errNoSQLite)
+	}
+	c.applyGlobalOptions()
+	return c.Options.applySettings(config)
+}
+
+// commandOptions returns the options of the commands that parse or graph
+// source units without the graph command's flags: the defaults, and the
+// cache and logging that the global options set.
+func commandOptions() *Options {
+	o := &Options{LocalManRepo: defaultLocalManRepo}
+	o.applyGlobalOptions()
+	return o
+}
+
+// applyGlobalOptions sets the options that the global options of the
+// command line set for every command.
+func (o *Options) applyGlobalOptions() {
+	o.CacheDir = cacheOpts.CacheDir
+	o.Warnf = logWarnf
+}
+
+// applySettings fills in the settings not given from the SRCLIB_BASH_*
+// environment variables or else from config, the Srcfile's Config, and
+// checks them.
+func (o *Options) applySettings(config map[string]string) error {
+	if o.LocalManRepo == "" {
+		o.LocalManRepo = defaultLocalManRepo
+	}
+	o.Docs = listSetting(o.Docs, "DOCS", config, "bashDocs")
+	for _, d := range o.Docs {
+		if err := checkChoice("docs", d, "man", "tldr"); err != nil {
+			return err
+		}
+	}
+	o.PosixEdition = stringSetting(o.PosixEdition, "POSIX_EDITION", config, "bashPosixEdition")
+	if o.PosixEdition != "" {
+		if err := checkChoice("posix_edition", o.PosixEdition, "2008", "2013", "2016", "2017", "2024"); err != nil {
+			return err
+		}
+	}
+	o.Resolvers = listSetting(o.Resolvers, "RESOLVERS", config, "bashResolvers")
+	if _, err := enabledResolvers(o.Resolvers); err != nil {
+		return err
+	}
+	var err error
+	if o.Jobs, err = intSetting(o.Jobs, "JOBS", config, "bashJobs"); err != nil {
+		return err
+	}
+	return nil
+}
+
+// warnf calls o.Warnf, if set, with a warning about the analysis.
+func (o *Options) warnf(format string, args ...interface{}) {
+	if o.Warnf != nil {
+		o.Warnf(format, args...)
+	}
+}
+
+// graphBuffer graphs data, the contents of the named file, such as an
+// unsaved editor buffer, with opts as a source unit of its own. The file
+// need not exist, and data is used for its positions.
+func graphBuffer(name string, data []byte, opts *Options) (*graphOutput, error) {
+	f, err := parseData(name, data)
+	if err != nil {
+		return nil, err
+	}
+	output := newGraphOutput()
+	output.contents[name] = data
+	idx, err := defaultPipeline.indexer.index(&unit.SourceUnit{}, []*parsedFile{f}, opts)
+	if err != nil {
+		return nil, err
+	}
+	start := time.Now()
+	if err := graphFile(f, idx, output); err != nil {
+		return nil, err
+	}
+	output.elapsed[name] = time.Since(start)
+	sortOutput(&output.Output)
+	sort.Sort(warningsByStart(output.warnings))
+	return output, nil
+}
+
+// checkFileFilters checks that the files given with --file are in units and
+// that the patterns given with --include-glob and --exclude-glob are valid.
+func (o *Options) checkFileFilters(units unit.SourceUnits) error {
+	inUnits := map[string]bool{}
+	for _, u := range units {
+		for _, name := range u.Files {
+			inUnits[filepath.Clean(name)] = true
+		}
+	}
+	for _, name := range o.Files {
+		if !inUnits[filepath.Clean(name)] {
+			return fmt.Errorf("File %s is not in any source unit", name)
+		}
+	}
+	for _, pattern := range append(append([]string(nil), o.IncludeGlobs...), o.ExcludeGlobs...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("Invalid glob pattern %q: %s", pattern, err)
+		}
+	}
+	return nil
+}
+
+// selected reports whether the file name of a source unit is to be graphed:
+// whether it is one of the files given with --file, if any, matches one of
+// the patterns given with --include-glob, if any, and matches none of the
+// patterns given with --exclude-glob. The files that aren't graphed still
+// define the functions, variables and aliases that refs resolve to.
+func (o *Options) selected(name string) bool {
+	if len(o.Files) > 0 {
+		found := false
+		for _, f := range o.Files {
+			if filepath.Clean(f) == filepath.Clean(name) {
+				found = true
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if len(o.IncludeGlobs) > 0 && !matchesGlob(o.IncludeGlobs, name) {
+		return false
+	}
+	return !matchesGlob(o.ExcludeGlobs, name)
+}
+
+// matchesGlob reports whether the file name matches one of patterns. A
+// pattern without a slash matches the base name of the file anywhere, as
+// in .gitignore files; one with a slash matches the whole name.
+func matchesGlob(patterns []string, name string) bool {
+	name = filepath.ToSlash(filepath.Clean(name))
+	for _, pattern := range patterns {
+		target := name
+		if !strings.Contains(pattern, "/") {
+			target = path.Base(name)
+		}
+		if ok, _ := path.Match(pattern, target); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// A unitIndex holds the definitions in a source unit that refs resolve to.
+type unitIndex struct {
+	funcs    funcIndex
+	vars     *varIndex
+	aliases  aliasIndex
+	npm      npmIndex
+	commands *commandResolver
+	// completed holds the commands that completion functions complete.
+	completed map[*function][]string
+	// exported holds the functions exported to child processes.
+	exported map[*function]bool
+	// files holds the names of the files of the unit.
+	files map[string]bool
+	// opts are the options the unit is graphed with.
+	opts *Options
+}
+
+func newUnitIndex(files []*parsedFile) *unitIndex {
+	funcs := newFuncIndex(files)
+	idx := &unitIndex{
+		funcs:     funcs,
+		vars:      newVarIndex(files),
+		aliases:   newAliasIndex(files),
+		npm:       newNPMIndex(files),
+		completed: completedCommands(files, funcs),
+		exported:  exportedFuncs(files, funcs),
+		files:     map[string]bool{},
+		opts:      &Options{},
+	}
+	for _, f := range files {
+		idx.files[filepath.Clean(f.name)] = true
+	}
+	return idx
+}
+
+// graphNamerefUse records, for the last ref in output, from the span
+// start:end of src to the variable d, what d refers to if it is a nameref.
+// A use of a nameref that refers to a variable by name is also a ref to that
+// variable.
+func graphNamerefUse(f *parsedFile, src *source, start, end int, d *varDecl, idx *unitIndex, output *graphOutput) {
+	target := d.site.nameref
+	if target == "" {
+		return
+	}
+	output.namerefTarget[output.Refs[len(output.Refs)-1]] = target
+	if !isName(target) {
+		return
+	}
+	if t := idx.vars.resolve(target, d.file, d.site.local); t != nil && t != d {
+		ref := makeVarRef(f.name, src, start, end, t, false)
+		output.Refs = append(output.Refs, ref)
+		output.viaNameref[ref] = d.site.name
+	}
+}
+
+// graphFile adds the defs, refs, docs and diagnostics of f, a file of the
+// unit that idx indexes, to output, running each emitter of the pipeline on
+// it, and the warnings about how well it was indexed.
+func graphFile(f *parsedFile, idx *unitIndex, output *graphOutput) error {
+	output.firstFileRef = len(output.Refs)
+	for _, e := range defaultPipeline.emitters {
+		if err := e.emit(f, idx, output); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// utf8BOM is the byte order mark that some editors put at the start of
+// UTF-8 files.
+var utf8BOM = []byte("\xef\xbb\xbf")
+
+// extractSources returns the shell sources contained in the named file. A
+// byte order mark at the start of the file is ignored.
+func extractSources(name string, data []byte) ([]*source, error) {
+	if bytes.HasPrefix(data, utf8BOM) {
+		sources, err := extractSources(name, data[len(utf8BOM):])
+		for _, src := range sources {
+			src.shift(len(utf8BOM))
+		}
+		return sources, err
+	}
+
+	switch filepath.Base(name) {
+	case "package.json":
+		return npmScriptSources(data)
+	}
+	if isCIConfigFile(name) {
+		return ciShellSources(name, data), nil
+	}
+	if isCrontabFile(name) {
+		return crontabSources(name, data), nil
+	}
+	if isSystemdServiceFile(name) {
+		return systemdExecSources(data), nil
+	}
+	if isAnsibleFile(name) {
+		return yamlShellSources(data, ansibleShellKeys), nil
+	}
+	if isVagrantfile(name) {
+		return vagrantInlineSources(data), nil
+	}
+	return []*source{{text: string(data)}}, nil
+}
+
+// graphSource adds the refs to commands in the source src of the named file
+// to output. It returns the spans of src that the identifier scanner
+// skipped.
+func graphSource(name string, src *source, s *script, idx *unitIndex, output *graphOutput) ([]scanError, error) {
+	// Command names end where the scanner's identifiers for them do, or
+	// just before a closing quote.
+	commands := map[int]word{}
+	for _, cmd := range s.commands {
+		end := cmd.end
+		if strings.HasSuffix(cmd.text, `"`) || strings.HasSuffix(cmd.text, "'") {
+			end--
+		}
+		commands[end] = cmd
+	}
+	// The commands in strings passed to eval, and with --remote-commands
+	// in command strings as in ssh host 'ls -l', are commands too, whose
+	// arguments are the words of the strings.
+	nested := map[int][]word{}
+	evaluated := map[int]bool{}
+	for _, cmd := range evalCommands(s) {
+		words, _ := evalWords(s.words, cmd)
+		for _, c := range commandWords(words) {
+			if isLiteralCommand(c) {
+				commands[c.end] = c
+				nested[c.end] = words
+				evaluated[c.end] = true
+			}
+		}
+	}
+	if idx.opts.RemoteCommands {
+		for _, words := range remoteCommandLists(s.words) {
+			for _, cmd := range commandWords(words) {
+				commands[cmd.end] = cmd
+				nested[cmd.end] = words
+			}
+		}
+	}
+	// Commands named in assigned values are only linked if the variables
+	// are run.
+	values := assignedValues(s)
+	executed := executedVars(s)
+	// Words in messages and here-strings are not commands.
+	data := dataWords(s)
+	// Nor are the names of variables, where they are assigned, declared or
+	// expanded.
+	variables := map[int]bool{}
+	for _, site := range s.vars {
+		variables[site.end] = true
+	}
+	for _, ref := range s.varRefs {
+		variables[ref.end] = true
+	}
+
+	skipped, err := scanIdents(src.text, func(ident string, offset int) error {
+		// fmt.Fprintf(os.Stderr, "ident: \"%s\" at %d\n", ident, offset-len(ident))
+		if inLongerName(src.text, offset-len(ident), offset) {
+			// The scanner splits names such as module::cat or my.tr
+			// into several identifiers, none of which is a command.
+			return nil
+		}
+		cmd, isCommand := commands[offset]
+		if isCommand && idx.commands.lookup(cmd, name) != nil {
+			// Linked to its command map target instead.
+			return nil
+		}
+		if isCommand && idx.npm.localBin(name, cmd) != "" {
+			// Linked to the file of the local command instead.
+			return nil
+		}
+		if !isCommand && inWords(data, offset-len(ident), offset) {
+			// Data, such as done in echo "done".
+			return nil
+		}
+		if !isCommand && variables[offset] {
+			// A variable, such as date in date=$(date).
+			return nil
+		}
+		indirect := false
+		if !isCommand {
+			if v := valueAt(src.text, values, offset-len(ident), offset); v != nil {
+				if !executed[v.name] {
+					// A literal value, such as vi in EDITOR=vi.
+					return nil
+				}
+				indirect = true
+			}
+		}
+		var flags []string
+		if isCommand {
+			words := s.words
+			if r, ok := nested[offset]; ok {
+				words = r
+			}
+			flags = commandFlags(words, cmd)
+		}
+		for _, p := range commandPages(idx.opts, ident, isCommand, flags) {
+			// ref to a standard command
+			ref, err := makeCommandRef(name, ident, p.docs, p.page, offset)
+			if err != nil {
+				return fmt.Errorf("failed to create command ref: %s", err)
+			}
+			ref.Start = uint32(src.fileOffset(offset - len(ident)))
+			ref.End = uint32(src.fileEnd(offset))
+			output.Refs = append(output.Refs, ref)
+			if len(flags) > 0 {
+				output.flags[ref] = flags
+			}
+			if indirect {
+				output.indirect[ref] = true
+			}
+			if evaluated[offset] {
+				output.lowConfidence[ref] = true
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Variables that are run as commands, as in RSYNC=rsync; $RSYNC, are
+	// also linked to the pages of the commands they hold.
+	for _, cmd := range s.commands {
+		v := expandedVar(cmd)
+		if v == "" {
+			continue
+		}
+		command, flags, ok := constantCommand(src.text, values, v)
+		if !ok || idx.commands.lookup(word{text: command}, name) != nil {
+			continue
+		}
+		flags = append(flags, commandFlags(s.words, cmd)...)
+		for _, p := range commandPages(idx.opts, command, true, flags) {
+			ref, err := makeCommandRef(name, command, p.docs, p.page, cmd.end)
+			if err != nil {
+				return nil, fmt.Errorf("failed to create command ref: %s", err)
+			}
+			ref.Start = uint32(src.fileOffset(cmd.start))
+			ref.End = uint32(src.fileEnd(cmd.end))
+			output.Refs = append(output.Refs, ref)
+			if len(flags) > 0 {
+				output.flags[ref] = flags
+			}
+			output.viaVariable[ref] = v
+		}
+	}
+
+	if idx.opts.Keywords {
+		graphKeywords(name, src, s, output)
+	}
+
+	return skipped, nil
+}
+
+// A scanError is a span of a source that the identifier scanner could not
+// tokenize, from the character it failed at to the end of its line.
+type scanError struct {
+	start, end int
+	err        error
+}
+
+// scanIdents calls visit with each identifier the scanner finds in text and
+// the offset just past it. When the scanner hits a character it can't
+// tokenize, it skips the rest of the line and resumes scanning on the next
+// one; the skipped spans are returned.
+func scanIdents(text string, visit func(ident string, offset int) error) ([]scanError, error) {
+	var skipped []scanError
+	// The scanner reports an identifier at the very end of its input as a
+	// number, so make sure the input ends with a newline.
+	text += "\n"
+	base := 0
+	sc := scanner.Scanner{}
+	sc.Init(strings.NewReader(text))
+	for {
+		tok, err := sc.Scan()
+		if err != nil {
+			start := base + sc.Pos().Offset
+			end := strings.IndexByte(text[start:], '\n')
+			if end < 0 {
+				end = len(text)
+			} else {
+				end += start
+			}
+			skipped = append(skipped, scanError{start: start, end: end, err: err})
+			if end >= len(text)-1 {
+				break
+			}
+			base = end + 1
+			sc = scanner.Scanner{}
+			sc.Init(strings.NewReader(text[base:]))
+			continue
+		}
+		if tok == scanner.EOF {
+			break
+		} else if tok == scanner.Ident {
+			if err := visit(sc.TokenText(), base+sc.Pos().Offset); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return skipped, nil
+}
+
+// diagnoseScanErrors adds a diagnostic annotation to output for each span of
+// the source src of f that the identifier scanner skipped.
+func diagnoseScanErrors(f *parsedFile, src *source, skipped []scanError, output *graphOutput) error {
+	if len(skipped) == 0 {
+		return nil
+	}
+	lines := newLineIndex(f.data)
+	for _, e := range skipped {
+		end := e.end
+		if end > len(src.text) {
+			end = len(src.text)
+		}
+		a, err := makeDiagnosticAnn(f.name, lines, &Diagnostic{
+			Source:  "scanner",
+			Code:    "skipped",
+			Level:   "warning",
+			Message: fmt.Sprintf("the rest of the line was not graphed: %s", e.err),
+			Start:   uint32(src.fileOffset(e.start)),
+			End:     uint32(src.fileEnd(end)),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create scanner diagnostic: %s", err)
+		}
+		output.Anns = append(output.Anns, a)
+	}
+	return nil
+}
+
+// messageCommands are the commands whose arguments are text to display.
+var messageCommands = map[string]bool{
+	"echo":   true,
+	"printf": true,
+}
+
+// dataWords returns the words in s that are data rather than code: the
+// arguments of the commands that display text, such as echo and printf,
+// here-strings, as in grep foo <<< "$data", glob patterns with groups, as
+// in rm !(sort), brace expansions, as in touch {sort,join}.log, and the
+// operands of conditionals, as in [[ -x /bin/sort ]].
+func dataWords(s *script) []word {
+	var data []word
+	for _, cmd := range s.commands {
+		if messageCommands[unquote(cmd.text)] {
+			data = append(data, commandArgs(s.words, cmd)...)
+		}
+	}
+	pos := commandPositions(s.words)
+	inCond := false
+	for i, w := range s.words {
+		switch {
+		case inCond:
+			inCond = w.text != "]]"
+			if !w.op {
+				data = append(data, w)
+			}
+		case w.text == "[[" && pos[i]:
+			inCond = true
+		}
+		if w.op && w.text == "<<<" && i+1 < len(s.words) && !s.words[i+1].op {
+			data = append(data, s.words[i+1])
+		}
+		if isGlobPattern(w) || isBraceExpansion(w) {
+			data = append(data, w)
+		}
+	}
+	return data
+}
+
+// inWords reports whether the offsets start to end are in one of words.
+func inWords(words []word, start, end int) bool {
+	for _, w := range words {
+		if w.start <= start && end <= w.end {
+			return true
+		}
+	}
+	return false
+}
+
+// nameChars are the characters other than letters, digits and underscores
+// that may appear in function names and file names, as in module::fn,
+// git-sync or lib.sh.
+const nameChars = ".-:+@"
+
+// inLongerName reports whether text[start:end] is part of a longer name,
+// being adjacent to name characters (including non-ASCII letters).
+func inLongerName(text string, start, end int) bool {
+	isPart := func(ch byte) bool {
+		return isNameChar(ch, false) || ch >= utf8.RuneSelf || strings.IndexByte(nameChars, ch) >= 0
+	}
+	return start > 0 && isPart(text[start-1]) || end < len(text) && isPart(text[end])
+}
+
+// keywordSections maps the shell keywords that start constructs to the
+// sections of the bash man page that describe them.
+var keywordSections = map[string]string{
+	"!":        "Pipelines",
+	"[[":       "Compound Commands",
+	"case":     "Compound Commands",
+	"for":      "Compound Commands",
+	"function": "Shell Function Definitions",
+	"if":       "Compound Commands",
+	"select":   "Compound Commands",
+	"until":    "Compound Commands",
+	"while":    "Compound Commands",
+}
+
+// graphKeywords emits refs from the keywords in s that start constructs
+// to the bash man page, recording the section that describes each.
+func graphKeywords(name string, src *source, s *script, output *graphOutput) {
+	page, ok := manPages["bash"]
+	if !ok {
+		return
+	}
+	for i, isCmd := range commandPositions(s.words) {
+		w := s.words[i]
+		section, ok := keywordSections[w.text]
+		if !isCmd || !ok {
+			continue
+		}
+		ref := &graph.Ref{
+			DefRepo:     page.repo,
+			DefUnitType: "ManPages",
+			DefUnit:     "man",
+			DefPath:     page.path + "/" + w.text,
+			UnitType:    "BashDirectory",
+			Unit:        "bash",
+			File:        name,
+			Start:       uint32(src.fileOffset(w.start)),
+			End:         uint32(src.fileEnd(w.end)),
+		}
+		output.Refs = append(output.Refs, ref)
+		output.sections[ref] = section
+	}
+}
+
+// commandFlags returns the options passed to the command named by cmd: its
+// arguments before any "--" that start with a dash, without the values
+// given to long options with "=". The options of a prefix such as exec end
+// at the command it runs.
+func commandFlags(words []word, cmd word) []string {
+	var flags []string
+	for _, a := range commandArgs(words, cmd) {
+		text := unquote(a.text)
+		if text == "--" {
+			break
+		}
+		if len(text) < 2 || text[0] != '-' {
+			if _, ok := commandPrefixes[unquote(cmd.text)]; ok {
+				// The rest are the arguments of the command run.
+				break
+			}
+			continue
+		}
+		if i := strings.IndexByte(text, '='); i >= 0 && strings.HasPrefix(text, "--") {
+			text = text[:i]
+		}
+		flags = append(flags, text)
+	}
+	return flags
+}
+
+//go:generate go run gen_manpages.go
+//go:generate go run gen_manpages.go -in tldrpages.txt -out tldrpages.go -var tldrPages
+//go:generate go run gen_manpages.go -in gnupages.txt -out gnupages.go -var gnuPages
+//go:generate go run gen_mansummaries.go
+
+// A manPage is a man page, or another page of documentation such as a
+// tldr-pages entry, that documents a command.
+type manPage struct {
+	// repo is the repository containing the man page.
+	repo string
+	// path is the path of the man page in repo.
+	path string
+}
+
+// A manSummary is what a man page says a command does, from its NAME and
+// SYNOPSIS sections.
+type manSummary struct {
+	// description is the one-line description of the command, as in
+	// "pattern scanning and processing language".
+	description string
+	// synopsis is the first form of the command's usage, as in "cat
+	// [OPTION]... [FILE]...", or "" if it is too long to show.
+	synopsis string
+}
+
+// RefData is the data of a ref to the man page of a command. srclib refs
+// have no data of their own, so it is output alongside the ref.
+type RefData struct {
+	// Hover is the command and the description in its man page's NAME
+	// section, followed on a line of its own by the first form of its
+	// SYNOPSIS, if any, as in "cat - concatenate files and print on the
+	// standard output\ncat [OPTION]... [FILE]...".
+	Hover string
+}
+
+// commandRefData returns the data of ref, a ref to a command's page in the
+// man doc set, or nil if ref is not such a ref or the page wasn't
+// summarized. The summaries are made from man pages, so refs to the other
+// doc sets and to the sections of pages that describe keywords get none.
+func commandRefData(ref *graph.Ref, section string) *RefData {
+	if ref.DefUnitType != docSets["man"].unitType || section != "" {
+		return nil
+	}
+	command := path.Base(ref.DefPath)
+	s, ok := manSummaries[command]
+	if !ok {
+		return nil
+	}
+	hover := command + " - " + s.description
+	if s.synopsis != "" {
+		hover += "\n" + s.synopsis
+	}
+	return &RefData{Hover: hover}
+}
+
+// A docSet is a collection of pages that commands can be linked to.
+type docSet struct {
+	// unitType and unit identify the source unit of the pages' defs.
+	unitType, unit string
+	// pages maps command names to the pages that document them.
+	pages map[string]manPage
+}
+
+// docSets are the collections of pages selectable with graph --docs.
+var docSets = map[string]docSet{
+	"man":  {unitType: "ManPages", unit: "man", pages: manPages},
+	"tldr": {unitType: "TldrPages", unit: "tldr", pages: tldrPages},
+}
+
+// posixManRepo is the repository of POSIX man pages.
+const posixManRepo = "github.com/sourcegraph/man-pages-posix"
+
+// defCommit returns the revision of the repository of ref's def that ref
+// links to, or "" for the default branch. Refs to POSIX man pages link to
+// the tag of the edition chosen with --posix-edition, such as posix-2017, so
+// that graph output doesn't change as the repository gains later editions.
+func (o *Options) defCommit(ref *graph.Ref) string {
+	if o.PosixEdition == "" || ref.DefRepo != posixManRepo {
+		return ""
+	}
+	return "posix-" + o.PosixEdition
+}
+
+// A docPage is a page that documents a command, in one of the collections
+// of pages that commands are linked to.
+type docPage struct {
+	docs docSet
+	page manPage
+}
+
+// commandPages returns the pages that document command in the collections
+// that opts choose. If run is set, command is the name of a command that
+// is run with the given options, rather than a word elsewhere, and may be
+// linked to an installed man page or a GNU manual as well.
+func commandPages(opts *Options, command string, run bool, flags []string) []docPage {
+	var pages []docPage
+	for _, docs := range opts.docSets() {
+		page, hasPage := docs.pages[command]
+		if docs.unit == "man" && opts.GNUDocs && hasLongOption(flags) {
+			if gnuPage, ok := gnuDocs.pages[command]; ok {
+				docs, page, hasPage = gnuDocs, gnuPage, true
+			}
+		}
+		if !hasPage && run && docs.unit == "man" && opts.LocalMan {
+			page, hasPage = localManPage(command, opts.LocalManRepo)
+		}
+		if hasPage {
+			pages = append(pages, docPage{docs, page})
+		}
+	}
+	return pages
+}
+
+// gnuDocs are the sections of the GNU manuals that document GNU tools,
+// whose long options and other extensions POSIX man pages don't cover.
+var gnuDocs = docSet{unitType: "GNUManuals", unit: "gnu", pages: gnuPages}
+
+// hasLongOption reports whether flags includes a GNU-style long option,
+// such as --verbose.
+func hasLongOption(flags []string) bool {
+	for _, f := range flags {
+		if len(f) > 2 && strings.HasPrefix(f, "--") {
+			return true
+		}
+	}
+	return false
+}
+
+// docSets returns the collections of pages to link commands to, in the
+// order given with --docs. Man pages are used if none are given, as when
+// graphing for commands other than graph.
+func (o *Options) docSets() []docSet {
+	if len(o.Docs) == 0 {
+		return []docSet{docSets["man"]}
+	}
+	var sets []docSet
+	seen := map[string]bool{}
+	for _, name := range o.Docs {
+		if !seen[name] {
+			seen[name] = true
+			sets = append(sets, docSets[name])
+		}
+	}
+	return sets
+}
+
+func makeCommandRef(filename string, command string, docs docSet, page manPage, offset int) (*graph.Ref, error) {
+	return &graph.Ref{
+		DefRepo:     page.repo,
+		DefUnitType: docs.unitType,
+		DefUnit:     docs.unit,
+		DefPath:     page.path + "/" + command,
+		UnitType:    "BashDirectory",
+		Unit:        "bash",
+		Def:         false,
+		File:        filename,
+		Start:       uint32(offset - len(command)),
+		End:         uint32(offset),
+	}, nil
+}
+
+// scriptWords returns the words in words that may name a script being run:
+// command names, and the first argument of shells run on a script. Source
+// statements are linked separately, as includes.
+func scriptWords(words []word) []word {
+	var scripts []word
+	for _, w := range commandWords(words) {
+		scripts = append(scripts, w)
+		switch unquote(w.text) {
+		case "sh", "bash":
+		default:
+			continue
+		}
+		for _, arg := range words {
+			if arg.start < w.end || arg.op {
+				continue
+			}
+			if !strings.HasPrefix(arg.text, "-") {
+				scripts = append(scripts, arg)
+			}
+			break
+		}
+	}
+	return scripts
+}
+
+// makeScriptDef returns the def of the file f itself, which source
+// statements and commands that run it refer to.
+func makeScriptDef(f *parsedFile) (*graph.Def, error) {
+	name := filepath.Base(f.name)
+	entry := fileEntryPoint(f)
+	shebang, _ := shebangLine(f.data)
+	var mainPath string
+	if entry.main != nil {
+		mainPath = f.defPath() + "/" + entry.main.name
+	}
+	data, err := json.Marshal(DefData{
+		Name:           name,
+		Keyword:        "script",
+		Kind:           "script",
+		Role:           entry.role,
+		Main:           mainPath,
+		Interpreter:    shebangInterpreter(shebang),
+		Options:        shellOptions(f),
+		MinBashVersion: minBashVersion(f),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &graph.Def{
+		DefKey: graph.DefKey{
+			UnitType: "BashDirectory",
+			Unit:     "bash",
+			Path:     f.defPath(),
+		},
+		TreePath: treePath(f.defPath()),
+		Name:     name,
+		Kind:     "script",
+		File:     f.name,
+		Data:     data,
+	}, nil
+}
+
+// scriptDefPath returns the DefPath of the script at path, which is also
+// the prefix of the DefPaths of the symbols it defines. It is the path of the
+// script relative to the repository root (the current directory) with
+// forward slashes, so DefPaths don't depend on where the repository is
+// checked out, even if the source units list absolute paths. To make the
+// DefPaths of the files of a unit, use the repoRoot of the unit, which looks
+// up the current directory only once.
+func scriptDefPath(path string) string {
+	return new(repoRoot).defPath(path)
+}
+
+// A repoRoot is the root of the repository that DefPaths are relative to:
+// the current directory, looked up the first time an absolute path needs
+// it. parseUnit makes one for each unit it parses.
+type repoRoot struct {
+	once sync.Once
+	// cwd is the current directory, and resolved is cwd with symlinks
+	// resolved, or "" if they can't be.
+	cwd, resolved string
+}
+
+// defPath returns the DefPath of the script at path, as scriptDefPath does.
+// A nil repoRoot looks up the current directory anew.
+func (r *repoRoot) defPath(path string) string {
+	if r == nil {
+		r = new(repoRoot)
+	}
+	if filepath.IsAbs(path) {
+		r.once.Do(func() {
+			r.cwd, _ = os.Getwd()
+			r.resolved, _ = filepath.EvalSymlinks(r.cwd)
+		})
+		if rel, err := filepath.Rel(r.cwd, path); err == nil && !strings.HasPrefix(rel, "..") {
+			path = rel
+		} else if r.resolved != "" {
+			if rel, err := filepath.Rel(r.resolved, path); err == nil && !strings.HasPrefix(rel, "..") {
+				path = rel
+			}
+		}
+	}
+	return filepath.ToSlash(filepath.Clean(path))
+}
+
+// treePath returns the TreePath of the def with the given DefPath. DefPaths
+// already nest functions and variables under the scripts that define them,
+// so the tree mirrors the directory structure of the repository.
+func treePath(defPath string) string {
+	return "./" + defPath
+}
+
+// makeIncludeRef returns a ref from the source statement inc in f to the
+// file it includes.
+func makeIncludeRef(f *parsedFile, inc *include) *graph.Ref {
+	return &graph.Ref{
+		DefUnitType: "BashDirectory",
+		DefUnit:     "bash",
+		DefPath:     f.root.defPath(inc.path),
+		UnitType:    "BashDirectory",
+		Unit:        "bash",
+		File:        f.name,
+		Start:       uint32(inc.src.fileOffset(inc.word.start)),
+		End:         uint32(inc.src.fileEnd(inc.word.end)),
+	}
+}
+
+// resolveScriptPath returns the path of the file in the repository that
+// path, used in the named file, refers to. Relative paths are resolved
+// against the directory of the file, then against the repository root.
+// Absolute paths are matched by their longest suffix that names a file in
+// the repository, since scripts are usually installed to a location that
+// mirrors their place in the repository.
+func resolveScriptPath(filename, path string) (string, bool) {
+	if !strings.Contains(path, "/") || strings.ContainsAny(path, "$`*?[") {
+		return "", false
+	}
+	var candidates []string
+	if filepath.IsAbs(path) {
+		parts := strings.Split(strings.TrimPrefix(filepath.ToSlash(path), "/"), "/")
+		for i := 0; i < len(parts)-1; i++ {
+			candidates = append(candidates, filepath.Join(parts[i:]...))
+		}
+	} else {
+		candidates = []string{filepath.Join(filepath.Dir(filename), path), path}
+	}
+	for _, c := range candidates {
+		c = filepath.Clean(c)
+		if strings.HasPrefix(c, "..") {
+			continue
+		}
+		if info, err := os.Stat(c); err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+		return c, true
+	}
+	return "", false
+}
+
+type DefData struct {
+	Name      string
+	Keyword   string
+	Type      string
+	Kind      string
+	Separator string
+	// Signature is the header of a function as written, as in "deploy()",
+	// or the declaration of a variable without its value, as in
+	// "declare -A ports".
+	Signature string `json:",omitempty"`
+	// DocHTML is the comment above a function or variable as an HTML
+	// snippet.
+	DocHTML string `json:",omitempty"`
+	// Complexity is the cyclomatic complexity of a function.
+	Complexity int `json:",omitempty"`
+	// BodyStart and BodyEnd are the byte offsets of the body of a function,
+	// from its opening brace (or other compound command) to its end.
+	BodyStart uint32 `json:",omitempty"`
+	BodyEnd   uint32 `json:",omitempty"`
+	// Completes lists the commands that a function is the completion
+	// function of, registered with complete -F.
+	Completes []string `json:",omitempty"`
+	// Attributes lists properties of a def that are not implied by its
+	// kind, such as "exported-function" for functions exported to child
+	// processes with export -f.
+	Attributes []string `json:",omitempty"`
+	// Role is "library" for a script meant to be sourced and "executable"
+	// for one meant to be run, and Main is the DefPath of the function that
+	// runs an executable script, as in main "$@", if any.
+	Role string `json:",omitempty"`
+	Main string `json:",omitempty"`
+	// Nameref is what a nameref variable refers to, as written: a
+	// variable name, or an expansion such as $1 for a variable passed by
+	// reference.
+	Nameref string `json:",omitempty"`
+	// Interpreter is the interpreter named on a script's #! line, and
+	// Options are the shell options it enables, as in errexit for set -e.
+	Interpreter string   `json:",omitempty"`
+	Options     []string `json:",omitempty"`
+	// MinBashVersion is the oldest Bash version that has all the features a
+	// script uses, such as 4.0 for associative arrays, if any of them are
+	// not in every version in use.
+	MinBashVersion string `json:",omitempty"`
+}
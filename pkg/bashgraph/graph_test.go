@@ -0,0 +1,257 @@
+package bashgraph
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"sourcegraph.com/sourcegraph/srclib/graph"
+	"sourcegraph.com/sourcegraph/srclib/unit"
+)
+
+var update = flag.Bool("update", false, "update the golden files in testdata")
+
+// TestGraph graphs each script in testdata/graph as a source unit of its
+// own, and compares the output with the script's golden file, NAME.golden.
+// Run go test -update to write the golden files from the current output.
+func TestGraph(t *testing.T) {
+	dir := filepath.Join("testdata", "graph")
+	scripts, err := filepath.Glob(filepath.Join(dir, "*.sh"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(scripts) == 0 {
+		t.Fatalf("no scripts in %s", dir)
+	}
+
+	// Files are named relative to the unit's directory, as srclib does.
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	for _, script := range scripts {
+		name := filepath.Base(script)
+		got, err := graphJSON(name)
+		if err != nil {
+			t.Errorf("%s: %s", name, err)
+			continue
+		}
+
+		golden := strings.TrimSuffix(name, ".sh") + ".golden"
+		if *update {
+			if err := ioutil.WriteFile(golden, got, 0644); err != nil {
+				t.Fatal(err)
+			}
+			continue
+		}
+		want, err := ioutil.ReadFile(golden)
+		if err != nil {
+			t.Errorf("%s: %s (run go test -update to create it)", name, err)
+			continue
+		}
+		if !bytes.Equal(got, want) {
+			t.Errorf("%s: graph output differs from %s\n%s", name, golden, diffLines(string(want), string(got)))
+		}
+	}
+}
+
+// TestSchema checks that the graph output of each script in testdata/graph
+// conforms to the srclib unit and graph types.
+func TestSchema(t *testing.T) {
+	dir := filepath.Join("testdata", "graph")
+	scripts, err := filepath.Glob(filepath.Join(dir, "*.sh"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	for _, script := range scripts {
+		name := filepath.Base(script)
+		data, err := graphJSON(name)
+		if err != nil {
+			t.Errorf("%s: %s", name, err)
+			continue
+		}
+		for _, v := range checkSchema(scriptUnits(name), data) {
+			t.Errorf("%s: %s:%d-%d: %s", name, v.File, v.Start, v.End, v.Message)
+		}
+	}
+}
+
+// TestValidate checks that the graph output of each script in
+// testdata/graph has no violations, such as the duplicate defs of functions
+// defined twice, as in redefine.sh. The files the scripts source and link
+// to are validated as if they were in the script's unit, as they would be
+// in a repository.
+func TestValidate(t *testing.T) {
+	dir := filepath.Join("testdata", "graph")
+	scripts, err := filepath.Glob(filepath.Join(dir, "*.sh"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	var files []string
+	err = filepath.Walk(".", func(path string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() {
+			files = append(files, path)
+		}
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	units := scriptUnits(".")
+	units[0].Files = files
+
+	for _, script := range scripts {
+		name := filepath.Base(script)
+		data, err := graphJSON(name)
+		if err != nil {
+			t.Errorf("%s: %s", name, err)
+			continue
+		}
+		var out graph.Output
+		if err := json.Unmarshal(data, &out); err != nil {
+			t.Errorf("%s: %s", name, err)
+			continue
+		}
+		for _, v := range validateGraph(units, &out) {
+			t.Errorf("%s: %s: %s:%d-%d: %s", name, v.Check, v.File, v.Start, v.End, v.Message)
+		}
+	}
+}
+
+// scriptUnits returns the source units of a source unit made of the named
+// file.
+func scriptUnits(name string) unit.SourceUnits {
+	return unit.SourceUnits{{Key: unit.Key{Name: "bash", Type: "BashDirectory"}, Info: unit.Info{Files: []string{name}, Dir: "."}}}
+}
+
+// graphJSON returns the indented graph output of a source unit made of the
+// named file.
+func graphJSON(name string) ([]byte, error) {
+	opts := testOptions()
+	out, err := graphUnits(scriptUnits(name), opts)
+	if err != nil {
+		return nil, err
+	}
+	pout, err := withPositions(out, opts)
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.MarshalIndent(pout, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// diffLines describes the first line where got differs from want, with
+// the lines before it and a few lines of each after it.
+func diffLines(want, got string) string {
+	wantLines := strings.Split(want, "\n")
+	gotLines := strings.Split(got, "\n")
+	i := 0
+	for i < len(wantLines) && i < len(gotLines) && wantLines[i] == gotLines[i] {
+		i++
+	}
+	var b bytes.Buffer
+	for j := i - 3; j < i; j++ {
+		if j >= 0 {
+			b.WriteString("  " + wantLines[j] + "\n")
+		}
+	}
+	for j := i; j < i+3 && j < len(wantLines); j++ {
+		b.WriteString("- " + wantLines[j] + "\n")
+	}
+	for j := i; j < i+3 && j < len(gotLines); j++ {
+		b.WriteString("+ " + gotLines[j] + "\n")
+	}
+	return b.String()
+}
+
+// FuzzGraphFile graphs arbitrary scripts, checking that graphing doesn't
+// panic and that every def and ref spans a range of the script.
+func FuzzGraphFile(f *testing.F) {
+	scripts, err := filepath.Glob(filepath.Join("testdata", "graph", "*.sh"))
+	if err != nil {
+		f.Fatal(err)
+	}
+	for _, script := range scripts {
+		data, err := ioutil.ReadFile(script)
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(data)
+	}
+	f.Add([]byte("\xef\xbb\xbfecho \"$(ls `pwd`)\" <(sort) <<< x"))
+	f.Add([]byte("case $x in a) eval 'f() { :; }';; esac"))
+	f.Add([]byte("ssh h 'docker exec c sh -c \"ls -l\"'"))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		pf, err := parseData("fuzz.sh", data)
+		if err != nil {
+			return
+		}
+		output := newGraphOutput()
+		idx := newUnitIndex([]*parsedFile{pf})
+		idx.opts = &Options{Keywords: true, RemoteCommands: true}
+		if err := graphFile(pf, idx, output); err != nil {
+			return
+		}
+		size := uint32(len(data))
+		for _, def := range output.Defs {
+			if def.DefStart > def.DefEnd || def.DefEnd > size {
+				t.Errorf("def %s spans %d-%d in %d bytes", def.Path, def.DefStart, def.DefEnd, size)
+			}
+		}
+		for _, ref := range output.Refs {
+			if ref.Start > ref.End || ref.End > size {
+				t.Errorf("ref to %s spans %d-%d in %d bytes", ref.DefPath, ref.Start, ref.End, size)
+			}
+		}
+	})
+}
+
+// TestSelftest checks that the expected output of the selftest command is
+// up to date.
+func TestSelftest(t *testing.T) {
+	got, err := selftestLines()
+	if err != nil {
+		t.Fatal(err)
+	}
+	missing, unexpected := diffLineSets(selftestExpected, got)
+	for _, l := range missing {
+		t.Errorf("missing: %s", l)
+	}
+	for _, l := range unexpected {
+		t.Errorf("unexpected: %s", l)
+	}
+}
@@ -0,0 +1,209 @@
+package bashgraph
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+
+	"sourcegraph.com/sourcegraph/srclib/graph"
+)
+
+type DiffCmd struct {
+	JSON     bool `long:"json" description:"output the differences as JSON"`
+	ExitCode bool `long:"exit-code" description:"exit with status 1 if there are differences"`
+	Args     struct {
+		Old string `positional-arg-name:"OLD" required:"yes"`
+		New string `positional-arg-name:"NEW" required:"yes"`
+	} `positional-args:"yes"`
+}
+
+// A GraphDiff is the difference between two graph outputs.
+type GraphDiff struct {
+	AddedDefs   []*graph.Def `json:",omitempty"`
+	RemovedDefs []*graph.Def `json:",omitempty"`
+	// MovedDefs are the defs whose span changed.
+	MovedDefs   []*MovedDef  `json:",omitempty"`
+	AddedRefs   []*graph.Ref `json:",omitempty"`
+	RemovedRefs []*graph.Ref `json:",omitempty"`
+	// MovedRefs are the refs whose span in the same file changed.
+	MovedRefs []*MovedRef `json:",omitempty"`
+}
+
+// A MovedDef is a def at a different span in the new graph output.
+type MovedDef struct {
+	Old *graph.Def
+	New *graph.Def
+}
+
+// A MovedRef is a ref at a different span in the new graph output.
+type MovedRef struct {
+	Old *graph.Ref
+	New *graph.Ref
+}
+
+func (c *DiffCmd) Execute(args []string) error {
+	old, err := readGraphOutput(c.Args.Old)
+	if err != nil {
+		return err
+	}
+	new, err := readGraphOutput(c.Args.New)
+	if err != nil {
+		return err
+	}
+
+	d := diffGraphs(old, new)
+	if c.JSON {
+		if err := json.NewEncoder(os.Stdout).Encode(d); err != nil {
+			return fmt.Errorf("Failed to output graph differences: %s", err)
+		}
+	} else {
+		d.print()
+	}
+	if c.ExitCode && !d.empty() {
+		os.Exit(1)
+	}
+	return nil
+}
+
+// readGraphOutput reads the graph output in the named file.
+func readGraphOutput(name string) (*graph.Output, error) {
+	data, err := ioutil.ReadFile(name)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read graph output: %s", err)
+	}
+	var out graph.Output
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, fmt.Errorf("Failed to parse graph output %s: %s", name, err)
+	}
+	return &out, nil
+}
+
+// diffGraphs returns the differences between the old and new graph
+// outputs. Defs are matched by their keys. Refs are matched by their
+// targets and files, and a ref is moved if the numbers of refs from a file
+// to a def are the same but their spans differ.
+func diffGraphs(old, new *graph.Output) *GraphDiff {
+	d := &GraphDiff{}
+
+	oldDefs := map[graph.DefKey]*graph.Def{}
+	for _, def := range old.Defs {
+		oldDefs[def.DefKey] = def
+	}
+	newDefs := map[graph.DefKey]bool{}
+	for _, def := range sortedDefs(new.Defs) {
+		newDefs[def.DefKey] = true
+		o, ok := oldDefs[def.DefKey]
+		switch {
+		case !ok:
+			d.AddedDefs = append(d.AddedDefs, def)
+		case o.File != def.File || o.DefStart != def.DefStart || o.DefEnd != def.DefEnd:
+			d.MovedDefs = append(d.MovedDefs, &MovedDef{Old: o, New: def})
+		}
+	}
+	for _, def := range sortedDefs(old.Defs) {
+		if !newDefs[def.DefKey] {
+			d.RemovedDefs = append(d.RemovedDefs, def)
+		}
+	}
+
+	type refKey struct {
+		graph.RefDefKey
+		file string
+	}
+	key := func(ref *graph.Ref) refKey {
+		return refKey{
+			RefDefKey: graph.RefDefKey{DefRepo: ref.DefRepo, DefUnitType: ref.DefUnitType, DefUnit: ref.DefUnit, DefPath: ref.DefPath},
+			file:      ref.File,
+		}
+	}
+	type span struct{ start, end uint32 }
+	unmatched := map[refKey]map[span][]*graph.Ref{}
+	for _, ref := range old.Refs {
+		k := key(ref)
+		if unmatched[k] == nil {
+			unmatched[k] = map[span][]*graph.Ref{}
+		}
+		s := span{ref.Start, ref.End}
+		unmatched[k][s] = append(unmatched[k][s], ref)
+	}
+	var added []*graph.Ref
+	for _, ref := range new.Refs {
+		s := span{ref.Start, ref.End}
+		if refs := unmatched[key(ref)][s]; len(refs) > 0 {
+			unmatched[key(ref)][s] = refs[1:]
+			continue
+		}
+		added = append(added, ref)
+	}
+
+	// Pair the unmatched refs of each target and file in order.
+	removed := map[refKey][]*graph.Ref{}
+	for k, spans := range unmatched {
+		for _, refs := range spans {
+			removed[k] = append(removed[k], refs...)
+		}
+		sort.Sort(refsByStart(removed[k]))
+	}
+	sort.Sort(refsByStart(added))
+	for _, ref := range added {
+		k := key(ref)
+		if len(removed[k]) > 0 {
+			d.MovedRefs = append(d.MovedRefs, &MovedRef{Old: removed[k][0], New: ref})
+			removed[k] = removed[k][1:]
+			continue
+		}
+		d.AddedRefs = append(d.AddedRefs, ref)
+	}
+	for _, refs := range removed {
+		d.RemovedRefs = append(d.RemovedRefs, refs...)
+	}
+	sort.Sort(refsByStart(d.RemovedRefs))
+	return d
+}
+
+// sortedDefs returns a copy of defs sorted by file and start.
+func sortedDefs(defs []*graph.Def) []*graph.Def {
+	sorted := append([]*graph.Def(nil), defs...)
+	sort.Sort(defsByStart(sorted))
+	return sorted
+}
+
+// empty reports whether there are no differences.
+func (d *GraphDiff) empty() bool {
+	return len(d.AddedDefs) == 0 && len(d.RemovedDefs) == 0 && len(d.MovedDefs) == 0 &&
+		len(d.AddedRefs) == 0 && len(d.RemovedRefs) == 0 && len(d.MovedRefs) == 0
+}
+
+// print prints the differences one per line, prefixed with + for additions,
+// - for removals and ~ for moves.
+func (d *GraphDiff) print() {
+	for _, def := range d.RemovedDefs {
+		fmt.Printf("- def %s %s:%d-%d\n", def.Path, def.File, def.DefStart, def.DefEnd)
+	}
+	for _, def := range d.AddedDefs {
+		fmt.Printf("+ def %s %s:%d-%d\n", def.Path, def.File, def.DefStart, def.DefEnd)
+	}
+	for _, m := range d.MovedDefs {
+		fmt.Printf("~ def %s %s:%d-%d -> %s:%d-%d\n", m.New.Path, m.Old.File, m.Old.DefStart, m.Old.DefEnd, m.New.File, m.New.DefStart, m.New.DefEnd)
+	}
+	for _, ref := range d.RemovedRefs {
+		fmt.Printf("- ref %s:%d-%d -> %s\n", ref.File, ref.Start, ref.End, refTarget(ref))
+	}
+	for _, ref := range d.AddedRefs {
+		fmt.Printf("+ ref %s:%d-%d -> %s\n", ref.File, ref.Start, ref.End, refTarget(ref))
+	}
+	for _, m := range d.MovedRefs {
+		fmt.Printf("~ ref %s:%d-%d -> %d-%d -> %s\n", m.New.File, m.Old.Start, m.Old.End, m.New.Start, m.New.End, refTarget(m.New))
+	}
+}
+
+// refTarget describes the def that ref links to: its path, prefixed with
+// its repository if it is in another one.
+func refTarget(ref *graph.Ref) string {
+	if ref.DefRepo == "" {
+		return ref.DefPath
+	}
+	return ref.DefRepo + ":" + ref.DefPath
+}
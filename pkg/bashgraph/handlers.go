@@ -0,0 +1,91 @@
+package bashgraph
+
+import "strings"
+
+// hookVars are the variables whose values are commands that the shell runs
+// when something happens, such as before each prompt.
+var hookVars = map[string]bool{
+	"PROMPT_COMMAND": true,
+}
+
+// hookFuncVars are the zsh variables whose values are arrays of the names
+// of functions that zsh runs when something happens.
+var hookFuncVars = map[string]bool{
+	"chpwd_functions":              true,
+	"periodic_functions":           true,
+	"precmd_functions":             true,
+	"preexec_functions":            true,
+	"zsh_directory_name_functions": true,
+	"zshaddhistory_functions":      true,
+	"zshexit_functions":            true,
+}
+
+// hookWords returns the commands in s that the shell runs when hooks fire,
+// as lists of words with offsets in s: the handlers of trap, as in
+// trap '_on_err' ERR, the values of hook variables, as in
+// PROMPT_COMMAND="history -a; update_prompt", and the functions in zsh's
+// hook arrays, as in precmd_functions+=(update_prompt) or
+// add-zsh-hook precmd update_prompt.
+func hookWords(s *script) [][]word {
+	var hooks [][]word
+	// commandString adds the commands in w, a string literal or a single
+	// word.
+	commandString := func(w word) {
+		if inner, ok := quotedWords(w); ok {
+			hooks = append(hooks, inner)
+		} else if !strings.HasPrefix(w.text, "-") {
+			hooks = append(hooks, []word{w})
+		}
+	}
+
+	for _, cmd := range s.commands {
+		args := commandArgs(s.words, cmd)
+		switch unquote(cmd.text) {
+		case "trap":
+			if len(args) > 0 && args[0].text == "--" {
+				args = args[1:]
+			}
+			if len(args) > 1 {
+				commandString(args[0])
+			}
+		case "add-zsh-hook":
+			var names []word
+			for _, a := range args {
+				if !strings.HasPrefix(a.text, "-") {
+					names = append(names, a)
+				}
+			}
+			if len(names) == 2 {
+				hooks = append(hooks, names[1:])
+			}
+		}
+	}
+
+	for _, w := range s.words {
+		name := assignmentName(w.text)
+		if w.op || !hookVars[name] && !hookFuncVars[name] {
+			continue
+		}
+		eq := strings.IndexByte(w.text, '=')
+		value := word{text: w.text[eq+1:], start: w.start + eq + 1, end: w.end}
+		elems := []word{value}
+		if strings.HasPrefix(value.text, "(") && strings.HasSuffix(value.text, ")") {
+			// An array, whose elements are commands or function names.
+			elems = splitWords(value.text[1 : len(value.text)-1])
+			for i := range elems {
+				elems[i].start += value.start + 1
+				elems[i].end += value.start + 1
+			}
+		}
+		for _, e := range elems {
+			switch {
+			case e.op:
+			case hookFuncVars[name]:
+				hooks = append(hooks, []word{e})
+			default:
+				commandString(e)
+			}
+		}
+	}
+	return hooks
+}
@@ -0,0 +1,48 @@
+package bashgraph
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// gitHookNames are the names of the hooks that git runs.
+var gitHookNames = map[string]bool{
+	"applypatch-msg":        true,
+	"pre-applypatch":        true,
+	"post-applypatch":       true,
+	"pre-commit":            true,
+	"pre-merge-commit":      true,
+	"prepare-commit-msg":    true,
+	"commit-msg":            true,
+	"post-commit":           true,
+	"pre-rebase":            true,
+	"post-checkout":         true,
+	"post-merge":            true,
+	"pre-push":              true,
+	"pre-receive":           true,
+	"update":                true,
+	"proc-receive":          true,
+	"post-receive":          true,
+	"post-update":           true,
+	"reference-transaction": true,
+	"push-to-checkout":      true,
+	"pre-auto-gc":           true,
+	"post-rewrite":          true,
+	"sendemail-validate":    true,
+	"fsmonitor-watchman":    true,
+}
+
+// isGitHook reports whether path looks like a git hook: a file in a hooks
+// or .githooks directory, or a file named after a hook.
+func isGitHook(path string) bool {
+	name := filepath.Base(path)
+	if gitHookNames[strings.TrimSuffix(name, filepath.Ext(name))] {
+		return true
+	}
+	for _, dir := range strings.Split(filepath.ToSlash(filepath.Dir(path)), "/") {
+		if dir == "hooks" || dir == ".githooks" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,290 @@
+package bashgraph
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"sourcegraph.com/sourcegraph/srclib/unit"
+)
+
+type ImagesCmd struct {
+	JSON bool `long:"json" description:"output the list as JSON"`
+}
+
+// imageUnitType is the unit type of the raw dependencies that scan records
+// for the container images a unit uses.
+const imageUnitType = "ContainerImage"
+
+// An ImageScript is a file that uses container images.
+type ImageScript struct {
+	File   string
+	Images []*ImageUse
+}
+
+// An ImageUse is a literal reference to a container image.
+type ImageUse struct {
+	// Image is the image as written, such as alpine:3.18.
+	Image string
+	// Command is how the image is used, such as "docker run" or "FROM".
+	Command string
+	// Start and End are the byte offsets of the image in the file.
+	Start uint32
+	End   uint32
+	Line  int
+}
+
+// imageCommands are the container engines whose run, create and pull
+// subcommands take an image.
+var imageCommands = map[string]bool{
+	"docker": true, "podman": true, "nerdctl": true,
+}
+
+// imageSubcommands are the subcommands of imageCommands that take an image,
+// with those of the older docker container and docker image forms.
+var imageSubcommands = map[string]bool{
+	"run": true, "create": true, "pull": true,
+}
+
+// imageOptsWithArg are the options of the imageSubcommands that take an
+// argument, unless it is given after =. Options not listed don't.
+var imageOptsWithArg = map[string]bool{
+	"-a": true, "-e": true, "-h": true, "-l": true, "-m": true, "-p": true, "-u": true, "-v": true, "-w": true,
+	"--add-host": true, "--attach": true, "--cap-add": true, "--cap-drop": true, "--cgroup-parent": true,
+	"--cidfile": true, "--cpus": true, "--cpu-shares": true, "--device": true, "--dns": true,
+	"--entrypoint": true, "--env": true, "--env-file": true, "--expose": true, "--gpus": true,
+	"--group-add": true, "--health-cmd": true, "--hostname": true, "--ipc": true, "--label": true,
+	"--label-file": true, "--link": true, "--log-driver": true, "--log-opt": true, "--memory": true,
+	"--mount": true, "--name": true, "--network": true, "--net": true, "--pid": true, "--platform": true,
+	"--publish": true, "--pull": true, "--restart": true, "--runtime": true, "--security-opt": true,
+	"--shm-size": true, "--stop-signal": true, "--tmpfs": true, "--ulimit": true, "--user": true,
+	"--userns": true, "--volume": true, "--volumes-from": true, "--workdir": true,
+}
+
+// imageName matches the references to images: an optional registry host,
+// the repository path, and an optional tag and digest.
+var imageName = regexp.MustCompile(`^([a-z0-9.-]+(:[0-9]+)?/)?[a-z0-9]+([._-][a-z0-9]+)*(/[a-z0-9]+([._-][a-z0-9]+)*)*(:[A-Za-z0-9_][A-Za-z0-9_.-]*)?(@sha256:[a-f0-9]{64})?$`)
+
+// fromLine matches the FROM instructions of Dockerfiles, with the name of
+// the build stage they start, if any.
+var fromLine = regexp.MustCompile(`(?m)^[ \t]*FROM[ \t]+(?:--platform=\S+[ \t]+)?(\S+)(?:[ \t]+(?i:AS)[ \t]+(\S+))?`)
+
+func (c *ImagesCmd) Execute(args []string) error {
+	units, err := readSourceUnits()
+	if err != nil {
+		return err
+	}
+
+	scripts := imageScripts(units, commandOptions())
+	if c.JSON {
+		if err := json.NewEncoder(os.Stdout).Encode(scripts); err != nil {
+			return fmt.Errorf("Failed to output container images: %s", err)
+		}
+		return nil
+	}
+
+	for _, s := range scripts {
+		for _, img := range s.Images {
+			fmt.Printf("%s:%d: %s (%s)\n", s.File, img.Line, img.Image, img.Command)
+		}
+	}
+	return nil
+}
+
+// imageScripts returns the files of units that use container images, sorted
+// by name.
+func imageScripts(units unit.SourceUnits, opts *Options) []*ImageScript {
+	var scripts []*ImageScript
+	for _, u := range units {
+		for _, f := range parseUnit(u, opts) {
+			if uses := imageUses(f); len(uses) > 0 {
+				scripts = append(scripts, &ImageScript{File: f.name, Images: uses})
+			}
+		}
+	}
+	sort.Sort(imageScriptsByFile(scripts))
+	return scripts
+}
+
+type imageScriptsByFile []*ImageScript
+
+func (is imageScriptsByFile) Len() int           { return len(is) }
+func (is imageScriptsByFile) Swap(i, j int)      { is[i], is[j] = is[j], is[i] }
+func (is imageScriptsByFile) Less(i, j int) bool { return is[i].File < is[j].File }
+
+// imageUses returns the literal references to container images in f, in
+// the order they appear: the images that commands run or pull, and those
+// that the Dockerfiles that f writes with here-documents start FROM. Images
+// named by expansions, as in docker run "$IMAGE", and the build stages and
+// scratch image of Dockerfiles are left out.
+func imageUses(f *parsedFile) []*ImageUse {
+	var uses []*ImageUse
+	for i, s := range f.scripts {
+		src := f.sources[i]
+		for _, cmd := range s.commands {
+			sub, w, ok := commandImage(s.words, cmd)
+			if !ok {
+				continue
+			}
+			uses = append(uses, &ImageUse{
+				Image:   unquote(w.text),
+				Command: unquote(cmd.text) + " " + sub,
+				Start:   uint32(src.fileOffset(w.start)),
+				End:     uint32(src.fileEnd(w.end)),
+			})
+		}
+	}
+
+	for _, src := range f.sources {
+		_, _, bodies := lexWords(src.text)
+		for _, b := range bodies {
+			if !isDockerfile(b.text) {
+				continue
+			}
+			stages := map[string]bool{"scratch": true}
+			for _, m := range fromLine.FindAllStringSubmatchIndex(b.text, -1) {
+				image := b.text[m[2]:m[3]]
+				if !stages[strings.ToLower(image)] && imageName.MatchString(image) {
+					uses = append(uses, &ImageUse{
+						Image:   image,
+						Command: "FROM",
+						Start:   uint32(src.fileOffset(b.start + m[2])),
+						End:     uint32(src.fileEnd(b.start + m[3])),
+					})
+				}
+				if m[4] >= 0 {
+					stages[strings.ToLower(b.text[m[4]:m[5]])] = true
+				}
+			}
+		}
+	}
+
+	li := newLineIndex(f.data)
+	for _, u := range uses {
+		u.Line, _ = li.position(int(u.Start))
+	}
+	sort.Sort(imageUsesByStart(uses))
+	return uses
+}
+
+// isDockerfile reports whether the here-document body text is a
+// Dockerfile: its first instruction is FROM, or ARG, which may precede it.
+func isDockerfile(text string) bool {
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		return strings.HasPrefix(line, "FROM ") || strings.HasPrefix(line, "ARG ")
+	}
+	return false
+}
+
+type imageUsesByStart []*ImageUse
+
+func (us imageUsesByStart) Len() int           { return len(us) }
+func (us imageUsesByStart) Swap(i, j int)      { us[i], us[j] = us[j], us[i] }
+func (us imageUsesByStart) Less(i, j int) bool { return us[i].Start < us[j].Start }
+
+// commandImage returns the subcommand and the image argument of the command
+// named by cmd, if it runs, creates or pulls a literal image, as in docker
+// run --rm -v "$PWD":/src golang:1.21 or podman container create alpine.
+func commandImage(words []word, cmd word) (string, word, bool) {
+	if !imageCommands[unquote(cmd.text)] {
+		return "", word{}, false
+	}
+	args := commandArgs(words, cmd)
+	if len(args) > 1 {
+		if a := unquote(args[0].text); a == "container" || a == "image" {
+			args = args[1:]
+		}
+	}
+	if len(args) == 0 || !imageSubcommands[unquote(args[0].text)] {
+		return "", word{}, false
+	}
+	sub := unquote(args[0].text)
+	for i := 1; i < len(args); i++ {
+		a := unquote(args[i].text)
+		if strings.HasPrefix(a, "-") {
+			if imageOptsWithArg[a] {
+				i++
+			}
+			continue
+		}
+		if strings.ContainsAny(args[i].text, "$`") || !imageName.MatchString(a) {
+			return "", word{}, false
+		}
+		return sub, args[i], true
+	}
+	return "", word{}, false
+}
+
+// imageDeps returns the container images that the named files, relative to
+// dir, use, as raw dependencies of their unit, sorted and without
+// duplicates. Only files that mention an image command or FROM are parsed.
+func imageDeps(dir string, files []string) []*unit.Key {
+	seen := map[string]bool{}
+	var deps []*unit.Key
+	for _, name := range files {
+		data, err := ioutil.ReadFile(filepath.Join(dir, name))
+		if err != nil || !mentionsImages(data) {
+			continue
+		}
+		f, err := parseData(name, data)
+		if err != nil {
+			continue
+		}
+		for _, u := range imageUses(f) {
+			if seen[u.Image] {
+				continue
+			}
+			seen[u.Image] = true
+			deps = append(deps, imageKey(u.Image))
+		}
+	}
+	sort.Sort(imageKeysByName(deps))
+	return deps
+}
+
+// mentionsImages reports whether data may use container images.
+func mentionsImages(data []byte) bool {
+	for _, s := range []string{"docker", "podman", "nerdctl", "FROM"} {
+		if bytes.Contains(data, []byte(s)) {
+			return true
+		}
+	}
+	return false
+}
+
+// imageKey returns the raw dependency on image, whose name is the image
+// without its tag or digest, which are its version.
+func imageKey(image string) *unit.Key {
+	name, version := image, ""
+	if i := strings.IndexByte(name, '@'); i >= 0 {
+		name, version = name[:i], name[i+1:]
+	}
+	if i := strings.LastIndexByte(name, ':'); i > strings.LastIndexByte(name, '/') {
+		if version == "" {
+			version = name[i+1:]
+		}
+		name = name[:i]
+	}
+	return &unit.Key{Repo: unit.UnitRepoUnresolved, Type: imageUnitType, Name: name, Version: version}
+}
+
+type imageKeysByName []*unit.Key
+
+func (ks imageKeysByName) Len() int      { return len(ks) }
+func (ks imageKeysByName) Swap(i, j int) { ks[i], ks[j] = ks[j], ks[i] }
+func (ks imageKeysByName) Less(i, j int) bool {
+	if ks[i].Name != ks[j].Name {
+		return ks[i].Name < ks[j].Name
+	}
+	return ks[i].Version < ks[j].Version
+}
@@ -0,0 +1,155 @@
+package bashgraph
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"sourcegraph.com/sourcegraph/srclib/unit"
+)
+
+type ImpactCmd struct {
+	Diff string `long:"diff" description:"git revision range (such as master..HEAD) whose changed files to use in addition to the arguments" value-name:"RANGE"`
+}
+
+// Impact is the set of files and functions affected by a change.
+type Impact struct {
+	Files     []string
+	Functions []*ImpactedFunction
+}
+
+// An ImpactedFunction is a function affected by a change.
+type ImpactedFunction struct {
+	File    string
+	Name    string
+	DefPath string
+}
+
+func (c *ImpactCmd) Execute(args []string) error {
+	changed := args
+	if c.Diff != "" {
+		files, err := gitChangedFiles(c.Diff)
+		if err != nil {
+			return fmt.Errorf("Failed to list files changed in %s: %s", c.Diff, err)
+		}
+		changed = append(changed, files...)
+	}
+	if len(changed) == 0 {
+		return fmt.Errorf("no changed files given")
+	}
+
+	units, err := readSourceUnits()
+	if err != nil {
+		return err
+	}
+
+	bytes, err := json.MarshalIndent(impact(units, changed, commandOptions()), "", "  ")
+	if err != nil {
+		return fmt.Errorf("Failed to marshal impact: %s", err)
+	}
+	if _, err := os.Stdout.Write(bytes); err != nil {
+		return fmt.Errorf("Failed to output impact: %s", err)
+	}
+	return nil
+}
+
+// gitChangedFiles returns the files in the current directory, or below it,
+// that changed in the git revision range rev. They are named relative to
+// the current directory, as the source units' files are, and may contain
+// spaces.
+func gitChangedFiles(rev string) ([]string, error) {
+	out, err := exec.Command("git", "diff", "-z", "--name-only", "--relative", rev).Output()
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, name := range strings.Split(string(out), "\x00") {
+		if name != "" {
+			files = append(files, name)
+		}
+	}
+	return files, nil
+}
+
+// impact computes the files and functions in units that are affected by
+// changes to the named files. A function is affected if it is defined in a
+// changed file or calls an affected function. A file is affected if it
+// changed, sources an affected file, calls an affected function from its
+// top level, or defines an affected function.
+func impact(units unit.SourceUnits, changed []string, opts *Options) *Impact {
+	files := map[string]bool{}
+	for _, name := range changed {
+		files[filepath.Clean(name)] = true
+	}
+	funcs := map[*function]*funcDef{}
+
+	for _, u := range units {
+		parsed := parseUnit(u, opts)
+		idx := newFuncIndex(parsed)
+		for _, defs := range idx {
+			for _, d := range defs {
+				if files[filepath.Clean(d.file.name)] {
+					funcs[d.fn] = d
+				}
+			}
+		}
+
+		incs := map[*parsedFile][]*include{}
+		calls := map[*parsedFile][]*call{}
+		for _, f := range parsed {
+			incs[f] = includes(f)
+			calls[f] = idx.calls(f)
+		}
+
+		for changedAny := true; changedAny; {
+			changedAny = false
+			for _, f := range parsed {
+				name := filepath.Clean(f.name)
+				for _, inc := range incs[f] {
+					if files[inc.path] && !files[name] {
+						files[name] = true
+						changedAny = true
+					}
+				}
+				for _, c := range calls[f] {
+					if funcs[c.def.fn] == nil {
+						continue
+					}
+					if c.caller == nil {
+						if !files[name] {
+							files[name] = true
+							changedAny = true
+						}
+					} else if funcs[c.caller] == nil {
+						funcs[c.caller] = &funcDef{file: f, src: c.src, fn: c.caller}
+						files[name] = true
+						changedAny = true
+					}
+				}
+			}
+		}
+	}
+
+	im := &Impact{Files: sortedKeys(files)}
+	for _, d := range funcs {
+		im.Functions = append(im.Functions, &ImpactedFunction{
+			File:    d.file.name,
+			Name:    d.fn.name,
+			DefPath: d.defPath(),
+		})
+	}
+	sort.Sort(impactedFunctions(im.Functions))
+	return im
+}
+
+type impactedFunctions []*ImpactedFunction
+
+func (fs impactedFunctions) Len() int      { return len(fs) }
+func (fs impactedFunctions) Swap(i, j int) { fs[i], fs[j] = fs[j], fs[i] }
+func (fs impactedFunctions) Less(i, j int) bool {
+	return fs[i].DefPath < fs[j].DefPath
+}
@@ -0,0 +1,65 @@
+package bashgraph
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestGitChangedFiles(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	dir, err := ioutil.TempDir("", "srclib-bash-impact")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	git := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %s\n%s", args, err, out)
+		}
+	}
+	write := func(name, data string) {
+		name = filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(name, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("top.sh", "echo top\n")
+	write("scripts/deploy app.sh", "echo deploy\n")
+	write("scripts/lib/util.sh", "echo util\n")
+	git("init", "-q")
+	git("add", "-A")
+	git("commit", "-q", "-m", "initial")
+	write("top.sh", "echo changed\n")
+	write("scripts/deploy app.sh", "echo changed\n")
+	write("scripts/lib/util.sh", "echo changed\n")
+
+	// From a subdirectory of the repository, the files are named
+	// relative to it, as scan names them there.
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(filepath.Join(dir, "scripts")); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+	got, err := gitChangedFiles("HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"deploy app.sh", filepath.Join("lib", "util.sh")}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got changed files %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,74 @@
+package bashgraph
+
+import "strings"
+
+// An include is a source statement ("source FILE" or ". FILE") whose file
+// is in the repository.
+type include struct {
+	src *source
+	// word is the argument that names the file.
+	word word
+	// path is the path of the included file in the repository.
+	path string
+}
+
+// includes returns the resolved source statements in f.
+func includes(f *parsedFile) []*include {
+	var incs []*include
+	for i, s := range f.scripts {
+		for _, cmd := range s.commands {
+			if name := unquote(cmd.text); name != "source" && name != "." {
+				continue
+			}
+			args := commandArgs(s.words, cmd)
+			if len(args) == 0 {
+				continue
+			}
+			path := includePath(args[0].text)
+			if !strings.Contains(path, "/") {
+				// Bash looks for the file in PATH and then in the current
+				// directory, so assume the latter.
+				path = "./" + path
+			}
+			if path, ok := resolveScriptPath(f.name, path); ok {
+				incs = append(incs, &include{src: f.sources[i], word: args[0], path: path})
+			}
+		}
+	}
+	return incs
+}
+
+// includePath returns the path that the source statement argument arg
+// names. Scripts commonly locate their libraries relative to their own
+// directory, as in "$(dirname "$0")/lib.sh" or "${BASH_SOURCE%/*}/lib.sh",
+// so a leading expansion followed by a slash is taken to be the directory of
+// the script.
+func includePath(arg string) string {
+	i := 0
+	if i < len(arg) && arg[i] == '"' {
+		i++
+	}
+	if i+1 >= len(arg) || arg[i] != '$' {
+		return unquote(arg)
+	}
+	switch arg[i+1] {
+	case '(', '{':
+		i = scanSubstitution(arg, i+1)
+	default:
+		i++
+		for i < len(arg) && isNameChar(arg[i], false) {
+			i++
+		}
+	}
+	if i < len(arg) && arg[i] == '"' {
+		i++
+	}
+	if i >= len(arg) || arg[i] != '/' {
+		return unquote(arg)
+	}
+	rest := arg[i+1:]
+	if arg[0] == '"' {
+		rest = `"` + rest
+	}
+	return "./" + unquote(rest)
+}
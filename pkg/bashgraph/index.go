@@ -0,0 +1,121 @@
+package bashgraph
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"runtime"
+	"sort"
+	"sync"
+
+	"sourcegraph.com/sourcegraph/srclib/dep"
+	"sourcegraph.com/sourcegraph/srclib/unit"
+)
+
+// IndexCmd takes the flags of scan and graph. Graphing the units it found
+// itself, it has no use for graph's --filename, --watch and --summary.
+type IndexCmd struct {
+	ScanCmd
+	GraphCmd
+}
+
+// An indexOutput is the output of the index command.
+type indexOutput struct {
+	Units []*indexedUnit
+}
+
+// An indexedUnit is a source unit with its resolved deps and graph output.
+// The toolchain has no dependencies to resolve, so Deps is always empty.
+type indexedUnit struct {
+	Unit  *unit.SourceUnit
+	Deps  []*dep.Resolution
+	Graph *positionedOutput
+}
+
+func (c *IndexCmd) Execute(args []string) error {
+	if c.Filename != "" || c.Watch || c.Summary {
+		return fmt.Errorf("--filename, --watch and --summary can't be used with index")
+	}
+	scanDir, err := currentDir()
+	if err != nil {
+		return fmt.Errorf("resolving the path to scan failed with: %s", err)
+	}
+	config, err := readTreeConfig()
+	if err != nil {
+		return err
+	}
+
+	units, err := c.ScanCmd.scanUnits(scanDir, config)
+	if err != nil {
+		return err
+	}
+	if err := c.GraphCmd.applySettings(config); err != nil {
+		return err
+	}
+
+	idx, err := indexUnits(units, &c.Options)
+	if err != nil {
+		return err
+	}
+	if c.SQLite != "" {
+		outs := make([]*positionedOutput, len(idx.Units))
+		for i, u := range idx.Units {
+			outs[i] = u.Graph
+		}
+		return writeSQLite(c.SQLite, units, outs...)
+	}
+	if err := json.NewEncoder(os.Stdout).Encode(idx); err != nil {
+		return fmt.Errorf("Failed to output index: %s", err)
+	}
+	return nil
+}
+
+// indexUnits graphs units with opts, graphing the Jobs of opts units at
+// once (or one per CPU), each of which parses its files as many at a time.
+// The units are in the order given.
+func indexUnits(units []*unit.SourceUnit, opts *Options) (*indexOutput, error) {
+	jobs := opts.Jobs
+	if jobs <= 0 {
+		jobs = runtime.NumCPU()
+	}
+	idx := &indexOutput{Units: make([]*indexedUnit, len(units))}
+	errs := make([]error, len(units))
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for j := 0; j < jobs; j++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				idx.Units[i], errs[i] = indexUnit(units[i], opts)
+			}
+		}()
+	}
+	for i := range units {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			return nil, fmt.Errorf("Failed to graph source unit %s: %s", units[i].Name, err)
+		}
+	}
+	return idx, nil
+}
+
+// indexUnit graphs u on its own with opts, as graph does.
+func indexUnit(u *unit.SourceUnit, opts *Options) (*indexedUnit, error) {
+	output := newGraphOutput()
+	if err := graphUnit(u, opts, opts.selected, output); err != nil {
+		return nil, err
+	}
+	sortOutput(&output.Output)
+	sort.Sort(warningsByStart(output.warnings))
+	pout, err := withPositions(output, opts)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to compute positions: %s", err)
+	}
+	return &indexedUnit{Unit: u, Deps: []*dep.Resolution{}, Graph: pout}, nil
+}
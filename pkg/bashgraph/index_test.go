@@ -0,0 +1,46 @@
+package bashgraph
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"sourcegraph.com/sourcegraph/srclib/unit"
+)
+
+func TestIndexUnits(t *testing.T) {
+	opts := testOptions()
+	if err := opts.applySettings(nil); err != nil {
+		t.Fatal(err)
+	}
+	var units []*unit.SourceUnit
+	for _, name := range []string{"functions.sh", "hooks.sh", "exports.sh", "options.sh"} {
+		units = append(units, &unit.SourceUnit{
+			Key:  unit.Key{Name: name, Type: "BashDirectory"},
+			Info: unit.Info{Files: []string{filepath.Join("testdata", "graph", name)}},
+		})
+	}
+	idx, err := indexUnits(units, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(idx.Units) != len(units) {
+		t.Fatalf("got %d units, want %d", len(idx.Units), len(units))
+	}
+	for i, u := range units {
+		out, err := graphUnits(unit.SourceUnits{u}, opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pout, err := withPositions(out, opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want, _ := json.Marshal(pout)
+		got, _ := json.Marshal(idx.Units[i].Graph)
+		if idx.Units[i].Unit != u || !reflect.DeepEqual(got, want) {
+			t.Errorf("index of unit %s differs from its graph", u.Name)
+		}
+	}
+}
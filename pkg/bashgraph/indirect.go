@@ -0,0 +1,132 @@
+package bashgraph
+
+import "strings"
+
+// An assignedValue is the value in an assignment word, such as "grep -r"
+// in CMD="grep -r".
+type assignedValue struct {
+	// name is the variable assigned to.
+	name string
+	// start and end are the offsets of the value.
+	start, end int
+}
+
+// assignedValues returns the values of the assignment words in s, whether
+// they are plain assignments, prefixes of commands or arguments of export
+// and the like.
+func assignedValues(s *script) []assignedValue {
+	var values []assignedValue
+	for _, w := range s.words {
+		if w.op {
+			continue
+		}
+		name := assignmentName(w.text)
+		if name == "" {
+			continue
+		}
+		eq := strings.IndexByte(w.text, '=')
+		values = append(values, assignedValue{name: name, start: w.start + eq + 1, end: w.end})
+	}
+	return values
+}
+
+// valueAt returns the assigned value in values whose literal text contains
+// the offsets start to end, outside of any command substitution, or nil if
+// there is none.
+func valueAt(text string, values []assignedValue, start, end int) *assignedValue {
+	for i, v := range values {
+		if v.start <= start && end <= v.end && !inSubstitution(text[v.start:start]) {
+			return &values[i]
+		}
+	}
+	return nil
+}
+
+// inSubstitution reports whether the text that follows prefix is inside a
+// command substitution that prefix opens, with $( or a backquote.
+func inSubstitution(prefix string) bool {
+	depth := 0
+	backquoted := false
+	for i := 0; i < len(prefix); i++ {
+		switch prefix[i] {
+		case '\\':
+			i++
+		case '`':
+			backquoted = !backquoted
+		case '(':
+			if depth > 0 || i > 0 && prefix[i-1] == '$' {
+				depth++
+			}
+		case ')':
+			if depth > 0 {
+				depth--
+			}
+		}
+	}
+	return depth > 0 || backquoted
+}
+
+// executedVars returns the names of the variables whose values s runs as
+// commands: those expanded in command position, as in $CMD or "${CMD}", or
+// passed to eval.
+func executedVars(s *script) map[string]bool {
+	names := map[string]bool{}
+	for _, cmd := range s.commands {
+		if name := expandedVar(cmd); name != "" {
+			names[name] = true
+		}
+		if unquote(cmd.text) == "eval" {
+			for _, a := range commandArgs(s.words, cmd) {
+				if name := expandedVar(a); name != "" {
+					names[name] = true
+				}
+			}
+		}
+	}
+	return names
+}
+
+// expandedVar returns the name of the variable that w is an expansion of,
+// as in $CMD or "${CMD}", or "" if w is anything else.
+func expandedVar(w word) string {
+	text := unquote(w.text)
+	if !strings.HasPrefix(text, "$") {
+		return ""
+	}
+	text = text[1:]
+	if strings.HasPrefix(text, "{") && strings.HasSuffix(text, "}") {
+		text = text[1 : len(text)-1]
+	}
+	if !isName(text) {
+		return ""
+	}
+	return text
+}
+
+// constantCommand returns the command that every assignment in values to
+// the named variable sets it to, along with the options it passes, such as
+// grep and -r for CMD="grep -r". It reports false if the variable is never
+// assigned, or assigned values with expansions or different commands.
+func constantCommand(text string, values []assignedValue, name string) (string, []string, bool) {
+	var command string
+	var flags []string
+	for _, v := range values {
+		if v.name != name {
+			continue
+		}
+		value := text[v.start:v.end]
+		if strings.ContainsAny(value, "$`") {
+			return "", nil, false
+		}
+		words := splitWords(unquote(value))
+		if len(words) == 0 || words[0].op || isAssignment(words[0].text) {
+			return "", nil, false
+		}
+		f := commandFlags(words, words[0])
+		if command != "" && (words[0].text != command || strings.Join(f, " ") != strings.Join(flags, " ")) {
+			return "", nil, false
+		}
+		command, flags = words[0].text, f
+	}
+	return command, flags, command != ""
+}
@@ -0,0 +1,104 @@
+package bashgraph
+
+import (
+	"bytes"
+	"sort"
+	"unicode/utf8"
+)
+
+// A lineIndex converts between byte offsets and 1-based line and column
+// numbers in a file. Columns count characters, not bytes, and a byte order
+// mark at the start of the file is not counted.
+type lineIndex struct {
+	data  []byte
+	start []int // start[i] is the offset of line i+1
+}
+
+func newLineIndex(data []byte) *lineIndex {
+	li := &lineIndex{data: data, start: []int{0}}
+	for i, b := range data {
+		if b == '\n' {
+			li.start = append(li.start, i+1)
+		}
+	}
+	return li
+}
+
+// position returns the line and column of offset.
+func (li *lineIndex) position(offset int) (line, col int) {
+	if offset > len(li.data) {
+		offset = len(li.data)
+	}
+	i := sort.Search(len(li.start), func(i int) bool { return li.start[i] > offset }) - 1
+	lineStart := li.start[i]
+	if i == 0 && offset >= len(utf8BOM) && bytes.HasPrefix(li.data, utf8BOM) {
+		// Editors don't show a byte order mark, so don't count it.
+		lineStart = len(utf8BOM)
+	}
+	return i + 1, utf8.RuneCount(li.data[lineStart:offset]) + 1
+}
+
+// offset returns the byte offset of line and col, clamped to the file.
+func (li *lineIndex) offset(line, col int) int {
+	if line < 1 {
+		return 0
+	}
+	if line > len(li.start) {
+		return len(li.data)
+	}
+	off := li.start[line-1]
+	if line == 1 && bytes.HasPrefix(li.data, utf8BOM) {
+		off = len(utf8BOM)
+	}
+	for c := 1; c < col && off < len(li.data) && li.data[off] != '\n'; c++ {
+		_, size := utf8.DecodeRune(li.data[off:])
+		off += size
+	}
+	return off
+}
+
+// utf16Position returns the 0-based line of offset and its column in UTF-16
+// code units, as the Language Server Protocol counts them.
+func (li *lineIndex) utf16Position(offset int) (line, col int) {
+	if offset > len(li.data) {
+		offset = len(li.data)
+	}
+	i := sort.Search(len(li.start), func(i int) bool { return li.start[i] > offset }) - 1
+	lineStart := li.start[i]
+	if i == 0 && offset >= len(utf8BOM) && bytes.HasPrefix(li.data, utf8BOM) {
+		lineStart = len(utf8BOM)
+	}
+	for _, r := range string(li.data[lineStart:offset]) {
+		col += utf16Len(r)
+	}
+	return i, col
+}
+
+// utf16Offset returns the byte offset of the 0-based line and the column
+// col in UTF-16 code units, clamped to the file.
+func (li *lineIndex) utf16Offset(line, col int) int {
+	if line < 0 {
+		return 0
+	}
+	if line >= len(li.start) {
+		return len(li.data)
+	}
+	off := li.start[line]
+	if line == 0 && bytes.HasPrefix(li.data, utf8BOM) {
+		off = len(utf8BOM)
+	}
+	for c := 0; c < col && off < len(li.data) && li.data[off] != '\n'; {
+		r, size := utf8.DecodeRune(li.data[off:])
+		c += utf16Len(r)
+		off += size
+	}
+	return off
+}
+
+// utf16Len returns the number of UTF-16 code units that encode r.
+func utf16Len(r rune) int {
+	if r >= 0x10000 {
+		return 2
+	}
+	return 1
+}
@@ -0,0 +1,146 @@
+package bashgraph
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strings"
+	"unicode/utf8"
+
+	"sourcegraph.com/sourcegraph/srclib/graph"
+	"sourcegraph.com/sourcegraph/srclib/unit"
+)
+
+type LintCmd struct {
+	ShellCheck string `long:"shellcheck" description:"path to the shellcheck program" default:"shellcheck"`
+}
+
+func (c *LintCmd) Execute(args []string) error {
+	units, err := readSourceUnits()
+	if err != nil {
+		return err
+	}
+
+	out, err := c.lintUnits(units)
+	if err != nil {
+		return fmt.Errorf("Failed to lint source units: %s", err)
+	}
+
+	if err := json.NewEncoder(os.Stdout).Encode(out); err != nil {
+		return fmt.Errorf("Failed to output lint data: %s", err)
+	}
+	return nil
+}
+
+func (c *LintCmd) lintUnits(units unit.SourceUnits) (*graph.Output, error) {
+	output := graph.Output{}
+	for _, u := range units {
+		for _, f := range u.Files {
+			if err := c.lintFile(f, &output); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return &output, nil
+}
+
+func (c *LintCmd) lintFile(name string, output *graph.Output) error {
+	data, err := ioutil.ReadFile(name)
+	if err != nil {
+		return fmt.Errorf("Failed to read file %s: %s", name, err)
+	}
+	sources, err := extractSources(name, data)
+	if err != nil {
+		return fmt.Errorf("Failed to extract shell sources from %s: %s", name, err)
+	}
+
+	li := newLineIndex(data)
+	for _, src := range sources {
+		comments, err := c.shellCheck(src)
+		if err != nil {
+			return fmt.Errorf("Failed to run ShellCheck on %s: %s", name, err)
+		}
+		srcLines := newLineIndex([]byte(src.text))
+		for _, sc := range comments {
+			d := &Diagnostic{
+				Source:  "shellcheck",
+				Code:    fmt.Sprintf("SC%d", sc.Code),
+				Level:   sc.Level,
+				Message: sc.Message,
+				Start:   uint32(src.fileOffset(shellCheckOffset(srcLines, sc.Line, sc.Column))),
+				End:     uint32(src.fileEnd(shellCheckOffset(srcLines, sc.EndLine, sc.EndColumn))),
+			}
+			a, err := makeDiagnosticAnn(name, li, d)
+			if err != nil {
+				return err
+			}
+			output.Anns = append(output.Anns, a)
+		}
+	}
+	return nil
+}
+
+// shellCheckOffset returns the byte offset in the source whose lines are
+// indexed by li of a line and column that ShellCheck reported. ShellCheck
+// counts columns in characters, so the line is decoded as runes; unlike
+// lineIndex.offset, a byte order mark is counted, since ShellCheck reads it
+// as a character.
+func shellCheckOffset(li *lineIndex, line, col int) int {
+	if line < 1 {
+		return 0
+	}
+	if line > len(li.start) {
+		return len(li.data)
+	}
+	off := li.start[line-1]
+	for c := 1; c < col && off < len(li.data) && li.data[off] != '\n'; c++ {
+		_, size := utf8.DecodeRune(li.data[off:])
+		off += size
+	}
+	return off
+}
+
+// A shellCheckComment is a finding in ShellCheck's JSON output.
+type shellCheckComment struct {
+	Line      int    `json:"line"`
+	EndLine   int    `json:"endLine"`
+	Column    int    `json:"column"`
+	EndColumn int    `json:"endColumn"`
+	Level     string `json:"level"`
+	Code      int    `json:"code"`
+	Message   string `json:"message"`
+}
+
+// shellCheck runs ShellCheck on src. Sources without a #! line, such as
+// those extracted from other formats, are checked as Bash.
+func (c *LintCmd) shellCheck(src *source) ([]*shellCheckComment, error) {
+	args := []string{"--format=json1"}
+	if _, ok := shebangLine([]byte(src.text)); !ok {
+		args = append(args, "--shell=bash")
+	}
+	cmd := exec.Command(c.ShellCheck, append(args, "-")...)
+	cmd.Stdin = strings.NewReader(src.text)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		// ShellCheck exits with status 1 when it finds problems.
+		if exitErr, ok := err.(*exec.ExitError); !ok || len(out) == 0 {
+			if ok {
+				return nil, fmt.Errorf("%s: %s", exitErr, stderr.String())
+			}
+			return nil, err
+		}
+	}
+
+	var result struct {
+		Comments []*shellCheckComment `json:"comments"`
+	}
+	if err := json.Unmarshal(out, &result); err != nil {
+		return nil, fmt.Errorf("parsing output failed: %s", err)
+	}
+	return result.Comments, nil
+}
@@ -0,0 +1,54 @@
+package bashgraph
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"sourcegraph.com/sourcegraph/srclib/graph"
+)
+
+func TestLintColumns(t *testing.T) {
+	dir, err := ioutil.TempDir("", "srclib-bash-lint")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// ShellCheck reports $x, after two 2-byte characters, at the character
+	// columns 20 to 22.
+	script := filepath.Join(dir, "t.sh")
+	if err := ioutil.WriteFile(script, []byte("#!/bin/bash\necho 'héllo wörld' $x\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	shellCheck := filepath.Join(dir, "shellcheck")
+	fake := `#!/bin/sh
+cat >/dev/null
+echo '{"comments":[{"line":2,"endLine":2,"column":20,"endColumn":22,"level":"info","code":2086,"message":"Double quote to prevent globbing and word splitting."}]}'
+exit 1
+`
+	if err := ioutil.WriteFile(shellCheck, []byte(fake), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	c := &LintCmd{ShellCheck: shellCheck}
+	var output graph.Output
+	if err := c.lintFile(script, &output); err != nil {
+		t.Fatal(err)
+	}
+	if len(output.Anns) != 1 {
+		t.Fatalf("got %d annotations, want 1", len(output.Anns))
+	}
+	var d Diagnostic
+	if err := json.Unmarshal(output.Anns[0].Data, &d); err != nil {
+		t.Fatal(err)
+	}
+	if want := uint32(len("#!/bin/bash\necho 'héllo wörld' ")); d.Start != want || d.End != want+2 {
+		t.Errorf("got span %d-%d, want %d-%d", d.Start, d.End, want, want+2)
+	}
+	if d.StartPos != (Position{Line: 2, Column: 20}) || d.EndPos != (Position{Line: 2, Column: 22}) {
+		t.Errorf("got positions %+v-%+v, want 2:20-2:22", d.StartPos, d.EndPos)
+	}
+}
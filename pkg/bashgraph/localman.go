@@ -0,0 +1,78 @@
+package bashgraph
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// localManPages caches the paths of the man pages installed on this host,
+// as localManPage returns them, by command name, with "" for commands that
+// have none. Units may be graphed at once, so it is guarded by
+// localManPagesMu.
+var (
+	localManPagesMu sync.Mutex
+	localManPages   = map[string]string{}
+)
+
+// localManPage returns the man page installed on this host that documents
+// command, if there is one. It asks man -w for the page's location, and
+// searches the directories in MANPATH (or /usr/share/man) if man is not
+// installed. The page is attributed to repo, as set by the --local-man-repo
+// option of the graph command, and its path is relative to the directory
+// holding its section directory, as in man1/jq.1.gz.
+func localManPage(command, repo string) (manPage, bool) {
+	localManPagesMu.Lock()
+	defer localManPagesMu.Unlock()
+	rel, ok := localManPages[command]
+	if !ok {
+		var path string
+		out, err := exec.Command("man", "-w", command).Output()
+		if err == nil {
+			path = strings.TrimSpace(strings.SplitN(string(out), "\n", 2)[0])
+		} else if _, ok := err.(*exec.Error); ok {
+			// man is not installed.
+			path = findManPage(command)
+		}
+		rel, _ = manPagePath(path)
+		localManPages[command] = rel
+	}
+	if rel == "" {
+		return manPage{}, false
+	}
+	return manPage{repo: repo, path: rel}, true
+}
+
+// findManPage returns the path of the section 1 or 8 man page for command
+// in the directories listed in MANPATH, or "" if there is none.
+func findManPage(command string) string {
+	dirs := filepath.SplitList(os.Getenv("MANPATH"))
+	if len(dirs) == 0 {
+		dirs = []string{"/usr/share/man", "/usr/local/share/man"}
+	}
+	for _, dir := range dirs {
+		if dir == "" {
+			continue
+		}
+		for _, section := range []string{"1", "8"} {
+			matches, _ := filepath.Glob(filepath.Join(dir, "man"+section, command+"."+section+"*"))
+			if len(matches) > 0 {
+				return matches[0]
+			}
+		}
+	}
+	return ""
+}
+
+// manPagePath returns the last two components of the path of an installed
+// man page, such as man1/jq.1.gz.
+func manPagePath(path string) (string, bool) {
+	dir, file := filepath.Split(path)
+	section := filepath.Base(dir)
+	if file == "" || !strings.HasPrefix(section, "man") {
+		return "", false
+	}
+	return section + "/" + file, true
+}
@@ -0,0 +1,80 @@
+package bashgraph
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+)
+
+// LogOptions are the options that control what all commands log to STDERR.
+type LogOptions struct {
+	Quiet    bool   `long:"quiet" short:"q" description:"only log errors; the same as --log-level=error"`
+	LogLevel string `long:"log-level" description:"least severe level of messages to log" choice:"debug" choice:"info" choice:"warn" choice:"error" default:"info" value-name:"LEVEL"`
+	LogJSON  bool   `long:"log-json" description:"log messages as JSON objects, one per line"`
+}
+
+// logOpts are the logging options of the command line, set by
+// SetGlobalOptions.
+var logOpts LogOptions
+
+// The levels of log messages, from least to most severe.
+const (
+	levelDebug = iota
+	levelInfo
+	levelWarn
+	levelError
+)
+
+var levelNames = []string{"debug", "info", "warn", "error"}
+
+// logOutput is where log messages are written.
+var logOutput io.Writer = os.Stderr
+
+// logEnabled reports whether messages of the given level are logged.
+func logEnabled(level int) bool {
+	min := levelInfo
+	for l, name := range levelNames {
+		if name == logOpts.LogLevel {
+			min = l
+		}
+	}
+	if logOpts.Quiet {
+		min = levelError
+	}
+	return level >= min
+}
+
+// logf logs a message of the given level, formatted as by fmt.Sprintf,
+// as plain text or, with --log-json, as a JSON object with level and msg
+// fields.
+func logf(level int, format string, args ...interface{}) {
+	if !logEnabled(level) {
+		return
+	}
+	msg := fmt.Sprintf(format, args...)
+	if logOpts.LogJSON {
+		data, err := json.Marshal(struct {
+			Level string `json:"level"`
+			Msg   string `json:"msg"`
+		}{levelNames[level], msg})
+		if err == nil {
+			fmt.Fprintf(logOutput, "%s\n", data)
+			return
+		}
+	}
+	if level != levelInfo {
+		msg = levelNames[level] + ": " + msg
+	}
+	fmt.Fprintln(logOutput, msg)
+}
+
+func logDebugf(format string, args ...interface{}) { logf(levelDebug, format, args...) }
+func logInfof(format string, args ...interface{})  { logf(levelInfo, format, args...) }
+func logWarnf(format string, args ...interface{})  { logf(levelWarn, format, args...) }
+func logErrorf(format string, args ...interface{}) { logf(levelError, format, args...) }
+
+// LogError logs err as an error, following the logging options.
+func LogError(err error) {
+	logErrorf("%s", err)
+}
@@ -0,0 +1,504 @@
+package bashgraph
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"sourcegraph.com/sourcegraph/srclib/graph"
+	"sourcegraph.com/sourcegraph/srclib/unit"
+)
+
+type LSPCmd struct{}
+
+func (c *LSPCmd) Execute(args []string) error {
+	return newLSPServer().serve(os.Stdin, os.Stdout)
+}
+
+// An lspServer answers Language Server Protocol requests about the scripts
+// in a workspace, graphing them as one source unit along with the open
+// documents, whose contents may not be saved.
+type lspServer struct {
+	// root is the workspace directory, which file names are relative to.
+	root string
+	// files are the scripts found in root by scan.
+	files []string
+	// buffers holds the contents of the open documents, by file name.
+	buffers map[string][]byte
+	// out is the graph output of the workspace, or nil if it has changed
+	// since it was last graphed, and index is the index of its symbols.
+	out   *graphOutput
+	index *SymbolIndex
+	// opts are the options the workspace is graphed with, whose cache
+	// holds the files that were parsed already.
+	opts *Options
+}
+
+func newLSPServer() *lspServer {
+	opts := commandOptions()
+	opts.cache = newParseCache()
+	return &lspServer{buffers: map[string][]byte{}, opts: opts}
+}
+
+// serve reads requests from r and writes responses to w, framed with
+// Content-Length headers, until r ends or the client sends exit.
+func (s *lspServer) serve(r io.Reader, w io.Writer) error {
+	in := bufio.NewReader(r)
+	for {
+		data, err := readLSPMessage(in)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		var req rpcRequest
+		if err := json.Unmarshal(data, &req); err != nil {
+			resp := &rpcResponse{JSONRPC: "2.0", ID: json.RawMessage("null"), Error: &rpcError{rpcParseError, err.Error()}}
+			if err := writeLSPMessage(w, resp); err != nil {
+				return err
+			}
+			continue
+		}
+		if req.Method == "exit" {
+			return nil
+		}
+		result, rerr := s.call(req.Method, req.Params)
+		if len(req.ID) == 0 {
+			continue
+		}
+		if err := writeLSPMessage(w, newRPCResponse(req.ID, result, rerr)); err != nil {
+			return err
+		}
+	}
+}
+
+// readLSPMessage reads the body of a message framed with a Content-Length
+// header from r.
+func readLSPMessage(r *bufio.Reader) ([]byte, error) {
+	length := -1
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			if err == io.EOF && line == "" && length < 0 {
+				return nil, io.EOF
+			}
+			return nil, fmt.Errorf("Failed to read message header: %s", err)
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			break
+		}
+		if i := strings.IndexByte(line, ':'); i > 0 && strings.EqualFold(line[:i], "Content-Length") {
+			if length, err = strconv.Atoi(strings.TrimSpace(line[i+1:])); err != nil {
+				return nil, fmt.Errorf("Invalid Content-Length header %q", line)
+			}
+		}
+	}
+	if length < 0 {
+		return nil, fmt.Errorf("Message has no Content-Length header")
+	}
+	data := make([]byte, length)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, fmt.Errorf("Failed to read message: %s", err)
+	}
+	return data, nil
+}
+
+// writeLSPMessage writes resp to w framed with a Content-Length header.
+func writeLSPMessage(w io.Writer, resp *rpcResponse) error {
+	data, err := json.Marshal(resp)
+	if err != nil {
+		return fmt.Errorf("Failed to encode response: %s", err)
+	}
+	if _, err := fmt.Fprintf(w, "Content-Length: %d\r\n\r\n%s", len(data), data); err != nil {
+		return fmt.Errorf("Failed to write response: %s", err)
+	}
+	return nil
+}
+
+// The LSP types used by the server, with only the fields it uses.
+type (
+	lspPosition struct {
+		Line      int `json:"line"`
+		Character int `json:"character"`
+	}
+	lspRange struct {
+		Start lspPosition `json:"start"`
+		End   lspPosition `json:"end"`
+	}
+	lspLocation struct {
+		URI   string   `json:"uri"`
+		Range lspRange `json:"range"`
+	}
+	lspTextDocument struct {
+		URI  string `json:"uri"`
+		Text string `json:"text"`
+	}
+	lspPositionParams struct {
+		TextDocument lspTextDocument `json:"textDocument"`
+		Position     lspPosition     `json:"position"`
+		Context      struct {
+			IncludeDeclaration bool `json:"includeDeclaration"`
+		} `json:"context"`
+	}
+	lspDocumentParams struct {
+		TextDocument   lspTextDocument `json:"textDocument"`
+		ContentChanges []struct {
+			Text string `json:"text"`
+		} `json:"contentChanges"`
+	}
+	lspSymbol struct {
+		Name          string      `json:"name"`
+		Kind          int         `json:"kind"`
+		Location      lspLocation `json:"location"`
+		ContainerName string      `json:"containerName,omitempty"`
+	}
+	lspHover struct {
+		Contents struct {
+			Kind  string `json:"kind"`
+			Value string `json:"value"`
+		} `json:"contents"`
+		Range lspRange `json:"range"`
+	}
+)
+
+// The LSP symbol kinds of functions and variables.
+const (
+	lspSymbolFunction = 12
+	lspSymbolVariable = 13
+)
+
+// call runs the named method with params and returns its result.
+func (s *lspServer) call(method string, params json.RawMessage) (interface{}, *rpcError) {
+	switch method {
+	case "initialize":
+		var p struct {
+			RootURI  string `json:"rootUri"`
+			RootPath string `json:"rootPath"`
+		}
+		if err := unmarshalParams(params, &p); err != nil {
+			return nil, err
+		}
+		if err := s.initialize(p.RootURI, p.RootPath); err != nil {
+			return nil, &rpcError{rpcInternalError, err.Error()}
+		}
+		return map[string]interface{}{
+			"capabilities": map[string]interface{}{
+				"textDocumentSync":       1, // full
+				"definitionProvider":     true,
+				"referencesProvider":     true,
+				"documentSymbolProvider": true,
+				"hoverProvider":          true,
+			},
+			"serverInfo": map[string]string{"name": "srclib-bash"},
+		}, nil
+	case "initialized", "shutdown":
+		return nil, nil
+	case "textDocument/didSave", "workspace/didChangeWatchedFiles":
+		// Files on disk changed; the parse cache notices which.
+		s.out = nil
+		return nil, nil
+	case "textDocument/didOpen", "textDocument/didChange", "textDocument/didClose":
+		var p lspDocumentParams
+		if err := unmarshalParams(params, &p); err != nil {
+			return nil, err
+		}
+		name := s.fileName(p.TextDocument.URI)
+		switch method {
+		case "textDocument/didOpen":
+			s.buffers[name] = []byte(p.TextDocument.Text)
+		case "textDocument/didChange":
+			if n := len(p.ContentChanges); n > 0 {
+				s.buffers[name] = []byte(p.ContentChanges[n-1].Text)
+			}
+		default:
+			delete(s.buffers, name)
+		}
+		s.out = nil
+		return nil, nil
+	case "textDocument/definition", "textDocument/references", "textDocument/hover", "textDocument/documentSymbol":
+		var p lspPositionParams
+		if err := unmarshalParams(params, &p); err != nil {
+			return nil, err
+		}
+		out, err := s.graph()
+		if err != nil {
+			return nil, &rpcError{rpcInternalError, err.Error()}
+		}
+		name := s.fileName(p.TextDocument.URI)
+		switch method {
+		case "textDocument/definition":
+			return s.definition(out, name, p.Position), nil
+		case "textDocument/references":
+			return s.references(out, name, p.Position, p.Context.IncludeDeclaration), nil
+		case "textDocument/hover":
+			return s.hover(out, name, p.Position), nil
+		default:
+			return s.documentSymbols(out, name), nil
+		}
+	}
+	return nil, &rpcError{rpcMethodNotFound, fmt.Sprintf("unknown method %q", method)}
+}
+
+// initialize makes the directory of rootURI, or else rootPath, or else the
+// current directory, the workspace, and scans it for scripts.
+func (s *lspServer) initialize(rootURI, rootPath string) error {
+	root := rootPath
+	if u, err := url.Parse(rootURI); err == nil && u.Scheme == "file" {
+		root = filepath.FromSlash(u.Path)
+	}
+	var err error
+	if root == "" {
+		if root, err = os.Getwd(); err != nil {
+			return fmt.Errorf("Failed to find the current directory: %s", err)
+		}
+	}
+	if root, err = filepath.EvalSymlinks(root); err != nil {
+		return fmt.Errorf("Failed to resolve workspace %s: %s", root, err)
+	}
+	// File names in the graph output are relative to the current
+	// directory, as they are when srclib runs graph.
+	if err := os.Chdir(root); err != nil {
+		return fmt.Errorf("Failed to change to workspace %s: %s", root, err)
+	}
+	units, err := new(ScanCmd).scan(root)
+	if err != nil {
+		return err
+	}
+	s.root, s.files = root, nil
+	for _, u := range units {
+		s.files = append(s.files, u.Files...)
+	}
+	s.out = nil
+	return nil
+}
+
+// fileName returns the name of the file at uri, relative to the workspace
+// if it is in it.
+func (s *lspServer) fileName(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "file" {
+		return uri
+	}
+	name := filepath.FromSlash(u.Path)
+	if s.root != "" {
+		if rel, err := filepath.Rel(s.root, name); err == nil && !strings.HasPrefix(rel, "..") {
+			return rel
+		}
+	}
+	return name
+}
+
+// fileURI returns the URI of the named file.
+func (s *lspServer) fileURI(name string) string {
+	if !filepath.IsAbs(name) {
+		name = filepath.Join(s.root, name)
+	}
+	return (&url.URL{Scheme: "file", Path: filepath.ToSlash(name)}).String()
+}
+
+// graph returns the graph output of the workspace's scripts and the open
+// documents, graphing them again if they have changed.
+func (s *lspServer) graph() (*graphOutput, error) {
+	if s.out != nil {
+		return s.out, nil
+	}
+	names := append([]string(nil), s.files...)
+	for name := range s.buffers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	output := newGraphOutput()
+	var files []*parsedFile
+	for i, name := range names {
+		if i > 0 && name == names[i-1] {
+			continue
+		}
+		var f *parsedFile
+		var err error
+		if data, ok := s.buffers[name]; ok {
+			output.contents[name] = data
+			f, err = parseData(name, data)
+		} else {
+			f, err = parseUnitFile(name, s.opts)
+		}
+		if err != nil {
+			s.opts.warnf("Skipping file: %s", err)
+			continue
+		}
+		files = append(files, f)
+	}
+	idx, err := defaultPipeline.indexer.index(&unit.SourceUnit{}, files, s.opts)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range files {
+		if err := graphFile(f, idx, output); err != nil {
+			s.opts.warnf("Failed to graph %s completely: %s", f.name, err)
+		}
+	}
+	sortOutput(&output.Output)
+	s.out, s.index = output, NewSymbolIndex(output.Defs, output.Refs)
+	return output, nil
+}
+
+// lines returns the line index of the named file, from the open document
+// if it is one.
+func (s *lspServer) lines(out *graphOutput, name string) *lineIndex {
+	data, ok := out.contents[name]
+	if !ok {
+		data, _ = ioutil.ReadFile(name)
+	}
+	return newLineIndex(data)
+}
+
+// location returns the LSP location of the span start:end of the named file.
+func (s *lspServer) location(out *graphOutput, name string, start, end uint32) lspLocation {
+	li := s.lines(out, name)
+	var r lspRange
+	r.Start.Line, r.Start.Character = li.utf16Position(int(start))
+	r.End.Line, r.End.Character = li.utf16Position(int(end))
+	return lspLocation{URI: s.fileURI(name), Range: r}
+}
+
+// refAt returns the ref in the named file whose span contains pos, the
+// narrowest if several do, or nil.
+func (s *lspServer) refAt(out *graphOutput, name string, pos lspPosition) *graph.Ref {
+	offset := uint32(s.lines(out, name).utf16Offset(pos.Line, pos.Character))
+	return s.index.RefAt(name, offset)
+}
+
+func (s *lspServer) definition(out *graphOutput, name string, pos lspPosition) interface{} {
+	ref := s.refAt(out, name, pos)
+	if ref == nil {
+		return nil
+	}
+	def := s.index.DefOf(ref)
+	if def == nil {
+		return nil
+	}
+	return s.location(out, def.File, def.DefStart, def.DefEnd)
+}
+
+func (s *lspServer) references(out *graphOutput, name string, pos lspPosition, includeDecl bool) []lspLocation {
+	target := s.refAt(out, name, pos)
+	if target == nil {
+		return nil
+	}
+	locs := []lspLocation{}
+	for _, ref := range s.index.RefsTo(target.DefKey()) {
+		if ref.Def && !includeDecl {
+			continue
+		}
+		locs = append(locs, s.location(out, ref.File, ref.Start, ref.End))
+	}
+	return locs
+}
+
+func (s *lspServer) documentSymbols(out *graphOutput, name string) []lspSymbol {
+	syms := []lspSymbol{}
+	for _, def := range s.index.DefsIn(name) {
+		if def.Kind == "script" {
+			continue
+		}
+		kind := lspSymbolFunction
+		if def.Kind == "var" {
+			kind = lspSymbolVariable
+		}
+		sym := lspSymbol{Name: def.Name, Kind: kind, Location: s.location(out, def.File, def.DefStart, def.DefEnd)}
+		if def.Local {
+			// Local variables' DefPaths end in FUNCTION/$NAME.
+			parts := strings.Split(def.Path, "/")
+			sym.ContainerName = parts[len(parts)-2]
+		}
+		if def.Kind == "var" {
+			sym.Name = "$" + def.Name
+		}
+		syms = append(syms, sym)
+	}
+	return syms
+}
+
+func (s *lspServer) hover(out *graphOutput, name string, pos lspPosition) interface{} {
+	ref := s.refAt(out, name, pos)
+	if ref == nil {
+		return nil
+	}
+	var text string
+	if def := s.index.DefOf(ref); def != nil {
+		text = defHoverText(out, def)
+	} else if ref.DefUnitType == "ManPages" {
+		text = fmt.Sprintf("`%s`: man page %s", path.Base(ref.DefPath), path.Dir(ref.DefPath))
+		if section := out.sections[ref]; section != "" {
+			text += fmt.Sprintf(", section %q", section)
+		}
+		if summary, ok := manSummaries[path.Base(ref.DefPath)]; ok && out.sections[ref] == "" {
+			text += "\n\n" + summary.description
+			if summary.synopsis != "" {
+				text += fmt.Sprintf("\n\n```\n%s\n```", summary.synopsis)
+			}
+		}
+	}
+	if text == "" {
+		return nil
+	}
+	var h lspHover
+	h.Contents.Kind = "markdown"
+	h.Contents.Value = text
+	h.Range = s.location(out, ref.File, ref.Start, ref.End).Range
+	return h
+}
+
+// defHoverText returns the text to show when hovering over a ref to def:
+// its kind and name, and the comment before it.
+func defHoverText(out *graphOutput, def *graph.Def) string {
+	var data DefData
+	if err := json.Unmarshal(def.Data, &data); err != nil {
+		return ""
+	}
+	text := fmt.Sprintf("```bash\n%s %s\n```", data.Keyword, data.Name)
+	if data.Keyword == "" {
+		text = fmt.Sprintf("```bash\n%s\n```", data.Name)
+	}
+	content, ok := out.contents[def.File]
+	if !ok {
+		content, _ = ioutil.ReadFile(def.File)
+	}
+	if doc := commentBefore(content, int(def.DefStart)); doc != "" {
+		text += "\n\n" + doc
+	}
+	return text
+}
+
+// commentBefore returns the text of the comment lines directly above the
+// line of offset in data, without their # markers, or "" if there are none.
+// A #! line is not a comment.
+func commentBefore(data []byte, offset int) string {
+	if offset > len(data) {
+		return ""
+	}
+	end := bytes.LastIndexByte(data[:offset], '\n')
+	var lines []string
+	for end > 0 {
+		start := bytes.LastIndexByte(data[:end], '\n') + 1
+		line := strings.TrimSpace(string(data[start:end]))
+		if !strings.HasPrefix(line, "#") || strings.HasPrefix(line, "#!") {
+			break
+		}
+		lines = append([]string{strings.TrimSpace(strings.TrimPrefix(line, "#"))}, lines...)
+		end = start - 1
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,104 @@
+package bashgraph
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLSP(t *testing.T) {
+	dir, err := ioutil.TempDir("", "srclib-bash-lsp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	lib := "# greet prints a greeting.\ngreet() { echo \"hi $1\"; }\n"
+	if err := ioutil.WriteFile(filepath.Join(dir, "lib.sh"), []byte(lib), 0644); err != nil {
+		t.Fatal(err)
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	root, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := newLSPServer()
+	mainURI := s.fileURI(filepath.Join(root, "main.sh"))
+	messages := []string{
+		fmt.Sprintf(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"rootUri":%q}}`, s.fileURI(root)),
+		`{"jsonrpc":"2.0","method":"initialized","params":{}}`,
+		// The open document is graphed from its unsaved contents.
+		fmt.Sprintf(`{"jsonrpc":"2.0","method":"textDocument/didOpen","params":{"textDocument":{"uri":%q,"text":"source lib.sh\ngreet world\ngreet again\n"}}}`, mainURI),
+		fmt.Sprintf(`{"jsonrpc":"2.0","id":2,"method":"textDocument/definition","params":{"textDocument":{"uri":%q},"position":{"line":1,"character":2}}}`, mainURI),
+		fmt.Sprintf(`{"jsonrpc":"2.0","id":3,"method":"textDocument/references","params":{"textDocument":{"uri":%q},"position":{"line":1,"character":2},"context":{"includeDeclaration":true}}}`, mainURI),
+		fmt.Sprintf(`{"jsonrpc":"2.0","id":4,"method":"textDocument/hover","params":{"textDocument":{"uri":%q},"position":{"line":2,"character":0}}}`, mainURI),
+		fmt.Sprintf(`{"jsonrpc":"2.0","id":5,"method":"textDocument/documentSymbol","params":{"textDocument":{"uri":%q}}}`, s.fileURI(filepath.Join(root, "lib.sh"))),
+		`{"jsonrpc":"2.0","method":"exit"}`,
+	}
+	var in bytes.Buffer
+	for _, m := range messages {
+		fmt.Fprintf(&in, "Content-Length: %d\r\n\r\n%s", len(m), m)
+	}
+	var out bytes.Buffer
+	if err := s.serve(&in, &out); err != nil {
+		t.Fatal(err)
+	}
+
+	results := map[int]json.RawMessage{}
+	r := bufio.NewReader(&out)
+	for {
+		data, err := readLSPMessage(r)
+		if err != nil {
+			break
+		}
+		var resp rpcResponse
+		if err := json.Unmarshal(data, &resp); err != nil {
+			t.Fatal(err)
+		}
+		if resp.Error != nil {
+			t.Fatalf("response %s: %s", resp.ID, resp.Error.Message)
+		}
+		var id int
+		json.Unmarshal(resp.ID, &id)
+		results[id] = resp.Result
+	}
+
+	var def lspLocation
+	if err := json.Unmarshal(results[2], &def); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(def.URI, "/lib.sh") || def.Range.Start.Line != 1 || def.Range.Start.Character != 0 {
+		t.Errorf("got definition %+v, want lib.sh line 1", def)
+	}
+	var refs []lspLocation
+	if err := json.Unmarshal(results[3], &refs); err != nil {
+		t.Fatal(err)
+	}
+	if len(refs) != 3 {
+		t.Errorf("got %d references, want the def and 2 calls", len(refs))
+	}
+	var hover lspHover
+	if err := json.Unmarshal(results[4], &hover); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(hover.Contents.Value, "greet prints a greeting.") {
+		t.Errorf("got hover %q, want the doc comment", hover.Contents.Value)
+	}
+	var syms []lspSymbol
+	if err := json.Unmarshal(results[5], &syms); err != nil {
+		t.Fatal(err)
+	}
+	if len(syms) != 1 || syms[0].Name != "greet" || syms[0].Kind != lspSymbolFunction {
+		t.Errorf("got symbols %+v, want greet", syms)
+	}
+}
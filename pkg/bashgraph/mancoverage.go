@@ -0,0 +1,112 @@
+package bashgraph
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"sourcegraph.com/sourcegraph/srclib/unit"
+)
+
+type ManCoverageCmd struct {
+	JSON     bool `long:"json" description:"output the report as JSON"`
+	LocalMan bool `long:"local-man" description:"count commands documented by the man pages installed on this host as linked"`
+}
+
+// A CommandCoverage records how often an external command is run and which
+// man page it is linked to.
+type CommandCoverage struct {
+	Name  string
+	Count int
+	// Repo and Page locate the command's man page; they are empty if the
+	// command is not linked to one.
+	Repo string `json:",omitempty"`
+	Page string `json:",omitempty"`
+}
+
+func (c *ManCoverageCmd) Execute(args []string) error {
+	units, err := readSourceUnits()
+	if err != nil {
+		return err
+	}
+
+	coverage := manCoverage(units, c.LocalMan, commandOptions())
+	if c.JSON {
+		if err := json.NewEncoder(os.Stdout).Encode(coverage); err != nil {
+			return fmt.Errorf("Failed to output man page coverage: %s", err)
+		}
+		return nil
+	}
+
+	var uses, linkedUses, linked int
+	for _, cc := range coverage {
+		page := "-"
+		if cc.Page != "" {
+			page = cc.Repo + "/" + cc.Page
+			linked++
+			linkedUses += cc.Count
+		}
+		uses += cc.Count
+		fmt.Printf("%6d %-20s %s\n", cc.Count, cc.Name, page)
+	}
+	if uses > 0 {
+		fmt.Printf("%d of %d commands linked, covering %d of %d uses (%.0f%%)\n",
+			linked, len(coverage), linkedUses, uses, 100*float64(linkedUses)/float64(uses))
+	}
+	return nil
+}
+
+// manCoverage counts the runs of each external command in units and looks
+// up their man pages. Commands are sorted by decreasing count, then name.
+// Commands named by a relative path are scripts, not installed programs,
+// and are left out.
+func manCoverage(units unit.SourceUnits, localMan bool, opts *Options) []*CommandCoverage {
+	counts := map[string]int{}
+	for _, u := range units {
+		files := parseUnit(u, opts)
+		funcs := map[string]bool{}
+		for name := range newFuncIndex(files) {
+			funcs[name] = true
+		}
+		for _, f := range files {
+			for _, s := range f.scripts {
+				for _, cmd := range s.commands {
+					name, ok := externalCommand(f, cmd, funcs)
+					if !ok || strings.Contains(name, "/") && !filepath.IsAbs(name) {
+						continue
+					}
+					counts[filepath.Base(name)]++
+				}
+			}
+		}
+	}
+
+	var coverage []*CommandCoverage
+	for name, count := range counts {
+		cc := &CommandCoverage{Name: name, Count: count}
+		page, ok := manPages[name]
+		if !ok && localMan {
+			page, ok = localManPage(name, opts.LocalManRepo)
+		}
+		if ok {
+			cc.Repo, cc.Page = page.repo, page.path
+		}
+		coverage = append(coverage, cc)
+	}
+	sort.Sort(commandCoverages(coverage))
+	return coverage
+}
+
+type commandCoverages []*CommandCoverage
+
+func (cs commandCoverages) Len() int      { return len(cs) }
+func (cs commandCoverages) Swap(i, j int) { cs[i], cs[j] = cs[j], cs[i] }
+func (cs commandCoverages) Less(i, j int) bool {
+	if cs[i].Count != cs[j].Count {
+		return cs[i].Count > cs[j].Count
+	}
+	return cs[i].Name < cs[j].Name
+}
@@ -0,0 +1,231 @@
+// Code generated by gen_manpages.go from manpages.txt; DO NOT EDIT.
+
+package bashgraph
+
+// manPages maps command names to the pages that document them.
+var manPages = map[string]manPage{
+	"admin":      {"github.com/sourcegraph/man-pages-posix", "man1p/admin.1p.txt"},
+	"alias":      {"github.com/sourcegraph/man-pages-posix", "man1p/alias.1p.txt"},
+	"ar":         {"github.com/sourcegraph/man-pages-posix", "man1p/ar.1p.txt"},
+	"asa":        {"github.com/sourcegraph/man-pages-posix", "man1p/asa.1p.txt"},
+	"at":         {"github.com/sourcegraph/man-pages-posix", "man1p/at.1p.txt"},
+	"awk":        {"github.com/sourcegraph/man-pages-posix", "man1p/awk.1p.txt"},
+	"basename":   {"github.com/sourcegraph/man-pages-posix", "man1p/basename.1p.txt"},
+	"bash":       {"github.com/sourcegraph/man-pages-linux", "man1/bash.1"},
+	"batch":      {"github.com/sourcegraph/man-pages-posix", "man1p/batch.1p.txt"},
+	"bc":         {"github.com/sourcegraph/man-pages-posix", "man1p/bc.1p.txt"},
+	"bg":         {"github.com/sourcegraph/man-pages-posix", "man1p/bg.1p.txt"},
+	"blkid":      {"github.com/sourcegraph/man-pages-linux", "man8/blkid.8"},
+	"break":      {"github.com/sourcegraph/man-pages-posix", "man1p/break.1p.txt"},
+	"c99":        {"github.com/sourcegraph/man-pages-posix", "man1p/c99.1p.txt"},
+	"cal":        {"github.com/sourcegraph/man-pages-posix", "man1p/cal.1p.txt"},
+	"cat":        {"github.com/sourcegraph/man-pages-posix", "man1p/cat.1p.txt"},
+	"cd":         {"github.com/sourcegraph/man-pages-posix", "man1p/cd.1p.txt"},
+	"cflow":      {"github.com/sourcegraph/man-pages-posix", "man1p/cflow.1p.txt"},
+	"chgrp":      {"github.com/sourcegraph/man-pages-posix", "man1p/chgrp.1p.txt"},
+	"chmod":      {"github.com/sourcegraph/man-pages-posix", "man1p/chmod.1p.txt"},
+	"chown":      {"github.com/sourcegraph/man-pages-posix", "man1p/chown.1p.txt"},
+	"chroot":     {"github.com/sourcegraph/man-pages-linux", "man8/chroot.8"},
+	"cksum":      {"github.com/sourcegraph/man-pages-posix", "man1p/cksum.1p.txt"},
+	"cmp":        {"github.com/sourcegraph/man-pages-posix", "man1p/cmp.1p.txt"},
+	"colon":      {"github.com/sourcegraph/man-pages-posix", "man1p/colon.1p.txt"},
+	"comm":       {"github.com/sourcegraph/man-pages-posix", "man1p/comm.1p.txt"},
+	"command":    {"github.com/sourcegraph/man-pages-posix", "man1p/command.1p.txt"},
+	"compress":   {"github.com/sourcegraph/man-pages-posix", "man1p/compress.1p.txt"},
+	"continue":   {"github.com/sourcegraph/man-pages-posix", "man1p/continue.1p.txt"},
+	"cp":         {"github.com/sourcegraph/man-pages-posix", "man1p/cp.1p.txt"},
+	"crontab":    {"github.com/sourcegraph/man-pages-posix", "man1p/crontab.1p.txt"},
+	"csplit":     {"github.com/sourcegraph/man-pages-posix", "man1p/csplit.1p.txt"},
+	"ctags":      {"github.com/sourcegraph/man-pages-posix", "man1p/ctags.1p.txt"},
+	"curl":       {"github.com/sourcegraph/man-pages-linux", "man1/curl.1"},
+	"cut":        {"github.com/sourcegraph/man-pages-posix", "man1p/cut.1p.txt"},
+	"cxref":      {"github.com/sourcegraph/man-pages-posix", "man1p/cxref.1p.txt"},
+	"date":       {"github.com/sourcegraph/man-pages-posix", "man1p/date.1p.txt"},
+	"dd":         {"github.com/sourcegraph/man-pages-posix", "man1p/dd.1p.txt"},
+	"delta":      {"github.com/sourcegraph/man-pages-posix", "man1p/delta.1p.txt"},
+	"df":         {"github.com/sourcegraph/man-pages-posix", "man1p/df.1p.txt"},
+	"diff":       {"github.com/sourcegraph/man-pages-posix", "man1p/diff.1p.txt"},
+	"dirname":    {"github.com/sourcegraph/man-pages-posix", "man1p/dirname.1p.txt"},
+	"dot":        {"github.com/sourcegraph/man-pages-posix", "man1p/dot.1p.txt"},
+	"du":         {"github.com/sourcegraph/man-pages-posix", "man1p/du.1p.txt"},
+	"echo":       {"github.com/sourcegraph/man-pages-posix", "man1p/echo.1p.txt"},
+	"ed":         {"github.com/sourcegraph/man-pages-posix", "man1p/ed.1p.txt"},
+	"env":        {"github.com/sourcegraph/man-pages-posix", "man1p/env.1p.txt"},
+	"eval":       {"github.com/sourcegraph/man-pages-posix", "man1p/eval.1p.txt"},
+	"ex":         {"github.com/sourcegraph/man-pages-posix", "man1p/ex.1p.txt"},
+	"exec":       {"github.com/sourcegraph/man-pages-posix", "man1p/exec.1p.txt"},
+	"exit":       {"github.com/sourcegraph/man-pages-posix", "man1p/exit.1p.txt"},
+	"expand":     {"github.com/sourcegraph/man-pages-posix", "man1p/expand.1p.txt"},
+	"export":     {"github.com/sourcegraph/man-pages-posix", "man1p/export.1p.txt"},
+	"expr":       {"github.com/sourcegraph/man-pages-posix", "man1p/expr.1p.txt"},
+	"false":      {"github.com/sourcegraph/man-pages-posix", "man1p/false.1p.txt"},
+	"fc":         {"github.com/sourcegraph/man-pages-posix", "man1p/fc.1p.txt"},
+	"fdisk":      {"github.com/sourcegraph/man-pages-linux", "man8/fdisk.8"},
+	"fg":         {"github.com/sourcegraph/man-pages-posix", "man1p/fg.1p.txt"},
+	"file":       {"github.com/sourcegraph/man-pages-posix", "man1p/file.1p.txt"},
+	"find":       {"github.com/sourcegraph/man-pages-posix", "man1p/find.1p.txt"},
+	"fold":       {"github.com/sourcegraph/man-pages-posix", "man1p/fold.1p.txt"},
+	"fort77":     {"github.com/sourcegraph/man-pages-posix", "man1p/fort77.1p.txt"},
+	"free":       {"github.com/sourcegraph/man-pages-linux", "man1/free.1"},
+	"fsck":       {"github.com/sourcegraph/man-pages-linux", "man8/fsck.8"},
+	"fuser":      {"github.com/sourcegraph/man-pages-posix", "man1p/fuser.1p.txt"},
+	"gencat":     {"github.com/sourcegraph/man-pages-posix", "man1p/gencat.1p.txt"},
+	"get":        {"github.com/sourcegraph/man-pages-posix", "man1p/get.1p.txt"},
+	"getconf":    {"github.com/sourcegraph/man-pages-posix", "man1p/getconf.1p.txt"},
+	"getopts":    {"github.com/sourcegraph/man-pages-posix", "man1p/getopts.1p.txt"},
+	"grep":       {"github.com/sourcegraph/man-pages-posix", "man1p/grep.1p.txt"},
+	"groupadd":   {"github.com/sourcegraph/man-pages-linux", "man8/groupadd.8"},
+	"groupdel":   {"github.com/sourcegraph/man-pages-linux", "man8/groupdel.8"},
+	"gunzip":     {"github.com/sourcegraph/man-pages-linux", "man1/gunzip.1"},
+	"gzip":       {"github.com/sourcegraph/man-pages-linux", "man1/gzip.1"},
+	"hash":       {"github.com/sourcegraph/man-pages-posix", "man1p/hash.1p.txt"},
+	"head":       {"github.com/sourcegraph/man-pages-posix", "man1p/head.1p.txt"},
+	"hostname":   {"github.com/sourcegraph/man-pages-linux", "man1/hostname.1"},
+	"iconv":      {"github.com/sourcegraph/man-pages-posix", "man1p/iconv.1p.txt"},
+	"id":         {"github.com/sourcegraph/man-pages-posix", "man1p/id.1p.txt"},
+	"ifconfig":   {"github.com/sourcegraph/man-pages-linux", "man8/ifconfig.8"},
+	"ip":         {"github.com/sourcegraph/man-pages-linux", "man8/ip.8"},
+	"ip6tables":  {"github.com/sourcegraph/man-pages-linux", "man8/ip6tables.8"},
+	"ipcrm":      {"github.com/sourcegraph/man-pages-posix", "man1p/ipcrm.1p.txt"},
+	"ipcs":       {"github.com/sourcegraph/man-pages-posix", "man1p/ipcs.1p.txt"},
+	"iptables":   {"github.com/sourcegraph/man-pages-linux", "man8/iptables.8"},
+	"jobs":       {"github.com/sourcegraph/man-pages-posix", "man1p/jobs.1p.txt"},
+	"join":       {"github.com/sourcegraph/man-pages-posix", "man1p/join.1p.txt"},
+	"journalctl": {"github.com/sourcegraph/man-pages-linux", "man1/journalctl.1"},
+	"kill":       {"github.com/sourcegraph/man-pages-posix", "man1p/kill.1p.txt"},
+	"ldconfig":   {"github.com/sourcegraph/man-pages-linux", "man8/ldconfig.8"},
+	"less":       {"github.com/sourcegraph/man-pages-linux", "man1/less.1"},
+	"lex":        {"github.com/sourcegraph/man-pages-posix", "man1p/lex.1p.txt"},
+	"link":       {"github.com/sourcegraph/man-pages-posix", "man1p/link.1p.txt"},
+	"ln":         {"github.com/sourcegraph/man-pages-posix", "man1p/ln.1p.txt"},
+	"locale":     {"github.com/sourcegraph/man-pages-posix", "man1p/locale.1p.txt"},
+	"localedef":  {"github.com/sourcegraph/man-pages-posix", "man1p/localedef.1p.txt"},
+	"logger":     {"github.com/sourcegraph/man-pages-posix", "man1p/logger.1p.txt"},
+	"logname":    {"github.com/sourcegraph/man-pages-posix", "man1p/logname.1p.txt"},
+	"losetup":    {"github.com/sourcegraph/man-pages-linux", "man8/losetup.8"},
+	"lp":         {"github.com/sourcegraph/man-pages-posix", "man1p/lp.1p.txt"},
+	"ls":         {"github.com/sourcegraph/man-pages-posix", "man1p/ls.1p.txt"},
+	"lsblk":      {"github.com/sourcegraph/man-pages-linux", "man8/lsblk.8"},
+	"lsmod":      {"github.com/sourcegraph/man-pages-linux", "man8/lsmod.8"},
+	"m4":         {"github.com/sourcegraph/man-pages-posix", "man1p/m4.1p.txt"},
+	"mailx":      {"github.com/sourcegraph/man-pages-posix", "man1p/mailx.1p.txt"},
+	"make":       {"github.com/sourcegraph/man-pages-posix", "man1p/make.1p.txt"},
+	"man":        {"github.com/sourcegraph/man-pages-posix", "man1p/man.1p.txt"},
+	"mesg":       {"github.com/sourcegraph/man-pages-posix", "man1p/mesg.1p.txt"},
+	"mkdir":      {"github.com/sourcegraph/man-pages-posix", "man1p/mkdir.1p.txt"},
+	"mkfifo":     {"github.com/sourcegraph/man-pages-posix", "man1p/mkfifo.1p.txt"},
+	"mkfs":       {"github.com/sourcegraph/man-pages-linux", "man8/mkfs.8"},
+	"mkswap":     {"github.com/sourcegraph/man-pages-linux", "man8/mkswap.8"},
+	"modprobe":   {"github.com/sourcegraph/man-pages-linux", "man8/modprobe.8"},
+	"more":       {"github.com/sourcegraph/man-pages-posix", "man1p/more.1p.txt"},
+	"mount":      {"github.com/sourcegraph/man-pages-linux", "man8/mount.8"},
+	"mv":         {"github.com/sourcegraph/man-pages-posix", "man1p/mv.1p.txt"},
+	"newgrp":     {"github.com/sourcegraph/man-pages-posix", "man1p/newgrp.1p.txt"},
+	"nice":       {"github.com/sourcegraph/man-pages-posix", "man1p/nice.1p.txt"},
+	"nl":         {"github.com/sourcegraph/man-pages-posix", "man1p/nl.1p.txt"},
+	"nm":         {"github.com/sourcegraph/man-pages-posix", "man1p/nm.1p.txt"},
+	"nohup":      {"github.com/sourcegraph/man-pages-posix", "man1p/nohup.1p.txt"},
+	"od":         {"github.com/sourcegraph/man-pages-posix", "man1p/od.1p.txt"},
+	"parted":     {"github.com/sourcegraph/man-pages-linux", "man8/parted.8"},
+	"paste":      {"github.com/sourcegraph/man-pages-posix", "man1p/paste.1p.txt"},
+	"patch":      {"github.com/sourcegraph/man-pages-posix", "man1p/patch.1p.txt"},
+	"pathchk":    {"github.com/sourcegraph/man-pages-posix", "man1p/pathchk.1p.txt"},
+	"pax":        {"github.com/sourcegraph/man-pages-posix", "man1p/pax.1p.txt"},
+	"pr":         {"github.com/sourcegraph/man-pages-posix", "man1p/pr.1p.txt"},
+	"printf":     {"github.com/sourcegraph/man-pages-posix", "man1p/printf.1p.txt"},
+	"prs":        {"github.com/sourcegraph/man-pages-posix", "man1p/prs.1p.txt"},
+	"ps":         {"github.com/sourcegraph/man-pages-posix", "man1p/ps.1p.txt"},
+	"pwd":        {"github.com/sourcegraph/man-pages-posix", "man1p/pwd.1p.txt"},
+	"qalter":     {"github.com/sourcegraph/man-pages-posix", "man1p/qalter.1p.txt"},
+	"qdel":       {"github.com/sourcegraph/man-pages-posix", "man1p/qdel.1p.txt"},
+	"qhold":      {"github.com/sourcegraph/man-pages-posix", "man1p/qhold.1p.txt"},
+	"qmove":      {"github.com/sourcegraph/man-pages-posix", "man1p/qmove.1p.txt"},
+	"qmsg":       {"github.com/sourcegraph/man-pages-posix", "man1p/qmsg.1p.txt"},
+	"qrerun":     {"github.com/sourcegraph/man-pages-posix", "man1p/qrerun.1p.txt"},
+	"qrls":       {"github.com/sourcegraph/man-pages-posix", "man1p/qrls.1p.txt"},
+	"qselect":    {"github.com/sourcegraph/man-pages-posix", "man1p/qselect.1p.txt"},
+	"qsig":       {"github.com/sourcegraph/man-pages-posix", "man1p/qsig.1p.txt"},
+	"qstat":      {"github.com/sourcegraph/man-pages-posix", "man1p/qstat.1p.txt"},
+	"qsub":       {"github.com/sourcegraph/man-pages-posix", "man1p/qsub.1p.txt"},
+	"read":       {"github.com/sourcegraph/man-pages-posix", "man1p/read.1p.txt"},
+	"readonly":   {"github.com/sourcegraph/man-pages-posix", "man1p/readonly.1p.txt"},
+	"reboot":     {"github.com/sourcegraph/man-pages-linux", "man8/reboot.8"},
+	"renice":     {"github.com/sourcegraph/man-pages-posix", "man1p/renice.1p.txt"},
+	"return":     {"github.com/sourcegraph/man-pages-posix", "man1p/return.1p.txt"},
+	"rm":         {"github.com/sourcegraph/man-pages-posix", "man1p/rm.1p.txt"},
+	"rmdel":      {"github.com/sourcegraph/man-pages-posix", "man1p/rmdel.1p.txt"},
+	"rmdir":      {"github.com/sourcegraph/man-pages-posix", "man1p/rmdir.1p.txt"},
+	"route":      {"github.com/sourcegraph/man-pages-linux", "man8/route.8"},
+	"rsync":      {"github.com/sourcegraph/man-pages-linux", "man1/rsync.1"},
+	"sact":       {"github.com/sourcegraph/man-pages-posix", "man1p/sact.1p.txt"},
+	"sccs":       {"github.com/sourcegraph/man-pages-posix", "man1p/sccs.1p.txt"},
+	"scp":        {"github.com/sourcegraph/man-pages-linux", "man1/scp.1"},
+	"sed":        {"github.com/sourcegraph/man-pages-posix", "man1p/sed.1p.txt"},
+	"service":    {"github.com/sourcegraph/man-pages-linux", "man8/service.8"},
+	"set":        {"github.com/sourcegraph/man-pages-posix", "man1p/set.1p.txt"},
+	"sh":         {"github.com/sourcegraph/man-pages-posix", "man1p/sh.1p.txt"},
+	"shift":      {"github.com/sourcegraph/man-pages-posix", "man1p/shift.1p.txt"},
+	"shutdown":   {"github.com/sourcegraph/man-pages-linux", "man8/shutdown.8"},
+	"sleep":      {"github.com/sourcegraph/man-pages-posix", "man1p/sleep.1p.txt"},
+	"sort":       {"github.com/sourcegraph/man-pages-posix", "man1p/sort.1p.txt"},
+	"split":      {"github.com/sourcegraph/man-pages-posix", "man1p/split.1p.txt"},
+	"ss":         {"github.com/sourcegraph/man-pages-linux", "man8/ss.8"},
+	"ssh":        {"github.com/sourcegraph/man-pages-linux", "man1/ssh.1"},
+	"strings":    {"github.com/sourcegraph/man-pages-posix", "man1p/strings.1p.txt"},
+	"strip":      {"github.com/sourcegraph/man-pages-posix", "man1p/strip.1p.txt"},
+	"stty":       {"github.com/sourcegraph/man-pages-posix", "man1p/stty.1p.txt"},
+	"sudo":       {"github.com/sourcegraph/man-pages-linux", "man8/sudo.8"},
+	"swapon":     {"github.com/sourcegraph/man-pages-linux", "man8/swapon.8"},
+	"sysctl":     {"github.com/sourcegraph/man-pages-linux", "man8/sysctl.8"},
+	"systemctl":  {"github.com/sourcegraph/man-pages-linux", "man1/systemctl.1"},
+	"tabs":       {"github.com/sourcegraph/man-pages-posix", "man1p/tabs.1p.txt"},
+	"tail":       {"github.com/sourcegraph/man-pages-posix", "man1p/tail.1p.txt"},
+	"talk":       {"github.com/sourcegraph/man-pages-posix", "man1p/talk.1p.txt"},
+	"tar":        {"github.com/sourcegraph/man-pages-linux", "man1/tar.1"},
+	"tee":        {"github.com/sourcegraph/man-pages-posix", "man1p/tee.1p.txt"},
+	"test":       {"github.com/sourcegraph/man-pages-posix", "man1p/test.1p.txt"},
+	"time":       {"github.com/sourcegraph/man-pages-posix", "man1p/time.1p.txt"},
+	"times":      {"github.com/sourcegraph/man-pages-posix", "man1p/times.1p.txt"},
+	"touch":      {"github.com/sourcegraph/man-pages-posix", "man1p/touch.1p.txt"},
+	"tput":       {"github.com/sourcegraph/man-pages-posix", "man1p/tput.1p.txt"},
+	"tr":         {"github.com/sourcegraph/man-pages-posix", "man1p/tr.1p.txt"},
+	"trap":       {"github.com/sourcegraph/man-pages-posix", "man1p/trap.1p.txt"},
+	"true":       {"github.com/sourcegraph/man-pages-posix", "man1p/true.1p.txt"},
+	"tsort":      {"github.com/sourcegraph/man-pages-posix", "man1p/tsort.1p.txt"},
+	"tty":        {"github.com/sourcegraph/man-pages-posix", "man1p/tty.1p.txt"},
+	"type":       {"github.com/sourcegraph/man-pages-posix", "man1p/type.1p.txt"},
+	"ulimit":     {"github.com/sourcegraph/man-pages-posix", "man1p/ulimit.1p.txt"},
+	"umask":      {"github.com/sourcegraph/man-pages-posix", "man1p/umask.1p.txt"},
+	"umount":     {"github.com/sourcegraph/man-pages-linux", "man8/umount.8"},
+	"unalias":    {"github.com/sourcegraph/man-pages-posix", "man1p/unalias.1p.txt"},
+	"uname":      {"github.com/sourcegraph/man-pages-posix", "man1p/uname.1p.txt"},
+	"uncompress": {"github.com/sourcegraph/man-pages-posix", "man1p/uncompress.1p.txt"},
+	"unexpand":   {"github.com/sourcegraph/man-pages-posix", "man1p/unexpand.1p.txt"},
+	"unget":      {"github.com/sourcegraph/man-pages-posix", "man1p/unget.1p.txt"},
+	"uniq":       {"github.com/sourcegraph/man-pages-posix", "man1p/uniq.1p.txt"},
+	"unlink":     {"github.com/sourcegraph/man-pages-posix", "man1p/unlink.1p.txt"},
+	"unset":      {"github.com/sourcegraph/man-pages-posix", "man1p/unset.1p.txt"},
+	"unzip":      {"github.com/sourcegraph/man-pages-linux", "man1/unzip.1"},
+	"useradd":    {"github.com/sourcegraph/man-pages-linux", "man8/useradd.8"},
+	"userdel":    {"github.com/sourcegraph/man-pages-linux", "man8/userdel.8"},
+	"usermod":    {"github.com/sourcegraph/man-pages-linux", "man8/usermod.8"},
+	"uucp":       {"github.com/sourcegraph/man-pages-posix", "man1p/uucp.1p.txt"},
+	"uudecode":   {"github.com/sourcegraph/man-pages-posix", "man1p/uudecode.1p.txt"},
+	"uuencode":   {"github.com/sourcegraph/man-pages-posix", "man1p/uuencode.1p.txt"},
+	"uustat":     {"github.com/sourcegraph/man-pages-posix", "man1p/uustat.1p.txt"},
+	"uux":        {"github.com/sourcegraph/man-pages-posix", "man1p/uux.1p.txt"},
+	"val":        {"github.com/sourcegraph/man-pages-posix", "man1p/val.1p.txt"},
+	"vi":         {"github.com/sourcegraph/man-pages-posix", "man1p/vi.1p.txt"},
+	"wait":       {"github.com/sourcegraph/man-pages-posix", "man1p/wait.1p.txt"},
+	"watch":      {"github.com/sourcegraph/man-pages-linux", "man1/watch.1"},
+	"wc":         {"github.com/sourcegraph/man-pages-posix", "man1p/wc.1p.txt"},
+	"wget":       {"github.com/sourcegraph/man-pages-linux", "man1/wget.1"},
+	"what":       {"github.com/sourcegraph/man-pages-posix", "man1p/what.1p.txt"},
+	"which":      {"github.com/sourcegraph/man-pages-linux", "man1/which.1"},
+	"who":        {"github.com/sourcegraph/man-pages-posix", "man1p/who.1p.txt"},
+	"write":      {"github.com/sourcegraph/man-pages-posix", "man1p/write.1p.txt"},
+	"xargs":      {"github.com/sourcegraph/man-pages-posix", "man1p/xargs.1p.txt"},
+	"yacc":       {"github.com/sourcegraph/man-pages-posix", "man1p/yacc.1p.txt"},
+	"zcat":       {"github.com/sourcegraph/man-pages-posix", "man1p/zcat.1p.txt"},
+	"zip":        {"github.com/sourcegraph/man-pages-linux", "man1/zip.1"},
+}
@@ -0,0 +1,144 @@
+// Code generated by gen_mansummaries.go from the pages in /usr/share/man of the commands manpages.txt lists; DO NOT EDIT.
+
+package bashgraph
+
+// manSummaries maps command names to the descriptions and synopses in
+// their man pages. They were read from the pages installed in /usr/share/man,
+// not from the pages refs link to, so they may describe another
+// implementation of a command, such as GNU's.
+var manSummaries = map[string]manSummary{
+	"ar":         {"create, modify, and extract from archives", ""},
+	"awk":        {"pattern scanning and text processing language", ""},
+	"basename":   {"strip directory and suffix from filenames", "basename NAME [SUFFIX]"},
+	"bash":       {"GNU Bourne-Again SHell", "bash [options] [command_string | file]"},
+	"blkid":      {"locate/print block device attributes", "blkid --label label | --uuid uuid"},
+	"c99":        {"ANSI (1999) C compiler", "c99 [-pedantic] [-pedantic-errors] [-D_ANSI_SOURCE] options ..."},
+	"cat":        {"concatenate files and print on the standard output", "cat [OPTION]... [FILE]..."},
+	"chgrp":      {"change group ownership", "chgrp [OPTION]... GROUP FILE..."},
+	"chmod":      {"change file mode bits", "chmod [OPTION]... MODE[,MODE]... FILE..."},
+	"chown":      {"change file owner and group", "chown [OPTION]... [OWNER][:[GROUP]] FILE..."},
+	"chroot":     {"run command or interactive shell with special root directory", "chroot [OPTION] NEWROOT [COMMAND [ARG]...]"},
+	"cksum":      {"compute and verify file checksums", "cksum [OPTION]... [FILE]..."},
+	"cmp":        {"compare two files byte by byte", "cmp [OPTION]... FILE1 [FILE2 [SKIP1 [SKIP2]]]"},
+	"comm":       {"compare two sorted files line by line", "comm [OPTION]... FILE1 FILE2"},
+	"cp":         {"copy files and directories", "cp [OPTION]... [-T] SOURCE DEST"},
+	"csplit":     {"split a file into sections determined by context lines", "csplit [OPTION]... FILE PATTERN..."},
+	"curl":       {"transfer a URL", "curl [options / URLs]"},
+	"cut":        {"remove sections from each line of files", "cut OPTION... [FILE]..."},
+	"date":       {"print or set the system date and time", "date [OPTION]... [+FORMAT]"},
+	"dd":         {"convert and copy a file", "dd [OPERAND]..."},
+	"df":         {"report file system space usage", "df [OPTION]... [FILE]..."},
+	"diff":       {"compare files line by line", "diff [OPTION]... FILES"},
+	"dirname":    {"strip last component from file name", "dirname [OPTION] NAME..."},
+	"du":         {"estimate file space usage", "du [OPTION]... [FILE]..."},
+	"echo":       {"display a line of text", "echo [SHORT-OPTION]... [STRING]..."},
+	"env":        {"run a program in a modified environment", "env [OPTION]... [-] [NAME=VALUE]... [COMMAND [ARG]...]"},
+	"ex":         {"Vi IMproved, a programmer's text editor", ""},
+	"expand":     {"convert tabs to spaces", "expand [OPTION]... [FILE]..."},
+	"expr":       {"evaluate expressions", "expr EXPRESSION"},
+	"false":      {"do nothing, unsuccessfully", "false [ignored command line arguments]"},
+	"find":       {"search for files in a directory hierarchy", "find [-H] [-L] [-P] [-D debugopts] [-Olevel] [starting-point...] [expression]"},
+	"fold":       {"wrap each input line to fit in specified width", "fold [OPTION]... [FILE]..."},
+	"free":       {"Display amount of free and used memory in the system", "free [options]"},
+	"fsck":       {"check and repair a Linux filesystem", "fsck [-lsAVRTMNP] [-r [fd]] [-C [fd]] [-t fstype] [filesystem...] [--] [fs-specific-options]"},
+	"fuser":      {"identify processes using files or sockets", ""},
+	"gencat":     {"Generate message catalog", "gencat [OPTION...] -o OUTPUT-FILE [INPUT-FILE]..."},
+	"getconf":    {"Query system configuration variables", "getconf -a"},
+	"grep":       {"print lines that match patterns", "grep [OPTION...] PATTERNS [FILE...]"},
+	"groupadd":   {"create a new group", "groupadd [OPTIONS] NEWGROUP"},
+	"groupdel":   {"delete a group", "groupdel [options] GROUP"},
+	"gunzip":     {"compress or expand files", ""},
+	"gzip":       {"compress or expand files", ""},
+	"head":       {"output the first part of files", "head [OPTION]... [FILE]..."},
+	"hostname":   {"show or set the system's host name", ""},
+	"iconv":      {"convert text from one character encoding to another", "iconv [options] [-f from-encoding] [-t to-encoding] [inputfile]..."},
+	"id":         {"print real and effective user and group IDs", "id [OPTION]... [USER]..."},
+	"ifconfig":   {"configure a network interface", "ifconfig [-v] [-a] [-s] [interface]"},
+	"ip":         {"show / manipulate routing, network devices, interfaces and tunnels", "ip [ OPTIONS ] OBJECT { COMMAND | help }"},
+	"ipcrm":      {"remove certain IPC resources", "ipcrm [options]"},
+	"ipcs":       {"show information on IPC facilities", "ipcs [options]"},
+	"join":       {"join lines of two files on a common field", "join [OPTION]... FILE1 FILE2"},
+	"journalctl": {"Query the systemd journal", "journalctl [OPTIONS...] [MATCHES...]"},
+	"kill":       {"send a signal to a process", "kill [options] <pid> [...]"},
+	"ldconfig":   {"configure dynamic linker run-time bindings", ""},
+	"less":       {"opposite of more", "less -?"},
+	"link":       {"call the link function to create a link to a file", "link FILE1 FILE2"},
+	"ln":         {"make links between files", "ln [OPTION]... [-T] TARGET LINK_NAME"},
+	"locale":     {"get locale-specific information", "locale [option] locale [option] -a locale [option] -m locale [option] name..."},
+	"localedef":  {"compile locale definition files", ""},
+	"logger":     {"enter messages into the system log", "logger [options] message"},
+	"logname":    {"print user's login name", "logname [OPTION]"},
+	"losetup":    {"set up and control loop devices", ""},
+	"ls":         {"list directory contents", "ls [OPTION]... [FILE]..."},
+	"lsblk":      {"list block devices", "lsblk [options] [device...]"},
+	"make":       {"GNU make utility to maintain groups of programs", "make [OPTION]... [TARGET]..."},
+	"mesg":       {"display (or do not display) messages from other users", "mesg [option] [n|y]"},
+	"mkdir":      {"make directories", "mkdir [OPTION]... DIRECTORY..."},
+	"mkfifo":     {"make FIFOs (named pipes)", "mkfifo [OPTION]... NAME..."},
+	"mkfs":       {"build a Linux filesystem", "mkfs [options] [-t type] [fs-options] device [size]"},
+	"mkswap":     {"set up a Linux swap area", "mkswap [options] device [size]"},
+	"more":       {"file perusal filter for crt viewing", "more [options] file ..."},
+	"mount":      {"mount a filesystem", "mount [-h|-V]"},
+	"mv":         {"move (rename) files", "mv [OPTION]... [-T] SOURCE DEST"},
+	"newgrp":     {"log in to a new group", "newgrp [-] [group]"},
+	"nice":       {"run a program with modified scheduling priority", "nice [OPTION] [COMMAND [ARG]...]"},
+	"nl":         {"number lines of files", "nl [OPTION]... [FILE]..."},
+	"nm":         {"list symbols from object files", ""},
+	"nohup":      {"run a command immune to hangups, with output to a non-tty", "nohup COMMAND [ARG]..."},
+	"od":         {"dump files in octal and other formats", "od [OPTION]... [FILE]..."},
+	"paste":      {"merge lines of files", "paste [OPTION]... [FILE]..."},
+	"patch":      {"apply a diff file to an original", "patch [options] [originalfile [patchfile]] but usually just patch -pnum <patchfile"},
+	"pathchk":    {"check whether file names are valid or portable", "pathchk [OPTION]... NAME..."},
+	"pr":         {"convert text files for printing", "pr [OPTION]... [FILE]..."},
+	"printf":     {"format and print data", "printf FORMAT [ARGUMENT]..."},
+	"ps":         {"report a snapshot of the current processes", "ps [options]"},
+	"pwd":        {"print name of current/working directory", "pwd [OPTION]..."},
+	"reboot":     {"Halt, power-off or reboot the machine", ""},
+	"renice":     {"alter priority of running processes", "renice [-n] priority [-g|-p|-u] identifier..."},
+	"rm":         {"remove files or directories", "rm [OPTION]... [FILE]..."},
+	"rmdir":      {"remove empty directories", "rmdir [OPTION]... DIRECTORY..."},
+	"route":      {"show / manipulate the IP routing table", "route [-CFvnNee] [-A family |-4|-6]"},
+	"sed":        {"stream editor for filtering and transforming text", ""},
+	"service":    {"run a System V init script", "service SCRIPT COMMAND [OPTIONS]"},
+	"shutdown":   {"Halt, power off or reboot the machine", "shutdown [OPTIONS...] [TIME] [WALL...]"},
+	"sleep":      {"delay for a specified amount of time", "sleep NUMBER[SUFFIX]..."},
+	"sort":       {"sort lines of text files", "sort [OPTION]... [FILE]..."},
+	"split":      {"split a file into pieces", "split [OPTION]... [FILE [PREFIX]]"},
+	"ss":         {"another utility to investigate sockets", "ss [options] [ FILTER ]"},
+	"strings":    {"print the sequences of printable characters in files", ""},
+	"strip":      {"discard symbols and other data from object files", ""},
+	"stty":       {"change and print terminal line settings", "stty [-F DEVICE | --file=DEVICE] [SETTING]..."},
+	"swapon":     {"enable/disable devices and files for paging and swapping", "swapon [options] [specialfile...]"},
+	"sysctl":     {"configure kernel parameters at runtime", "sysctl [options] [variable[=value]] [...]"},
+	"systemctl":  {"Control the systemd system and service manager", "systemctl [OPTIONS...] COMMAND [UNIT...]"},
+	"tabs":       {"set tabs on a terminal", "tabs [options]] [tabstop-list]"},
+	"tail":       {"output the last part of files", "tail [OPTION]... [FILE]..."},
+	"tar":        {"an archiving utility", "tar {A|c|d|r|t|u|x} [GnSkUWOmpsMBiajJzZhPlRvwo] [ARG...]"},
+	"tee":        {"read from standard input and write to standard output and files", "tee [OPTION]... [FILE]..."},
+	"test":       {"check file types and compare values", "test EXPRESSION"},
+	"touch":      {"change file timestamps", "touch [OPTION]... FILE..."},
+	"tput":       {"initialize a terminal or query terminfo database", "tput [-Ttype] capname [parameters]"},
+	"tr":         {"translate or delete characters", "tr [OPTION]... STRING1 [STRING2]"},
+	"true":       {"do nothing, successfully", "true [ignored command line arguments]"},
+	"tsort":      {"perform topological sort", "tsort [OPTION] [FILE]"},
+	"tty":        {"print the file name of the terminal connected to standard input", "tty [OPTION]..."},
+	"umount":     {"unmount filesystems", "umount -a [-dflnrv] [-t fstype] [-O option...]"},
+	"uname":      {"print system information", "uname [OPTION]..."},
+	"uncompress": {"compress or expand files", ""},
+	"unexpand":   {"convert spaces to tabs", "unexpand [OPTION]... [FILE]..."},
+	"uniq":       {"report or omit repeated lines", "uniq [OPTION]... [INPUT [OUTPUT]]"},
+	"unlink":     {"call the unlink function to remove the specified file", "unlink FILE"},
+	"unzip":      {"list, test and extract compressed files in a ZIP archive", "unzip [-Z] [-cflptTuvz[abjnoqsCDKLMUVWX$/:^]] file[.zip] [file(s) ...] [-x xfile(s) ...] [-d exdir]"},
+	"useradd":    {"create a new user or update default new user information", "useradd [options] LOGIN"},
+	"userdel":    {"delete a user account and related files", "userdel [options] LOGIN"},
+	"usermod":    {"modify a user account", "usermod [options] LOGIN"},
+	"vi":         {"Vi IMproved, a programmer's text editor", ""},
+	"watch":      {"execute a program periodically, showing output fullscreen", "watch [options] command"},
+	"wc":         {"print newline, word, and byte counts for each file", "wc [OPTION]... [FILE]..."},
+	"wget":       {"The non-interactive network downloader", ""},
+	"which":      {"locate a command", "which [-a] filename ..."},
+	"who":        {"show who is logged on", "who [OPTION]... [ FILE | ARG1 ARG2 ]"},
+	"xargs":      {"build and execute command lines from standard input", "xargs [options] [command [initial-arguments]]"},
+	"zcat":       {"compress or expand files", ""},
+	"zip":        {"package and compress (archive) files", ""},
+}
@@ -0,0 +1,159 @@
+package bashgraph
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"sourcegraph.com/sourcegraph/srclib/unit"
+)
+
+type MetricsCmd struct{}
+
+// FileMetrics are the metrics of a file.
+type FileMetrics struct {
+	File      string
+	Lines     int
+	Functions int
+	// LongestFunction is the name of the function with the most lines.
+	LongestFunction      string `json:",omitempty"`
+	LongestFunctionLines int    `json:",omitempty"`
+	// ExternalCommands is the number of distinct external programs the
+	// file runs, named in ExternalCommandNames.
+	ExternalCommands     int
+	ExternalCommandNames []string           `json:",omitempty"`
+	FunctionMetrics      []*FunctionMetrics `json:",omitempty"`
+}
+
+// FunctionMetrics are the metrics of a function.
+type FunctionMetrics struct {
+	Name                 string
+	StartLine            int
+	EndLine              int
+	Lines                int
+	Complexity           int
+	ExternalCommands     int
+	ExternalCommandNames []string `json:",omitempty"`
+}
+
+func (c *MetricsCmd) Execute(args []string) error {
+	units, err := readSourceUnits()
+	if err != nil {
+		return err
+	}
+
+	metrics, err := unitsMetrics(units, commandOptions())
+	if err != nil {
+		return fmt.Errorf("Failed to compute metrics: %s", err)
+	}
+
+	bytes, err := json.MarshalIndent(metrics, "", "  ")
+	if err != nil {
+		return fmt.Errorf("Failed to marshal metrics: %s", err)
+	}
+	if _, err := os.Stdout.Write(bytes); err != nil {
+		return fmt.Errorf("Failed to output metrics: %s", err)
+	}
+	return nil
+}
+
+func unitsMetrics(units unit.SourceUnits, opts *Options) ([]*FileMetrics, error) {
+	var files []*parsedFile
+	funcs := map[string]bool{}
+	for _, u := range units {
+		for _, name := range u.Files {
+			f, err := parseFile(name, opts)
+			if err != nil {
+				return nil, err
+			}
+			files = append(files, f)
+			for _, s := range f.scripts {
+				for _, fn := range s.functions {
+					funcs[fn.name] = true
+				}
+			}
+		}
+	}
+
+	var metrics []*FileMetrics
+	for _, f := range files {
+		metrics = append(metrics, fileMetrics(f, funcs))
+	}
+	return metrics, nil
+}
+
+// fileMetrics computes the metrics of f. funcs holds the names of all
+// functions in the unit, which are not external commands.
+func fileMetrics(f *parsedFile, funcs map[string]bool) *FileMetrics {
+	li := newLineIndex(f.data)
+	m := &FileMetrics{File: f.name, Lines: len(li.start)}
+	if len(f.data) > 0 && f.data[len(f.data)-1] == '\n' {
+		m.Lines--
+	}
+
+	external := map[string]bool{}
+	for i, s := range f.scripts {
+		src := f.sources[i]
+		for _, cmd := range s.commands {
+			if name, ok := externalCommand(f, cmd, funcs); ok {
+				external[name] = true
+			}
+		}
+		for _, fn := range s.functions {
+			startLine, _ := li.position(src.fileOffset(fn.start))
+			endLine, _ := li.position(src.fileEnd(fn.end))
+			fm := &FunctionMetrics{
+				Name:       fn.name,
+				StartLine:  startLine,
+				EndLine:    endLine,
+				Lines:      endLine - startLine + 1,
+				Complexity: fn.complexity,
+			}
+			fnExternal := map[string]bool{}
+			for _, cmd := range s.commands {
+				if cmd.start < fn.start || cmd.end > fn.end {
+					continue
+				}
+				if name, ok := externalCommand(f, cmd, funcs); ok {
+					fnExternal[name] = true
+				}
+			}
+			fm.ExternalCommandNames = sortedKeys(fnExternal)
+			fm.ExternalCommands = len(fm.ExternalCommandNames)
+			m.FunctionMetrics = append(m.FunctionMetrics, fm)
+			if fm.Lines > m.LongestFunctionLines {
+				m.LongestFunction = fm.Name
+				m.LongestFunctionLines = fm.Lines
+			}
+		}
+	}
+	m.Functions = len(m.FunctionMetrics)
+	m.ExternalCommandNames = sortedKeys(external)
+	m.ExternalCommands = len(m.ExternalCommandNames)
+	return m
+}
+
+// externalCommand returns the name of the program that cmd, a command in f,
+// runs, if it is not a builtin, a function in funcs, or computed at run
+// time.
+func externalCommand(f *parsedFile, cmd word, funcs map[string]bool) (string, bool) {
+	if strings.ContainsAny(cmd.text, "$`") {
+		return "", false
+	}
+	name := unquote(cmd.text)
+	if name == "" || isBuiltin(f, name) || funcs[name] {
+		return "", false
+	}
+	return name, true
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,257 @@
+package bashgraph
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"sourcegraph.com/sourcegraph/srclib/unit"
+)
+
+type NetworkCmd struct {
+	JSON bool `long:"json" description:"output the report as JSON"`
+}
+
+// A NetworkUse is how a command reaches the network.
+type NetworkUse struct {
+	// Class is the kind of access: "http" for curl and wget, "ssh" for
+	// ssh, scp, sftp and rsync, "socket" for nc and the like, and "dns"
+	// for dig, nslookup and host.
+	Class string
+	// Endpoints are the literal URLs and hosts given to the command.
+	Endpoints []string `json:",omitempty"`
+}
+
+// A NetworkScript is a file that runs commands that reach the network.
+type NetworkScript struct {
+	File     string
+	Commands []*NetworkOp
+}
+
+// A NetworkOp is a command that reaches the network.
+type NetworkOp struct {
+	Command string
+	*NetworkUse
+	// Start and End are the byte offsets of the command's name in the file.
+	Start uint32
+	End   uint32
+	Line  int
+}
+
+// networkClasses are the classes of the commands that reach the network.
+var networkClasses = map[string]string{
+	"curl": "http", "wget": "http",
+	"ssh": "ssh", "scp": "ssh", "sftp": "ssh", "rsync": "ssh",
+	"nc": "socket", "ncat": "socket", "netcat": "socket", "socat": "socket", "telnet": "socket",
+	"dig": "dns", "nslookup": "dns", "host": "dns",
+}
+
+// ncOptsWithArg are the options of nc and the like that take an argument.
+var ncOptsWithArg = map[string]bool{
+	"-p": true, "-s": true, "-w": true, "-i": true, "-q": true, "-x": true, "-X": true, "-e": true, "-c": true,
+}
+
+func (c *NetworkCmd) Execute(args []string) error {
+	units, err := readSourceUnits()
+	if err != nil {
+		return err
+	}
+
+	scripts := networkScripts(units, commandOptions())
+	if c.JSON {
+		if err := json.NewEncoder(os.Stdout).Encode(scripts); err != nil {
+			return fmt.Errorf("Failed to output network activity: %s", err)
+		}
+		return nil
+	}
+
+	files := 0
+	for _, u := range units {
+		files += len(u.Files)
+	}
+	for _, s := range scripts {
+		for _, op := range s.Commands {
+			fmt.Println(strings.Join(append([]string{fmt.Sprintf("%s:%d: %s (%s)", s.File, op.Line, op.Command, op.Class)}, op.Endpoints...), " "))
+		}
+	}
+	fmt.Printf("%d of %d files reach the network\n", len(scripts), files)
+	return nil
+}
+
+// networkScripts returns the files of units that run commands that reach
+// the network, sorted by name.
+func networkScripts(units unit.SourceUnits, opts *Options) []*NetworkScript {
+	var scripts []*NetworkScript
+	for _, u := range units {
+		for _, f := range parseUnit(u, opts) {
+			ops := networkOps(f)
+			if len(ops) == 0 {
+				continue
+			}
+			li := newLineIndex(f.data)
+			for _, op := range ops {
+				op.Line, _ = li.position(int(op.Start))
+			}
+			scripts = append(scripts, &NetworkScript{File: f.name, Commands: ops})
+		}
+	}
+	sort.Sort(networkScriptsByFile(scripts))
+	return scripts
+}
+
+type networkScriptsByFile []*NetworkScript
+
+func (ns networkScriptsByFile) Len() int           { return len(ns) }
+func (ns networkScriptsByFile) Swap(i, j int)      { ns[i], ns[j] = ns[j], ns[i] }
+func (ns networkScriptsByFile) Less(i, j int) bool { return ns[i].File < ns[j].File }
+
+// networkOps returns the commands in f that reach the network, in the
+// order they appear. Line is not set.
+func networkOps(f *parsedFile) []*NetworkOp {
+	var ops []*NetworkOp
+	for i, s := range f.scripts {
+		src := f.sources[i]
+		for _, cmd := range s.commands {
+			if use := networkUse(s.words, cmd); use != nil {
+				ops = append(ops, &NetworkOp{
+					Command:    unquote(cmd.text),
+					NetworkUse: use,
+					Start:      uint32(src.fileOffset(cmd.start)),
+					End:        uint32(src.fileEnd(cmd.end)),
+				})
+			}
+		}
+	}
+	return ops
+}
+
+// networkUse returns how the command named by cmd reaches the network, or
+// nil if it doesn't. scp and rsync only do if they are given a remote
+// location, as in scp app.tgz deploy@web1:/srv.
+func networkUse(words []word, cmd word) *NetworkUse {
+	name := unquote(cmd.text)
+	class, ok := networkClasses[name]
+	if !ok {
+		return nil
+	}
+	use := &NetworkUse{Class: class}
+	seen := map[string]bool{}
+	add := func(endpoint string) {
+		if endpoint != "" && !seen[endpoint] {
+			seen[endpoint] = true
+			use.Endpoints = append(use.Endpoints, endpoint)
+		}
+	}
+
+	args := commandArgs(words, cmd)
+	remote := false
+	for i, a := range args {
+		if name == "scp" || name == "rsync" {
+			// A location is remote even if its host is expanded, as in
+			// deploy@$host:/srv.
+			remote = remote || remoteHost(unquote(a.text)) != "" || isURL(unquote(a.text))
+		}
+		if strings.ContainsAny(a.text, "$`") {
+			continue
+		}
+		arg := unquote(a.text)
+		if isURL(arg) {
+			add(arg)
+			continue
+		}
+		switch name {
+		case "ssh", "sftp":
+			if i > 0 && isSSHOptWithArg(unquote(args[i-1].text)) || strings.HasPrefix(arg, "-") {
+				continue
+			}
+			add(stripUser(arg))
+			return use
+		case "scp", "rsync":
+			if host := remoteHost(arg); host != "" {
+				add(host)
+			}
+		case "nc", "ncat", "netcat", "telnet":
+			if i > 0 && ncOptsWithArg[unquote(args[i-1].text)] || strings.HasPrefix(arg, "-") || isNumber(arg) {
+				continue
+			}
+			add(arg)
+			return use
+		case "dig", "nslookup", "host":
+			arg = strings.TrimPrefix(arg, "@")
+			if !strings.HasPrefix(arg, "-") && !strings.HasPrefix(arg, "+") && isHostName(arg) {
+				add(arg)
+			}
+		}
+	}
+	if (name == "scp" || name == "rsync") && !remote {
+		return nil
+	}
+	return use
+}
+
+// isURL reports whether s is a URL with a network scheme.
+func isURL(s string) bool {
+	for _, scheme := range []string{"https://", "http://", "ftp://", "ftps://", "rsync://", "ssh://", "sftp://"} {
+		if strings.HasPrefix(s, scheme) {
+			return true
+		}
+	}
+	return false
+}
+
+// isSSHOptWithArg reports whether opt is an option of ssh that takes an
+// argument.
+func isSSHOptWithArg(opt string) bool {
+	return len(opt) == 2 && opt[0] == '-' && strings.IndexByte(sshOptsWithArg, opt[1]) >= 0
+}
+
+// remoteHost returns the host of a remote location given to scp or rsync,
+// such as web1 in deploy@web1:/srv, or "" if loc is a local path.
+func remoteHost(loc string) string {
+	i := strings.IndexByte(loc, ':')
+	if i <= 0 || strings.Contains(loc[:i], "/") {
+		return ""
+	}
+	return stripUser(loc[:i])
+}
+
+// stripUser returns host without the user in user@host.
+func stripUser(host string) string {
+	if i := strings.LastIndexByte(host, '@'); i >= 0 {
+		return host[i+1:]
+	}
+	return host
+}
+
+// isHostName reports whether s looks like a host name or address, rather
+// than an option value such as a record type.
+func isHostName(s string) bool {
+	return s == "localhost" || strings.ContainsAny(s, ".:")
+}
+
+// isNumber reports whether s is made of digits, such as a port.
+func isNumber(s string) bool {
+	return s != "" && strings.Trim(s, "0123456789") == ""
+}
+
+// emitNetwork records how each ref graphed from f that is from a command
+// that reaches the network does.
+func emitNetwork(f *parsedFile, idx *unitIndex, output *graphOutput) error {
+	ops := networkOps(f)
+	if len(ops) == 0 {
+		return nil
+	}
+	refs := output.fileRefs()
+	uses := map[[2]uint32]*NetworkUse{}
+	for _, op := range ops {
+		uses[[2]uint32{op.Start, op.End}] = op.NetworkUse
+	}
+	for _, ref := range refs {
+		if use, ok := uses[[2]uint32{ref.Start, ref.End}]; ok && ref.File == f.name && !ref.Def {
+			output.network[ref] = use
+		}
+	}
+	return nil
+}
@@ -0,0 +1,461 @@
+package bashgraph
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"sourcegraph.com/sourcegraph/srclib/graph"
+)
+
+// An npmScript is a script in the scripts section of a package.json file.
+type npmScript struct {
+	name string
+	// start and end are the offsets of the script's name in the file.
+	start, end int
+	// src is the command the script runs.
+	src *source
+}
+
+// An npmPackage is what a package.json file declares about the commands
+// its scripts run. Scripts whose values aren't strings, which npm can't
+// run, are left out.
+type npmPackage struct {
+	// file is the package.json file, once the package is indexed.
+	file    *parsedFile
+	scripts []*npmScript
+	// bins maps the names of the commands in the package's bin entry to
+	// the files they run, relative to the package's directory.
+	bins map[string]string
+}
+
+// parseNPMPackage parses the scripts and bin entries of a package.json
+// file.
+func parseNPMPackage(data []byte) (*npmPackage, error) {
+	pkg := &npmPackage{bins: map[string]string{}}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if tok, err := dec.Token(); err != nil {
+		return nil, err
+	} else if tok != json.Delim('{') {
+		// Not an object, so there are no scripts.
+		return pkg, nil
+	}
+
+	var name, bin string
+	for dec.More() {
+		key, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		switch key {
+		case "name":
+			var v interface{}
+			if err := dec.Decode(&v); err != nil {
+				return nil, err
+			}
+			name, _ = v.(string)
+			continue
+		case "bin":
+			var v interface{}
+			if err := dec.Decode(&v); err != nil {
+				return nil, err
+			}
+			switch v := v.(type) {
+			case string:
+				bin = v
+			case map[string]interface{}:
+				for cmd, file := range v {
+					if file, ok := file.(string); ok {
+						pkg.bins[cmd] = file
+					}
+				}
+			}
+			continue
+		case "scripts":
+		default:
+			var skip json.RawMessage
+			if err := dec.Decode(&skip); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		if tok, err := dec.Token(); err != nil {
+			return nil, err
+		} else if tok != json.Delim('{') {
+			return nil, fmt.Errorf("scripts is not an object")
+		}
+		for dec.More() {
+			// Skip to the start of the name.
+			start := int(dec.InputOffset())
+			for start < len(data) && (data[start] == ',' || isJSONSpace(data[start])) {
+				start++
+			}
+			tok, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			script := &npmScript{name: tok.(string), start: start + 1, end: int(dec.InputOffset()) - 1}
+
+			// Skip to the start of the value.
+			start = int(dec.InputOffset())
+			for start < len(data) && (data[start] == ':' || isJSONSpace(data[start])) {
+				start++
+			}
+			if start >= len(data) || data[start] != '"' {
+				var skip json.RawMessage
+				if err := dec.Decode(&skip); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			if _, err := dec.Token(); err != nil {
+				return nil, err
+			}
+			if script.src, err = jsonStringSource(data, start); err != nil {
+				return nil, err
+			}
+			pkg.scripts = append(pkg.scripts, script)
+		}
+		if _, err := dec.Token(); err != nil {
+			return nil, err
+		}
+	}
+	if bin != "" && name != "" {
+		// A single bin is named after the package, without its scope.
+		pkg.bins[path.Base(name)] = bin
+	}
+	return pkg, nil
+}
+
+// npmScriptSources returns the commands in the scripts section of a
+// package.json file as shell sources, one per script.
+func npmScriptSources(data []byte) ([]*source, error) {
+	pkg, err := parseNPMPackage(data)
+	if err != nil {
+		return nil, err
+	}
+	var sources []*source
+	for _, script := range pkg.scripts {
+		sources = append(sources, script.src)
+	}
+	return sources, nil
+}
+
+// script returns the script of pkg with the given name, or nil if there is
+// none. As with other JSON keys, the last of several scripts of the same
+// name is the one that is run.
+func (pkg *npmPackage) script(name string) *npmScript {
+	var found *npmScript
+	for _, s := range pkg.scripts {
+		if s.name == name {
+			found = s
+		}
+	}
+	return found
+}
+
+// bin returns the file that the command name, run by one of the scripts of
+// pkg, runs if it is a local command: one installed in the package's
+// node_modules/.bin, which npm puts first on the PATH of scripts, or one of
+// the package's own bin entries. It returns "" for other commands.
+func (pkg *npmPackage) bin(name string) string {
+	if name == "" || strings.Contains(name, "/") {
+		return ""
+	}
+	dir := filepath.Dir(pkg.file.name)
+	p := filepath.Join(dir, "node_modules", ".bin", name)
+	if info, err := os.Stat(p); err == nil && !info.IsDir() {
+		return p
+	}
+	if file, ok := pkg.bins[name]; ok {
+		return filepath.Join(dir, file)
+	}
+	return ""
+}
+
+// defPath returns the DefPath of the script s of pkg.
+func (pkg *npmPackage) defPath(s *npmScript) string {
+	return pkg.file.defPath() + "/npm-script:" + s.name
+}
+
+// An npmIndex holds the packages of the package.json files of a source
+// unit, by the directory they are in.
+type npmIndex map[string]*npmPackage
+
+func newNPMIndex(files []*parsedFile) npmIndex {
+	idx := npmIndex{}
+	for _, f := range files {
+		if filepath.Base(f.name) != "package.json" {
+			continue
+		}
+		data := bytes.TrimPrefix(f.data, utf8BOM)
+		pkg, err := parseNPMPackage(data)
+		if err != nil {
+			// Reported when its scripts were extracted.
+			continue
+		}
+		shift := len(f.data) - len(data)
+		for _, s := range pkg.scripts {
+			s.start += shift
+			s.end += shift
+		}
+		pkg.file = f
+		idx[filepath.Dir(filepath.Clean(f.name))] = pkg
+	}
+	return idx
+}
+
+// resolve returns the package whose scripts npm and yarn run from the
+// directory of the named file: that of the nearest package.json in the
+// directory or above it. It returns nil if the unit has none.
+func (idx npmIndex) resolve(name string) *npmPackage {
+	dir := filepath.Dir(filepath.Clean(name))
+	for {
+		if pkg := idx[dir]; pkg != nil {
+			return pkg
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return nil
+		}
+		dir = parent
+	}
+}
+
+// localBin returns the file of the local command that cmd, a command in
+// the named file, runs if the file is a package.json file, as bin does.
+func (idx npmIndex) localBin(name string, cmd word) string {
+	if filepath.Base(name) != "package.json" {
+		return ""
+	}
+	pkg := idx[filepath.Dir(filepath.Clean(name))]
+	if pkg == nil {
+		return ""
+	}
+	return pkg.bin(unquote(cmd.text))
+}
+
+// An npmRun is a word in a script that names a package.json script that
+// an npm or yarn command runs.
+type npmRun struct {
+	w      word
+	script string
+}
+
+// npmRunScripts are the npm commands that run the script of the same name.
+var npmRunScripts = map[string]string{
+	"test": "test", "t": "test", "tst": "test",
+	"start": "start", "stop": "stop", "restart": "restart",
+}
+
+// yarnCommands are yarn's own commands, which yarn runs rather than a
+// script of the same name.
+var yarnCommands = map[string]bool{
+	"add": true, "audit": true, "autoclean": true, "bin": true, "cache": true,
+	"check": true, "config": true, "create": true, "dedupe": true, "dlx": true,
+	"exec": true, "generate-lock-entry": true, "global": true, "help": true,
+	"import": true, "info": true, "init": true, "install": true,
+	"licenses": true, "link": true, "list": true, "login": true,
+	"logout": true, "node": true, "outdated": true, "owner": true,
+	"pack": true, "patch": true, "plugin": true, "policies": true,
+	"publish": true, "rebuild": true, "remove": true, "run": true,
+	"set": true, "tag": true, "team": true, "unlink": true, "unplug": true,
+	"up": true, "upgrade": true, "upgrade-interactive": true,
+	"version": true, "versions": true, "why": true, "workspace": true,
+	"workspaces": true,
+}
+
+// npmRuns returns the words in s that name the package.json scripts that
+// its npm and yarn commands run, as build in npm run build, npm run-script
+// build, yarn run build or yarn build, and test in npm test.
+func npmRuns(s *script) []npmRun {
+	var runs []npmRun
+	for _, cmd := range s.commands {
+		tool := unquote(cmd.text)
+		if tool != "npm" && tool != "yarn" {
+			continue
+		}
+		var args []word
+		for _, a := range commandArgs(s.words, cmd) {
+			if !strings.HasPrefix(a.text, "-") {
+				args = append(args, a)
+			}
+		}
+		if len(args) == 0 {
+			continue
+		}
+		sub := unquote(args[0].text)
+		switch {
+		case sub == "run" || tool == "npm" && sub == "run-script":
+			if len(args) > 1 {
+				runs = append(runs, npmRun{args[1], unquote(args[1].text)})
+			}
+		case tool == "npm":
+			if name, ok := npmRunScripts[sub]; ok {
+				runs = append(runs, npmRun{args[0], name})
+			}
+		case !yarnCommands[sub]:
+			runs = append(runs, npmRun{args[0], sub})
+		}
+	}
+	return runs
+}
+
+// emitNPMScripts adds the defs of the scripts of f, if it is a package.json
+// file, and the refs to the scripts from the npm and yarn commands in f
+// that run them, as in npm run build. The commands in the scripts that are
+// local, as webpack in "build": "webpack" with webpack installed in
+// node_modules/.bin, are linked to the files they run, which get a def of
+// their own if they aren't in the unit.
+func emitNPMScripts(f *parsedFile, idx *unitIndex, output *graphOutput) error {
+	if pkg := idx.npm[filepath.Dir(filepath.Clean(f.name))]; pkg != nil && pkg.file == f {
+		for _, s := range pkg.scripts {
+			def, err := makeNPMScriptDef(pkg, s)
+			if err != nil {
+				return fmt.Errorf("failed to create npm script def: %s", err)
+			}
+			output.Defs = append(output.Defs, def)
+			output.Refs = append(output.Refs, makeNPMScriptRef(f.name, s.start, s.end, pkg, s, true))
+		}
+	}
+
+	pkg := idx.npm.resolve(f.name)
+	for i, src := range f.sources {
+		s := f.scripts[i]
+		if pkg != nil {
+			for _, run := range npmRuns(s) {
+				if script := pkg.script(run.script); script != nil {
+					output.Refs = append(output.Refs, makeNPMScriptRef(f.name, src.fileOffset(run.w.start), src.fileEnd(run.w.end), pkg, script, false))
+				}
+			}
+		}
+		for _, cmd := range s.commands {
+			if bin := idx.npm.localBin(f.name, cmd); bin != "" {
+				if err := linkFile(f, src, cmd, bin, idx, output); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func makeNPMScriptDef(pkg *npmPackage, s *npmScript) (*graph.Def, error) {
+	data, err := json.Marshal(DefData{
+		Name:    s.name,
+		Keyword: "npm-script",
+		Kind:    "npm-script",
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &graph.Def{
+		DefKey: graph.DefKey{
+			UnitType: "BashDirectory",
+			Unit:     "bash",
+			Path:     pkg.defPath(s),
+		},
+		TreePath: treePath(pkg.defPath(s)),
+		Name:     s.name,
+		Kind:     "npm-script",
+		File:     pkg.file.name,
+		DefStart: uint32(s.start),
+		DefEnd:   uint32(s.end),
+		Data:     data,
+	}, nil
+}
+
+// makeNPMScriptRef returns a ref to the script s of pkg from the span
+// start:end of the named file.
+func makeNPMScriptRef(filename string, start, end int, pkg *npmPackage, s *npmScript, isDef bool) *graph.Ref {
+	return &graph.Ref{
+		DefUnitType: "BashDirectory",
+		DefUnit:     "bash",
+		DefPath:     pkg.defPath(s),
+		UnitType:    "BashDirectory",
+		Unit:        "bash",
+		Def:         isDef,
+		File:        filename,
+		Start:       uint32(start),
+		End:         uint32(end),
+	}
+}
+
+// jsonStringSource decodes the JSON string literal starting at data[start],
+// mapping every decoded character back to where it is in data.
+func jsonStringSource(data []byte, start int) (*source, error) {
+	var b sourceBuilder
+	i := start + 1
+	for i < len(data) && data[i] != '"' {
+		if data[i] != '\\' {
+			b.addByte(data[i], i)
+			i++
+			continue
+		}
+		if i+1 >= len(data) {
+			break
+		}
+		var r rune
+		n := 2
+		switch data[i+1] {
+		case 'b':
+			r = '\b'
+		case 'f':
+			r = '\f'
+		case 'n':
+			r = '\n'
+		case 'r':
+			r = '\r'
+		case 't':
+			r = '\t'
+		case 'u':
+			var err error
+			r, n, err = jsonUnicodeEscape(data[i:])
+			if err != nil {
+				return nil, fmt.Errorf("bad escape at offset %d: %s", i, err)
+			}
+		default:
+			r = rune(data[i+1])
+		}
+		b.addDecoded(string(r), i, i+n)
+		i += n
+	}
+	if i >= len(data) {
+		return nil, fmt.Errorf("unterminated string at offset %d", start)
+	}
+	b.end = i
+	return b.source(), nil
+}
+
+// jsonUnicodeEscape decodes the \uXXXX escape (or surrogate pair) at the
+// start of b, returning the rune and the number of bytes consumed.
+func jsonUnicodeEscape(b []byte) (rune, int, error) {
+	if len(b) < 6 {
+		return 0, 0, fmt.Errorf("short \\u escape")
+	}
+	v, err := strconv.ParseUint(string(b[2:6]), 16, 16)
+	if err != nil {
+		return 0, 0, err
+	}
+	r := rune(v)
+	if utf16.IsSurrogate(r) && len(b) >= 12 && b[6] == '\\' && b[7] == 'u' {
+		if v2, err := strconv.ParseUint(string(b[8:12]), 16, 16); err == nil {
+			if dec := utf16.DecodeRune(r, rune(v2)); dec != utf8.RuneError {
+				return dec, 12, nil
+			}
+		}
+	}
+	return r, 6, nil
+}
+
+func isJSONSpace(b byte) bool {
+	return b == ' ' || b == '\t' || b == '\n' || b == '\r'
+}
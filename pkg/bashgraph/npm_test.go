@@ -0,0 +1,110 @@
+package bashgraph
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"sourcegraph.com/sourcegraph/srclib/unit"
+)
+
+func TestNPMScripts(t *testing.T) {
+	dir, err := ioutil.TempDir("", "srclib-bash-npm")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	files := map[string]string{
+		"package.json": `{
+  "name": "@acme/tool",
+  "bin": "bin/tool.js",
+  "scripts": {
+    "build": "webpack --mode production",
+    "lint": "tool check",
+    "ci": "npm run lint && npm test",
+    "test": "mocha"
+  }
+}
+`,
+		"node_modules/.bin/webpack": "#!/usr/bin/env node\n",
+		"bin/tool.js":               "#!/usr/bin/env node\n",
+		"scripts/deploy.sh":         "#!/bin/sh\nnpm run build -- --watch\nyarn lint\nyarn install\nnpm run missing\n",
+	}
+	for name, data := range files {
+		name = filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(name, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Files are named relative to the unit's directory, as srclib does.
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	opts := testOptions()
+	if err := opts.applySettings(nil); err != nil {
+		t.Fatal(err)
+	}
+	u := &unit.SourceUnit{
+		Key:  unit.Key{Name: "bash", Type: "BashDirectory"},
+		Info: unit.Info{Files: []string{"package.json", filepath.Join("scripts", "deploy.sh")}},
+	}
+	out, err := graphUnits(unit.SourceUnits{u}, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var defs []string
+	for _, def := range out.Defs {
+		if def.Kind == "npm-script" {
+			defs = append(defs, def.Name)
+			data := files[def.File]
+			if got := data[def.DefStart:def.DefEnd]; got != def.Name {
+				t.Errorf("def of %s spans %q", def.Name, got)
+			}
+		}
+	}
+	sort.Strings(defs)
+	if got, want := strings.Join(defs, " "), "build ci lint test"; got != want {
+		t.Errorf("got defs of scripts %s, want %s", got, want)
+	}
+
+	var refs []string
+	for _, ref := range out.Refs {
+		if ref.Def {
+			continue
+		}
+		switch {
+		case strings.Contains(ref.DefPath, "/npm-script:"), strings.HasSuffix(ref.DefPath, "webpack"), strings.HasSuffix(ref.DefPath, "tool.js"):
+			data, err := ioutil.ReadFile(ref.File)
+			if err != nil {
+				t.Fatal(err)
+			}
+			refs = append(refs, ref.File+":"+string(data[ref.Start:ref.End])+"->"+ref.DefPath)
+		}
+	}
+	sort.Strings(refs)
+	want := []string{
+		"package.json:lint->package.json/npm-script:lint",
+		"package.json:test->package.json/npm-script:test",
+		"package.json:tool->bin/tool.js",
+		"package.json:webpack->node_modules/.bin/webpack",
+		"scripts/deploy.sh:build->package.json/npm-script:build",
+		"scripts/deploy.sh:lint->package.json/npm-script:lint",
+	}
+	if got := strings.Join(refs, "\n"); got != strings.Join(want, "\n") {
+		t.Errorf("got refs\n%s\nwant\n%s", got, strings.Join(want, "\n"))
+	}
+}
@@ -0,0 +1,166 @@
+package bashgraph
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"path"
+	"strings"
+	"testing"
+)
+
+// offsetScripts is the number of random scripts TestOffsets generates.
+const offsetScripts = 300
+
+// An offsetScript is a generated script with the spans at which it places
+// identifiers that must be graphed as defs or refs.
+type offsetScript struct {
+	buf    bytes.Buffer
+	idents []identSpan
+}
+
+// An identSpan is an identifier placed in a generated script.
+type identSpan struct {
+	name       string
+	start, end int
+}
+
+// write appends the parts of a line to the script. Parts starting with @
+// are identifiers, which are written without the @ and recorded.
+func (s *offsetScript) write(parts ...string) {
+	for _, p := range parts {
+		if strings.HasPrefix(p, "@") {
+			p = p[1:]
+			s.idents = append(s.idents, identSpan{name: p, start: s.buf.Len(), end: s.buf.Len() + len(p)})
+		}
+		s.buf.WriteString(p)
+	}
+	s.buf.WriteString("\n")
+}
+
+// genOffsetScript generates a script that defines and uses random functions
+// and variables, with random spacing, indentation, comments and multibyte
+// text around them.
+func genOffsetScript(r *rand.Rand) *offsetScript {
+	pick := func(choices ...string) string { return choices[r.Intn(len(choices))] }
+	pad := func() string { return pick("", " ", "  ", "\t", " \t ") }
+	sp := func() string { return pick(" ", "  ", "\t") }
+	noise := func() string { return pick("", "é", "日本", "x", "ü€") }
+	ident := func(prefix string) string {
+		const chars = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ_0123456789"
+		n := 1 + r.Intn(8)
+		b := []byte(prefix)
+		for i := 0; i < n; i++ {
+			b = append(b, chars[r.Intn(len(chars))])
+		}
+		return string(b)
+	}
+
+	s := &offsetScript{}
+	if r.Intn(2) == 0 {
+		s.write("#!/bin/bash")
+	}
+	var funcs, vars []string
+	for i := 1 + r.Intn(4); i > 0; i-- {
+		funcs = append(funcs, ident(fmt.Sprintf("f%d_", len(funcs))))
+	}
+	for i := 1 + r.Intn(4); i > 0; i-- {
+		vars = append(vars, ident(fmt.Sprintf("V%d_", len(vars))))
+	}
+
+	for _, v := range vars {
+		if r.Intn(3) == 0 {
+			s.write("# ", noise(), " comment ", noise())
+		}
+		switch r.Intn(3) {
+		case 0:
+			s.write(pad(), "@"+v, "=", noise(), "1")
+		case 1:
+			s.write(pad(), "@"+v, "=\"", noise(), " value\"")
+		default:
+			s.write(pad(), "export", sp(), "@"+v, "='", noise(), "'")
+		}
+	}
+	for _, f := range funcs {
+		if r.Intn(2) == 0 {
+			s.write(pad(), "@"+f, pad(), "()", pad(), "{")
+		} else {
+			s.write(pad(), "function", sp(), "@"+f, sp(), "{")
+		}
+		s.write(pad(), "echo", sp(), "\"", noise(), "\"")
+		s.write(pad(), "}")
+	}
+
+	for i := 5 + r.Intn(15); i > 0; i-- {
+		f := funcs[r.Intn(len(funcs))]
+		v := vars[r.Intn(len(vars))]
+		switch r.Intn(9) {
+		case 0:
+			s.write(pad(), "@"+f)
+		case 1:
+			s.write(pad(), "@"+f, sp(), "arg", noise())
+		case 2:
+			s.write(pad(), "if", sp(), "@"+f, ";", pad(), "then", sp(), "@"+f, ";", sp(), "fi")
+		case 3:
+			s.write(pad(), "@"+f, sp(), "|", sp(), "@"+f)
+		case 4:
+			s.write(pad(), "echo", sp(), "$", "@"+v)
+		case 5:
+			s.write(pad(), "echo", sp(), "\"", noise(), "${", "@"+v, "}", noise(), "\"")
+		case 6:
+			s.write(pad(), "echo", sp(), "\"", noise(), "$", "@"+v, "-", noise(), "\"")
+		case 7:
+			s.write(pad(), "@"+v, "=$((", "@"+v, sp(), "+", sp(), "1))")
+		default:
+			s.write(pad(), "x=$(", "@"+f, ")", pad(), "# ", noise())
+		}
+	}
+	return s
+}
+
+// TestOffsets graphs random scripts and checks that the span of every def
+// and ref slices exactly its identifier out of the script, and that every
+// identifier placed in the script is graphed at its span.
+func TestOffsets(t *testing.T) {
+	for seed := int64(0); seed < offsetScripts; seed++ {
+		s := genOffsetScript(rand.New(rand.NewSource(seed)))
+		data := s.buf.Bytes()
+
+		f, err := parseData("offsets.sh", data)
+		if err != nil {
+			t.Fatalf("seed %d: %s", seed, err)
+		}
+		output := newGraphOutput()
+		if err := graphFile(f, newUnitIndex([]*parsedFile{f}), output); err != nil {
+			t.Fatalf("seed %d: %s", seed, err)
+		}
+
+		type span struct{ start, end int }
+		graphed := map[span]bool{}
+		for _, def := range output.Defs {
+			if def.Kind == "script" {
+				continue
+			}
+			start, end := int(def.DefStart), int(def.DefEnd)
+			if end > len(data) || start > end || string(data[start:end]) != def.Name {
+				t.Errorf("seed %d: def %s spans %d-%d, not its name\n%s", seed, def.Path, start, end, data)
+				continue
+			}
+			graphed[span{start, end}] = true
+		}
+		for _, ref := range output.Refs {
+			start, end := int(ref.Start), int(ref.End)
+			name := strings.TrimPrefix(path.Base(ref.DefPath), "$")
+			if end > len(data) || start > end || string(data[start:end]) != name {
+				t.Errorf("seed %d: ref to %s spans %d-%d, not %q\n%s", seed, ref.DefPath, start, end, name, data)
+				continue
+			}
+			graphed[span{start, end}] = true
+		}
+		for _, id := range s.idents {
+			if !graphed[span{id.start, id.end}] {
+				t.Errorf("seed %d: %s at %d-%d is not graphed\n%s", seed, id.name, id.start, id.end, data)
+			}
+		}
+	}
+}
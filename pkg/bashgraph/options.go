@@ -0,0 +1,93 @@
+package bashgraph
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// setFlags are the long names of the single-letter options of the set
+// builtin.
+var setFlags = map[byte]string{
+	'a': "allexport", 'b': "notify", 'e': "errexit", 'f': "noglob",
+	'h': "hashall", 'k': "keyword", 'm': "monitor", 'n': "noexec",
+	'p': "privileged", 't': "onecmd", 'u': "nounset", 'v': "verbose",
+	'x': "xtrace", 'B': "braceexpand", 'C': "noclobber", 'E': "errtrace",
+	'H': "histexpand", 'P': "physical", 'T': "functrace",
+}
+
+// shellOptions returns the names of the shell options that f enables, in
+// sorted order: the options of set, by their long names as in errexit for
+// set -e, whether given on its #! line or to set, and the options enabled
+// with shopt -s or zsh's setopt. Options that are later disabled are still
+// included, since they are usually disabled only around a few commands.
+func shellOptions(f *parsedFile) []string {
+	enabled := map[string]bool{}
+	if line, ok := shebangLine(f.data); ok {
+		// The options follow the interpreter, and env before it.
+		fields := strings.Fields(line)
+		skip := 1
+		if len(fields) > 0 && filepath.Base(fields[0]) == "env" {
+			skip = 2
+		}
+		if len(fields) > skip {
+			setOptions(fields[skip:], enabled)
+		}
+	}
+	for _, s := range f.scripts {
+		for _, cmd := range s.commands {
+			var args []string
+			for _, a := range commandArgs(s.words, cmd) {
+				args = append(args, unquote(a.text))
+			}
+			switch unquote(cmd.text) {
+			case "set":
+				setOptions(args, enabled)
+			case "shopt":
+				if len(args) > 0 && args[0] == "-s" {
+					for _, a := range args[1:] {
+						enabled[a] = true
+					}
+				}
+			case "setopt":
+				for _, a := range args {
+					enabled[a] = true
+				}
+			}
+		}
+	}
+	var names []string
+	for name := range enabled {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// setOptions adds the options that the arguments args of set enable to
+// enabled. Parsing stops at the first argument that is not an option, which
+// sets the positional parameters.
+func setOptions(args []string, enabled map[string]bool) {
+	for i := 0; i < len(args); i++ {
+		a := args[i]
+		if a == "--" || len(a) < 2 || a[0] != '-' || a[1] == '-' {
+			return
+		}
+		for j := 1; j < len(a); j++ {
+			if a[j] == 'o' {
+				if i+1 < len(args) {
+					i++
+					enabled[args[i]] = true
+				}
+			} else if name, ok := setFlags[a[j]]; ok {
+				enabled[name] = true
+			}
+		}
+	}
+}
+
+// hasOption reports whether the sorted option names names include name.
+func hasOption(names []string, name string) bool {
+	i := sort.SearchStrings(names, name)
+	return i < len(names) && names[i] == name
+}
@@ -0,0 +1,133 @@
+package bashgraph
+
+import (
+	"sort"
+
+	"sourcegraph.com/sourcegraph/srclib/ann"
+	"sourcegraph.com/sourcegraph/srclib/graph"
+	"sourcegraph.com/sourcegraph/srclib/unit"
+)
+
+// sortOutput sorts the defs, refs, docs and annotations of out by file and
+// position, and then by the other fields that tell them apart, so that
+// graphing the same code always produces the same output.
+func sortOutput(out *graph.Output) {
+	sort.Sort(defsByStart(out.Defs))
+	sort.Sort(refsByStart(out.Refs))
+	sort.Sort(docsByStart(out.Docs))
+	sort.Sort(annsByStart(out.Anns))
+}
+
+type defsByStart []*graph.Def
+
+func (ds defsByStart) Len() int      { return len(ds) }
+func (ds defsByStart) Swap(i, j int) { ds[i], ds[j] = ds[j], ds[i] }
+func (ds defsByStart) Less(i, j int) bool {
+	a, b := ds[i], ds[j]
+	switch {
+	case a.File != b.File:
+		return a.File < b.File
+	case a.DefStart != b.DefStart:
+		return a.DefStart < b.DefStart
+	case a.DefEnd != b.DefEnd:
+		return a.DefEnd < b.DefEnd
+	case a.UnitType != b.UnitType:
+		return a.UnitType < b.UnitType
+	case a.Unit != b.Unit:
+		return a.Unit < b.Unit
+	}
+	return a.Path < b.Path
+}
+
+type refsByStart []*graph.Ref
+
+func (rs refsByStart) Len() int      { return len(rs) }
+func (rs refsByStart) Swap(i, j int) { rs[i], rs[j] = rs[j], rs[i] }
+func (rs refsByStart) Less(i, j int) bool {
+	a, b := rs[i], rs[j]
+	switch {
+	case a.File != b.File:
+		return a.File < b.File
+	case a.Start != b.Start:
+		return a.Start < b.Start
+	case a.End != b.End:
+		return a.End < b.End
+	case a.Def != b.Def:
+		return a.Def
+	case a.DefRepo != b.DefRepo:
+		return a.DefRepo < b.DefRepo
+	case a.DefUnitType != b.DefUnitType:
+		return a.DefUnitType < b.DefUnitType
+	case a.DefUnit != b.DefUnit:
+		return a.DefUnit < b.DefUnit
+	case a.DefPath != b.DefPath:
+		return a.DefPath < b.DefPath
+	case a.UnitType != b.UnitType:
+		return a.UnitType < b.UnitType
+	}
+	return a.Unit < b.Unit
+}
+
+type docsByStart []*graph.Doc
+
+func (ds docsByStart) Len() int      { return len(ds) }
+func (ds docsByStart) Swap(i, j int) { ds[i], ds[j] = ds[j], ds[i] }
+func (ds docsByStart) Less(i, j int) bool {
+	a, b := ds[i], ds[j]
+	switch {
+	case a.File != b.File:
+		return a.File < b.File
+	case a.Start != b.Start:
+		return a.Start < b.Start
+	case a.Path != b.Path:
+		return a.Path < b.Path
+	}
+	return a.Format < b.Format
+}
+
+type annsByStart []*ann.Ann
+
+func (as annsByStart) Len() int      { return len(as) }
+func (as annsByStart) Swap(i, j int) { as[i], as[j] = as[j], as[i] }
+func (as annsByStart) Less(i, j int) bool {
+	a, b := as[i], as[j]
+	switch {
+	case a.File != b.File:
+		return a.File < b.File
+	case a.StartLine != b.StartLine:
+		return a.StartLine < b.StartLine
+	case a.EndLine != b.EndLine:
+		return a.EndLine < b.EndLine
+	case a.Type != b.Type:
+		return a.Type < b.Type
+	}
+	return string(a.Data) < string(b.Data)
+}
+
+type unitsByKey []*unit.SourceUnit
+
+func (us unitsByKey) Len() int      { return len(us) }
+func (us unitsByKey) Swap(i, j int) { us[i], us[j] = us[j], us[i] }
+func (us unitsByKey) Less(i, j int) bool {
+	if us[i].Type != us[j].Type {
+		return us[i].Type < us[j].Type
+	}
+	return us[i].Name < us[j].Name
+}
+
+type warningsByStart []*Warning
+
+func (ws warningsByStart) Len() int      { return len(ws) }
+func (ws warningsByStart) Swap(i, j int) { ws[i], ws[j] = ws[j], ws[i] }
+func (ws warningsByStart) Less(i, j int) bool {
+	a, b := ws[i], ws[j]
+	switch {
+	case a.File != b.File:
+		return a.File < b.File
+	case a.Start != b.Start:
+		return a.Start < b.Start
+	case a.End != b.End:
+		return a.End < b.End
+	}
+	return a.Code < b.Code
+}
@@ -0,0 +1,225 @@
+package bashgraph
+
+import (
+	"fmt"
+	"io/ioutil"
+)
+
+// A script is the result of parsing a shell source.
+type script struct {
+	// dialect is the shell the script is written for, as in parsedFile.
+	dialect string
+	words   []word
+	// commands are the names of the simple commands in the script.
+	commands []word
+	// functions are the functions the script defines, in source order.
+	functions []*function
+	// vars are the places where the script assigns or declares variables,
+	// in source order.
+	vars []*varSite
+	// varRefs are the variable expansions in the script.
+	varRefs []*varRef
+	// aliases are the aliases the script defines.
+	aliases []*aliasSite
+}
+
+// A function is a shell function definition.
+type function struct {
+	name string
+	// nameStart and nameEnd are the offsets of the function's name.
+	nameStart, nameEnd int
+	// start and end are the offsets of the whole definition, from the
+	// function keyword or name to the end of the body.
+	start, end int
+	// bodyStart is the offset of the body, such as its opening brace.
+	bodyStart int
+	// complexity is the cyclomatic complexity of the body.
+	complexity int
+}
+
+// A parsedFile is a file in a source unit along with the parses of its
+// shell sources.
+type parsedFile struct {
+	name string
+	data []byte
+	// dialect is the shell the file is written for, "bash" or "zsh".
+	dialect string
+	// dotenv is set if the file is a dotenv file that the unit's scripts
+	// source, rather than one of its files.
+	dotenv  bool
+	sources []*source
+	scripts []*script
+	// root is the root of the repository of the unit the file was parsed
+	// for, or nil if it wasn't parsed for a unit.
+	root *repoRoot
+}
+
+// defPath returns the DefPath of f, as scriptDefPath does.
+func (f *parsedFile) defPath() string {
+	return f.root.defPath(f.name)
+}
+
+// parseFile reads and parses the shell sources in the named file, through
+// the cache of analyzed files that opts use.
+func parseFile(name string, opts *Options) (*parsedFile, error) {
+	data, err := ioutil.ReadFile(name)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read file %s: %s", name, err)
+	}
+	return parseCachedData(opts.cacheDir(), name, data)
+}
+
+// parseData parses the shell sources in data, the contents of the named
+// file.
+func parseData(name string, data []byte) (*parsedFile, error) {
+	sources, err := extractSources(name, data)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to extract shell sources from %s: %s", name, err)
+	}
+	f := &parsedFile{name: name, data: data, dialect: shellDialect(name, data), sources: sources}
+	for _, src := range sources {
+		f.scripts = append(f.scripts, parseScript(src.text, f.dialect))
+	}
+	return f, nil
+}
+
+// parseScript parses the shell source text, written for dialect, with the
+// lexer and parser of the pipeline.
+func parseScript(text, dialect string) *script {
+	return defaultPipeline.parser.parse(defaultPipeline.lexer.lex(text), dialect)
+}
+
+// parseWords parses words, the words of shell source text written for
+// dialect.
+func parseWords(words []word, dialect string) *script {
+	s := &script{
+		dialect:  dialect,
+		words:    words,
+		commands: commandWords(words),
+	}
+
+	pos := commandPositions(words)
+	for i, w := range words {
+		if w.op || !isFuncDefName(words, i) {
+			continue
+		}
+		fn := &function{
+			name:      unquote(w.text),
+			nameStart: w.start,
+			nameEnd:   w.end,
+			start:     w.start,
+		}
+		if k := functionKeyword(words, i); k >= 0 {
+			fn.start = words[k].start
+		}
+
+		// Find the compound command that is the body.
+		j := i + 1
+		for j < len(words) && functionKeyword(words, j) >= 0 {
+			j++
+		}
+		if j+1 < len(words) && words[j].text == "(" && words[j+1].text == ")" {
+			j += 2
+		}
+		for j < len(words) && words[j].text == "\n" {
+			j++
+		}
+		if j == len(words) {
+			continue
+		}
+		fn.bodyStart = words[j].start
+		fn.end = words[compoundEnd(words, pos, j)].end
+		s.functions = append(s.functions, fn)
+	}
+	for _, fn := range s.functions {
+		fn.complexity = s.complexity(fn)
+	}
+
+	parseVars(s)
+	parseAliases(s)
+	return s
+}
+
+// enclosingFunc returns the innermost function of s whose definition
+// contains w, or nil if w is at the top level.
+func (s *script) enclosingFunc(w word) *function {
+	var enclosing *function
+	for _, fn := range s.functions {
+		if fn.start <= w.start && w.end <= fn.end && (enclosing == nil || fn.start > enclosing.start) {
+			enclosing = fn
+		}
+	}
+	return enclosing
+}
+
+// compoundOpeners and compoundClosers are the reserved words that open and
+// close compound commands.
+var (
+	compoundOpeners = map[string]bool{"{": true, "if": true, "case": true, "for": true, "select": true, "while": true, "until": true}
+	compoundClosers = map[string]bool{"}": true, "fi": true, "esac": true, "done": true}
+)
+
+// compoundEnd returns the index of the last word of the compound command
+// starting at words[i]. pos holds the command positions of words.
+func compoundEnd(words []word, pos []bool, i int) int {
+	if words[i].op && words[i].text == "(" {
+		depth := 0
+		for j := i; j < len(words); j++ {
+			if !words[j].op {
+				continue
+			}
+			switch words[j].text {
+			case "(":
+				depth++
+			case ")":
+				depth--
+				if depth == 0 {
+					return j
+				}
+			}
+		}
+		return len(words) - 1
+	}
+
+	depth := 0
+	for j := i; j < len(words); j++ {
+		if !pos[j] {
+			continue
+		}
+		switch {
+		case compoundOpeners[words[j].text]:
+			depth++
+		case compoundClosers[words[j].text]:
+			depth--
+			if depth == 0 {
+				return j
+			}
+		}
+		if depth == 0 {
+			// Not a compound command; the body is a simple command.
+			for j < len(words) && !words[j].isControlOp() {
+				j++
+			}
+			return j - 1
+		}
+	}
+	return len(words) - 1
+}
+
+// shellBuiltins are the commands built into Bash, which are never external
+// programs.
+var shellBuiltins = map[string]bool{
+	".": true, ":": true, "[": true, "alias": true, "bg": true, "bind": true,
+	"break": true, "builtin": true, "caller": true, "cd": true, "command": true,
+	"compgen": true, "complete": true, "compopt": true, "continue": true,
+	"declare": true, "dirs": true, "disown": true, "echo": true, "enable": true,
+	"eval": true, "exec": true, "exit": true, "export": true, "false": true,
+	"fc": true, "fg": true, "getopts": true, "hash": true, "help": true,
+	"history": true, "jobs": true, "kill": true, "let": true, "local": true,
+	"logout": true, "mapfile": true, "popd": true, "printf": true, "pushd": true,
+	"pwd": true, "read": true, "readarray": true, "readonly": true, "return": true,
+	"set": true, "shift": true, "shopt": true, "source": true, "suspend": true,
+	"test": true, "times": true, "trap": true, "true": true, "type": true,
+	"typeset": true, "ulimit": true, "umask": true, "unalias": true, "unset": true,
+	"wait": true,
+}
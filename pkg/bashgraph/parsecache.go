@@ -0,0 +1,64 @@
+package bashgraph
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// A parseCache holds parsed files by name, so that a long-running process
+// such as serve doesn't reparse the files that haven't changed since it
+// last graphed them.
+type parseCache struct {
+	mu    sync.Mutex
+	files map[string]*cachedFile
+}
+
+// A cachedFile is a parsed file along with the modification time and size
+// the file had when it was read.
+type cachedFile struct {
+	modTime time.Time
+	size    int64
+	file    *parsedFile
+}
+
+// parseUnitFile parses the named file of a source unit, through the
+// parse cache of opts if there is one.
+func parseUnitFile(name string, opts *Options) (*parsedFile, error) {
+	if opts.cache != nil {
+		return opts.cache.parse(name, opts)
+	}
+	return parseFile(name, opts)
+}
+
+func newParseCache() *parseCache {
+	return &parseCache{files: map[string]*cachedFile{}}
+}
+
+// parse returns the parsed file name, parsing it with opts only if it is
+// not cached or has changed since it was cached.
+func (c *parseCache) parse(name string, opts *Options) (*parsedFile, error) {
+	info, err := os.Stat(name)
+	if err != nil {
+		return parseFile(name, opts)
+	}
+	key, err := filepath.Abs(name)
+	if err != nil {
+		return parseFile(name, opts)
+	}
+	c.mu.Lock()
+	cached := c.files[key]
+	c.mu.Unlock()
+	if cached != nil && cached.modTime.Equal(info.ModTime()) && cached.size == info.Size() && cached.file.name == name {
+		return cached.file, nil
+	}
+	f, err := parseFile(name, opts)
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	c.files[key] = &cachedFile{modTime: info.ModTime(), size: info.Size(), file: f}
+	c.mu.Unlock()
+	return f, nil
+}
@@ -0,0 +1,156 @@
+package bashgraph
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"sourcegraph.com/sourcegraph/srclib/unit"
+)
+
+// The analysis of a source unit runs in stages, each behind an interface so
+// that it can be tested, and replaced, on its own:
+//
+//   - extractSources finds the shell sources in each file, such as the run
+//     steps of a CI config;
+//   - a lexer splits each source into words;
+//   - a parser finds the commands, functions, variables and aliases in the
+//     words of a source;
+//   - an indexer collects what the unit's files define, which refs resolve
+//     to;
+//   - emitters add the defs, refs, docs and diagnostics of each file to the
+//     graph output, in order, the last of them adding the warnings about
+//     the file and tagging the refs that the others added from privileged
+//     and network commands;
+//   - a graphWriter writes the output.
+//
+// The stages only communicate through their results and read their
+// configuration from the Options passed to them, which the indexer hands
+// on to the emitters in the unit's index, rather than from the commands'
+// flags. Files are parsed in parallel, Jobs at a time; units are graphed
+// one after another, except by the index command, which graphs several at
+// once.
+
+// A lexer splits shell source text into words.
+type lexer interface {
+	lex(text string) []word
+}
+
+// A parser parses the words of shell source text written for dialect.
+type parser interface {
+	parse(words []word, dialect string) *script
+}
+
+// An indexer indexes the definitions in files, the parsed files of u, for
+// graphing them with opts.
+type indexer interface {
+	index(u *unit.SourceUnit, files []*parsedFile, opts *Options) (*unitIndex, error)
+}
+
+// An emitter adds what it finds in f, a file of the unit that idx indexes,
+// to output.
+type emitter interface {
+	emit(f *parsedFile, idx *unitIndex, output *graphOutput) error
+}
+
+// A graphWriter writes the graph output of units.
+type graphWriter interface {
+	write(units unit.SourceUnits, out *positionedOutput) error
+}
+
+// A pipeline is the lexer, parser, indexer and emitters that analyze shell
+// sources.
+type pipeline struct {
+	lexer    lexer
+	parser   parser
+	indexer  indexer
+	emitters []emitter
+}
+
+// defaultPipeline is the pipeline that all commands use.
+var defaultPipeline = &pipeline{
+	lexer:   wordLexer{},
+	parser:  scriptParser{},
+	indexer: unitIndexer{},
+	emitters: []emitter{
+		emitterFunc(emitScriptDef),
+		emitterFunc(emitCommandDocs),
+		emitterFunc(emitFuncDefs),
+		emitterFunc(emitVars),
+		emitterFunc(emitAliases),
+		emitterFunc(emitCommandTargets),
+		emitterFunc(emitCalls),
+		emitterFunc(emitEvalDiagnostics),
+		emitterFunc(emitBashisms),
+		emitterFunc(emitSecurityFindings),
+		emitterFunc(emitSecrets),
+		emitterFunc(emitIncludes),
+		emitterFunc(emitFileRefs),
+		emitterFunc(emitNPMScripts),
+		emitterFunc(emitCompletions),
+		emitterFunc(emitHooks),
+		emitterFunc(emitDeclaredFuncs),
+		// These tag the refs that the emitters above added.
+		emitterFunc(emitFileWarnings),
+		emitterFunc(emitPrivileged),
+		emitterFunc(emitNetwork),
+	},
+}
+
+// wordLexer is the lexer of bash and its dialects.
+type wordLexer struct{}
+
+func (wordLexer) lex(text string) []word {
+	return splitWords(text)
+}
+
+// scriptParser is the parser of bash and its dialects.
+type scriptParser struct{}
+
+func (scriptParser) parse(words []word, dialect string) *script {
+	return parseWords(words, dialect)
+}
+
+// unitIndexer indexes the functions, variables, aliases, completions and
+// exports of the files, the scripts of their package.json files, and the
+// commands they run that are linked to defs outside the unit.
+type unitIndexer struct{}
+
+func (unitIndexer) index(u *unit.SourceUnit, files []*parsedFile, opts *Options) (*unitIndex, error) {
+	idx := newUnitIndex(files)
+	idx.opts = opts
+	var err error
+	if idx.commands, err = newCommandResolver(u, opts); err != nil {
+		return nil, err
+	}
+	return idx, nil
+}
+
+// An emitterFunc is a function that is an emitter.
+type emitterFunc func(f *parsedFile, idx *unitIndex, output *graphOutput) error
+
+func (e emitterFunc) emit(f *parsedFile, idx *unitIndex, output *graphOutput) error {
+	return e(f, idx, output)
+}
+
+// jsonGraphWriter writes graph output to w as JSON.
+type jsonGraphWriter struct {
+	w io.Writer
+}
+
+func (jw jsonGraphWriter) write(units unit.SourceUnits, out *positionedOutput) error {
+	if err := json.NewEncoder(jw.w).Encode(out); err != nil {
+		return fmt.Errorf("Failed to output graph data: %s", err)
+	}
+	return nil
+}
+
+// sqliteGraphWriter writes graph output to the SQLite database in the named
+// file, replacing the index it holds.
+type sqliteGraphWriter struct {
+	name string
+}
+
+func (sw sqliteGraphWriter) write(units unit.SourceUnits, out *positionedOutput) error {
+	return writeSQLite(sw.name, units, out)
+}
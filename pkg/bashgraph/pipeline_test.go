@@ -0,0 +1,82 @@
+package bashgraph
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"sourcegraph.com/sourcegraph/srclib/graph"
+	"sourcegraph.com/sourcegraph/srclib/unit"
+)
+
+func TestLexer(t *testing.T) {
+	var texts []string
+	for _, w := range defaultPipeline.lexer.lex("greet() { echo \"hi $1\"; }\n") {
+		texts = append(texts, w.text)
+	}
+	want := []string{"greet", "(", ")", "{", "echo", `"hi $1"`, ";", "}", "\n"}
+	if !reflect.DeepEqual(texts, want) {
+		t.Errorf("got words %q, want %q", texts, want)
+	}
+}
+
+func TestParser(t *testing.T) {
+	// The parser only sees words, so they needn't come from the lexer.
+	words := []word{
+		{text: "greet", start: 0, end: 5},
+		{text: "(", start: 5, end: 6, op: true},
+		{text: ")", start: 6, end: 7, op: true},
+		{text: "{", start: 8, end: 9},
+		{text: "x=1", start: 10, end: 13},
+		{text: ";", start: 13, end: 14, op: true},
+		{text: "}", start: 15, end: 16},
+	}
+	s := defaultPipeline.parser.parse(words, "bash")
+	if len(s.functions) != 1 || s.functions[0].name != "greet" || s.functions[0].end != 16 {
+		t.Errorf("got functions %+v, want greet ending at 16", s.functions)
+	}
+	if len(s.vars) != 1 || s.vars[0].name != "x" {
+		t.Errorf("got vars %+v, want x", s.vars)
+	}
+}
+
+func TestEmitter(t *testing.T) {
+	f, err := parseData("lib.sh", []byte("greet() { :; }\nbye() { :; }\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	idx, err := defaultPipeline.indexer.index(&unit.SourceUnit{}, []*parsedFile{f}, testOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	output := newGraphOutput()
+	if err := emitFuncDefs(f, idx, output); err != nil {
+		t.Fatal(err)
+	}
+	var paths []string
+	for _, def := range output.Defs {
+		paths = append(paths, def.Path)
+	}
+	if want := []string{"lib.sh/greet", "lib.sh/bye"}; !reflect.DeepEqual(paths, want) {
+		t.Errorf("got defs %v, want %v", paths, want)
+	}
+	if len(output.Refs) != 2 || !output.Refs[0].Def {
+		t.Errorf("got refs %v, want the def refs of greet and bye", output.Refs)
+	}
+}
+
+func TestJSONGraphWriter(t *testing.T) {
+	var buf bytes.Buffer
+	out := &positionedOutput{Output: &graph.Output{}, Defs: []*positionedDef{{Def: &graph.Def{Name: "greet"}}}}
+	if err := (jsonGraphWriter{&buf}).write(nil, out); err != nil {
+		t.Fatal(err)
+	}
+	var got graph.Output
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Defs) != 1 || got.Defs[0].Name != "greet" {
+		t.Errorf("got %s, want the def of greet", buf.Bytes())
+	}
+}
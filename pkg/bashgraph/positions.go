@@ -0,0 +1,134 @@
+package bashgraph
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	"sourcegraph.com/sourcegraph/srclib/graph"
+)
+
+// A Position is a 1-based line and column (in characters) in a file.
+type Position struct {
+	Line   int
+	Column int
+}
+
+// A positionedDef is a def along with the line and column positions of its
+// span.
+type positionedDef struct {
+	*graph.Def
+	StartPos Position
+	EndPos   Position
+}
+
+// A positionedRef is a ref along with the line and column positions of its
+// span.
+type positionedRef struct {
+	*graph.Ref
+	StartPos Position
+	EndPos   Position
+	// Flags are the options passed to the command, for refs to man pages.
+	Flags []string `json:",omitempty"`
+	// Section is the section of the man page that describes a keyword,
+	// for refs to shell keywords.
+	Section string `json:",omitempty"`
+	// DefCommit is the revision of DefRepo that the ref links to, for
+	// refs to POSIX man pages of a chosen edition.
+	DefCommit string `json:",omitempty"`
+	// Indirect is set for refs to commands named in the value of a
+	// variable that is run as a command, as in CMD="grep -r"; $CMD.
+	Indirect bool `json:",omitempty"`
+	// ViaVariable is the variable that a command is run through, for refs
+	// from expansions such as $RSYNC to the command they run.
+	ViaVariable string `json:",omitempty"`
+	// LowConfidence is set for refs from strings passed to eval, which
+	// may not be run as they appear.
+	LowConfidence bool `json:",omitempty"`
+	// NamerefTarget is what a nameref refers to, as in the variable's def
+	// data, for refs to namerefs.
+	NamerefTarget string `json:",omitempty"`
+	// ViaNameref is the nameref that a variable is used through, for
+	// refs from the uses of namerefs to the variables they refer to.
+	ViaNameref string `json:",omitempty"`
+	// Privileged is why the command requires elevated privileges, such as
+	// "sudo", "chown root" or "writes /etc/hosts", for refs from commands.
+	Privileged string `json:",omitempty"`
+	// Network is how the command reaches the network, for refs from
+	// commands such as curl and ssh.
+	Network *NetworkUse `json:",omitempty"`
+	// Data is the man page summary of the command, for refs from
+	// commands to their man pages.
+	Data *RefData `json:",omitempty"`
+}
+
+// positionedOutput is graph output whose defs and refs carry line and column
+// positions in addition to byte offsets. It encodes to JSON as a superset of
+// graph.Output.
+type positionedOutput struct {
+	*graph.Output
+	Defs []*positionedDef
+	Refs []*positionedRef
+	// Warnings are the issues with how well the files were indexed.
+	Warnings []*Warning `json:",omitempty"`
+}
+
+// withPositions adds line and column positions to the defs and refs of out,
+// reading the files they are in, and the flags, sections and revisions of
+// its refs to man pages, which opts choose.
+func withPositions(out *graphOutput, opts *Options) (*positionedOutput, error) {
+	files := map[string]*lineIndex{}
+	position := func(file string, offset uint32) (Position, error) {
+		li, ok := files[file]
+		if !ok {
+			data, ok := out.contents[file]
+			if !ok {
+				var err error
+				if data, err = ioutil.ReadFile(file); err != nil {
+					return Position{}, fmt.Errorf("Failed to read file %s: %s", file, err)
+				}
+			}
+			li = newLineIndex(data)
+			files[file] = li
+		}
+		line, col := li.position(int(offset))
+		return Position{Line: line, Column: col}, nil
+	}
+
+	pout := &positionedOutput{Output: &out.Output, Warnings: out.warnings}
+	for _, def := range out.Defs {
+		pd := &positionedDef{Def: def}
+		var err error
+		if pd.StartPos, err = position(def.File, def.DefStart); err != nil {
+			return nil, err
+		}
+		if pd.EndPos, err = position(def.File, def.DefEnd); err != nil {
+			return nil, err
+		}
+		pout.Defs = append(pout.Defs, pd)
+	}
+	for _, ref := range out.Refs {
+		pr := &positionedRef{
+			Ref:           ref,
+			Flags:         out.flags[ref],
+			Section:       out.sections[ref],
+			DefCommit:     opts.defCommit(ref),
+			Indirect:      out.indirect[ref],
+			ViaVariable:   out.viaVariable[ref],
+			LowConfidence: out.lowConfidence[ref],
+			NamerefTarget: out.namerefTarget[ref],
+			ViaNameref:    out.viaNameref[ref],
+			Privileged:    out.privileged[ref],
+			Network:       out.network[ref],
+			Data:          commandRefData(ref, out.sections[ref]),
+		}
+		var err error
+		if pr.StartPos, err = position(ref.File, ref.Start); err != nil {
+			return nil, err
+		}
+		if pr.EndPos, err = position(ref.File, ref.End); err != nil {
+			return nil, err
+		}
+		pout.Refs = append(pout.Refs, pr)
+	}
+	return pout, nil
+}
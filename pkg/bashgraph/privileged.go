@@ -0,0 +1,206 @@
+package bashgraph
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"sourcegraph.com/sourcegraph/srclib/unit"
+)
+
+type PrivilegedCmd struct {
+	JSON bool `long:"json" description:"output the report as JSON"`
+}
+
+// A PrivilegedScript is a file that runs operations that require elevated
+// privileges.
+type PrivilegedScript struct {
+	File       string
+	Operations []*PrivilegedOp
+}
+
+// A PrivilegedOp is a command that requires elevated privileges.
+type PrivilegedOp struct {
+	// Command is the name of the command, as written.
+	Command string
+	// Reason is why the command requires elevated privileges, such as
+	// "sudo", "chown root" or "writes /etc/hosts".
+	Reason string
+	// Start and End are the byte offsets of the command's name in the file.
+	Start uint32
+	End   uint32
+	Line  int
+}
+
+// elevationCommands are the commands that run other commands with elevated
+// privileges.
+var elevationCommands = map[string]bool{
+	"sudo": true, "su": true, "doas": true, "pkexec": true, "runuser": true,
+}
+
+// systemPaths are the directories that only root can write to.
+var systemPaths = []string{"/etc", "/usr", "/bin", "/sbin", "/lib", "/lib64", "/boot", "/opt", "/root", "/var", "/sys", "/proc"}
+
+// writeRedirections are the redirection operators that write to their
+// target.
+var writeRedirections = map[string]bool{
+	">": true, ">>": true, ">|": true, "&>": true, "&>>": true, "<>": true,
+}
+
+func (c *PrivilegedCmd) Execute(args []string) error {
+	units, err := readSourceUnits()
+	if err != nil {
+		return err
+	}
+
+	scripts := privilegedScripts(units, commandOptions())
+	if c.JSON {
+		if err := json.NewEncoder(os.Stdout).Encode(scripts); err != nil {
+			return fmt.Errorf("Failed to output privileged operations: %s", err)
+		}
+		return nil
+	}
+
+	files := 0
+	for _, u := range units {
+		files += len(u.Files)
+	}
+	for _, s := range scripts {
+		for _, op := range s.Operations {
+			fmt.Printf("%s:%d: %s\n", s.File, op.Line, op.Reason)
+		}
+	}
+	fmt.Printf("%d of %d files require elevated privileges\n", len(scripts), files)
+	return nil
+}
+
+// privilegedScripts returns the files of units that run operations that
+// require elevated privileges, sorted by name.
+func privilegedScripts(units unit.SourceUnits, opts *Options) []*PrivilegedScript {
+	var scripts []*PrivilegedScript
+	for _, u := range units {
+		for _, f := range parseUnit(u, opts) {
+			ops := privilegedOps(f)
+			if len(ops) == 0 {
+				continue
+			}
+			li := newLineIndex(f.data)
+			for _, op := range ops {
+				op.Line, _ = li.position(int(op.Start))
+			}
+			scripts = append(scripts, &PrivilegedScript{File: f.name, Operations: ops})
+		}
+	}
+	sort.Sort(privilegedScriptsByFile(scripts))
+	return scripts
+}
+
+type privilegedScriptsByFile []*PrivilegedScript
+
+func (ps privilegedScriptsByFile) Len() int           { return len(ps) }
+func (ps privilegedScriptsByFile) Swap(i, j int)      { ps[i], ps[j] = ps[j], ps[i] }
+func (ps privilegedScriptsByFile) Less(i, j int) bool { return ps[i].File < ps[j].File }
+
+// privilegedOps returns the commands in f that require elevated privileges,
+// in the order they appear. Line is not set.
+func privilegedOps(f *parsedFile) []*PrivilegedOp {
+	var ops []*PrivilegedOp
+	for i, s := range f.scripts {
+		src := f.sources[i]
+		for _, cmd := range s.commands {
+			if reason := privilegedReason(s.words, cmd); reason != "" {
+				ops = append(ops, &PrivilegedOp{
+					Command: unquote(cmd.text),
+					Reason:  reason,
+					Start:   uint32(src.fileOffset(cmd.start)),
+					End:     uint32(src.fileEnd(cmd.end)),
+				})
+			}
+		}
+	}
+	return ops
+}
+
+// privilegedReason returns why the command named by cmd requires elevated
+// privileges, or "" if it doesn't: it elevates them itself, sets file
+// capabilities, gives files to root, or writes to a system path, by
+// redirection or as the destination of a command such as tee or cp.
+func privilegedReason(words []word, cmd word) string {
+	name := unquote(cmd.text)
+	if elevationCommands[name] || name == "setcap" {
+		return name
+	}
+	args := commandArgs(words, cmd)
+	var paths []word
+	switch name {
+	case "chown", "chgrp":
+		for _, a := range args {
+			if a := unquote(a.text); !strings.HasPrefix(a, "-") {
+				if owner := strings.SplitN(a, ":", 2)[0]; owner == "root" || owner == "0" {
+					return name + " root"
+				}
+				break
+			}
+		}
+	case "tee", "mkdir", "touch", "rm", "rmdir", "chmod":
+		paths = args
+	case "cp", "mv", "install", "ln":
+		if len(args) > 0 {
+			paths = args[len(args)-1:]
+		}
+	}
+	ws := enclosingWords(words, cmd)
+	for i, w := range ws {
+		if w.start <= cmd.start {
+			continue
+		}
+		if w.isControlOp() {
+			break
+		}
+		if writeRedirections[w.text] && i+1 < len(ws) {
+			paths = append(paths, ws[i+1])
+		}
+	}
+	for _, p := range paths {
+		if path := unquote(p.text); isSystemPath(path) {
+			return "writes " + path
+		}
+	}
+	return ""
+}
+
+// isSystemPath reports whether path, as written, is in one of systemPaths,
+// other than /var/tmp, which anyone can write to.
+func isSystemPath(path string) bool {
+	if strings.ContainsAny(path, "$`") || strings.HasPrefix(path, "/var/tmp") {
+		return false
+	}
+	for _, dir := range systemPaths {
+		if path == dir || strings.HasPrefix(path, dir+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// emitPrivileged records the reason of each ref graphed from f that is from
+// a command requiring elevated privileges.
+func emitPrivileged(f *parsedFile, idx *unitIndex, output *graphOutput) error {
+	ops := privilegedOps(f)
+	if len(ops) == 0 {
+		return nil
+	}
+	refs := output.fileRefs()
+	reasons := map[[2]uint32]string{}
+	for _, op := range ops {
+		reasons[[2]uint32{op.Start, op.End}] = op.Reason
+	}
+	for _, ref := range refs {
+		if reason, ok := reasons[[2]uint32{ref.Start, ref.End}]; ok && ref.File == f.name && !ref.Def {
+			output.privileged[ref] = reason
+		}
+	}
+	return nil
+}
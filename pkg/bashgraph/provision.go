@@ -0,0 +1,153 @@
+package bashgraph
+
+import (
+	"bytes"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// ansibleShellKeys are the task keys whose values are command lines in
+// Ansible playbooks and roles.
+var ansibleShellKeys = map[string]bool{
+	"shell":                   true,
+	"command":                 true,
+	"ansible.builtin.shell":   true,
+	"ansible.builtin.command": true,
+	"cmd":                     true,
+}
+
+// isAnsibleFile reports whether path is an Ansible playbook or role task
+// list.
+func isAnsibleFile(path string) bool {
+	ext := filepath.Ext(path)
+	if ext != ".yml" && ext != ".yaml" {
+		return false
+	}
+	name := strings.TrimSuffix(filepath.Base(path), ext)
+	if name == "site" || strings.HasPrefix(name, "playbook") {
+		return true
+	}
+	for _, dir := range strings.Split(filepath.ToSlash(filepath.Dir(path)), "/") {
+		switch dir {
+		case "playbooks", "tasks", "handlers":
+			return true
+		}
+	}
+	return false
+}
+
+// isVagrantfile reports whether path is a Vagrantfile.
+func isVagrantfile(path string) bool {
+	return filepath.Base(path) == "Vagrantfile"
+}
+
+var (
+	vagrantInline  = regexp.MustCompile(`\binline\s*(?::|=)\s*`)
+	rubyHeredoc    = regexp.MustCompile(`<<([-~]?)(["']?)([A-Za-z_][A-Za-z0-9_]*)(["']?)`)
+	rubyHeredocVar = regexp.MustCompile(`(\$?[A-Za-z_][A-Za-z0-9_]*)\s*=\s*<<[-~]?["']?[A-Za-z_]`)
+	rubyVariable   = regexp.MustCompile(`^\$?[A-Za-z_][A-Za-z0-9_]*`)
+)
+
+// vagrantInlineSources returns the inline shell provisioner scripts of a
+// Vagrantfile as shell sources. An inline script may be a string literal, a
+// heredoc, or a variable that a heredoc is assigned to.
+func vagrantInlineSources(data []byte) []*source {
+	heredocs := map[string]*source{}
+	for _, m := range rubyHeredocVar.FindAllSubmatchIndex(data, -1) {
+		start := m[0] + bytes.Index(data[m[0]:m[1]], []byte("<<"))
+		if src := rubyHeredocSource(data, start); src != nil {
+			heredocs[string(data[m[2]:m[3]])] = src
+		}
+	}
+
+	var sources []*source
+	for _, m := range vagrantInline.FindAllIndex(data, -1) {
+		i := m[1]
+		if i >= len(data) {
+			break
+		}
+		switch {
+		case data[i] == '"' || data[i] == '\'':
+			if src := rubyStringSource(data, i); src != nil {
+				sources = append(sources, src)
+			}
+		case data[i] == '<':
+			if src := rubyHeredocSource(data, i); src != nil {
+				sources = append(sources, src)
+			}
+		default:
+			if v := rubyVariable.Find(data[i:]); v != nil && heredocs[string(v)] != nil {
+				sources = append(sources, heredocs[string(v)])
+			}
+		}
+	}
+	return sources
+}
+
+// rubyStringSource decodes the Ruby string literal starting at data[start].
+// Interpolations are kept verbatim.
+func rubyStringSource(data []byte, start int) *source {
+	quote := data[start]
+	var b sourceBuilder
+	for i := start + 1; i < len(data); i++ {
+		c := data[i]
+		switch {
+		case c == quote:
+			b.end = i
+			return b.source()
+		case c == '\\' && i+1 < len(data):
+			next := data[i+1]
+			switch {
+			case quote == '"' && next == 'n':
+				b.addDecoded("\n", i, i+2)
+			case quote == '"' && next == 't':
+				b.addDecoded("\t", i, i+2)
+			case next == quote || next == '\\':
+				b.addDecoded(string(next), i, i+2)
+			default:
+				b.addByte(c, i)
+				b.addByte(next, i+1)
+			}
+			i++
+		default:
+			b.addByte(c, i)
+		}
+	}
+	return nil
+}
+
+// rubyHeredocSource returns the body of the heredoc whose opening <<TAG
+// starts at data[start]. The body begins on the line after the opening tag.
+func rubyHeredocSource(data []byte, start int) *source {
+	m := rubyHeredoc.FindSubmatchIndex(data[start:])
+	if m == nil || m[0] != 0 {
+		return nil
+	}
+	indented := m[3] > m[2]
+	tag := string(data[start+m[6] : start+m[7]])
+
+	i := start + m[1]
+	for i < len(data) && data[i] != '\n' {
+		i++
+	}
+	i++
+	var b sourceBuilder
+	for i < len(data) {
+		end := i
+		for end < len(data) && data[end] != '\n' {
+			end++
+		}
+		line := strings.TrimRight(string(data[i:end]), "\r")
+		if line == tag || indented && strings.TrimSpace(line) == tag {
+			b.end = i
+			return b.source()
+		}
+		b.add(data, i, end)
+		if end < len(data) {
+			b.addByte('\n', end)
+		}
+		i = end + 1
+	}
+	return nil
+}
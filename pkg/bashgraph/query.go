@@ -0,0 +1,94 @@
+package bashgraph
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+type QueryCmd struct {
+	DB   string `long:"db" description:"SQLite index to query" value-name:"FILE" required:"yes"`
+	Refs bool   `long:"refs" description:"list the refs to the matching defs"`
+	File string `long:"file" description:"only list the defs, or with --refs the refs, in FILE" value-name:"FILE"`
+	JSON bool   `long:"json" description:"output the defs or refs as JSON, as graph outputs them"`
+	Args struct {
+		Pattern string `positional-arg-name:"PATTERN"`
+	} `positional-args:"yes"`
+}
+
+// A queryRow is a def or ref found by the query command.
+type queryRow struct {
+	File   string
+	Line   int
+	Column int
+	// Text is the kind and name of a def, or the DefPath of a ref.
+	Text string
+	JSON json.RawMessage
+}
+
+func (c *QueryCmd) Execute(args []string) error {
+	if _, err := os.Stat(c.DB); err != nil {
+		return fmt.Errorf("Failed to open database %s: %s", c.DB, err)
+	}
+	db, err := openSQLite(c.DB)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	rows, err := c.query(db)
+	if err != nil {
+		return fmt.Errorf("Failed to query %s: %s", c.DB, err)
+	}
+	if c.JSON {
+		found := make([]json.RawMessage, len(rows))
+		for i, r := range rows {
+			found[i] = r.JSON
+		}
+		if err := json.NewEncoder(os.Stdout).Encode(found); err != nil {
+			return fmt.Errorf("Failed to output query results: %s", err)
+		}
+		return nil
+	}
+	for _, r := range rows {
+		fmt.Printf("%s:%d:%d: %s\n", r.File, r.Line, r.Column, r.Text)
+	}
+	return nil
+}
+
+// query returns the defs whose names match the pattern, or with --refs the
+// refs to them, ordered by file and position.
+func (c *QueryCmd) query(db *sql.DB) ([]*queryRow, error) {
+	pattern := c.Args.Pattern
+	if pattern == "" {
+		pattern = "*"
+	}
+	var q string
+	if c.Refs {
+		q = `SELECT r.file, r.start_line, r.start_column, r.def_path, r.json FROM refs r
+			JOIN defs d ON r.def_path = d.path AND r.def_unit = d.unit AND r.def_unit_type = d.unit_type AND r.def_repo = ''
+			WHERE d.name GLOB ? AND NOT r.is_def AND (? = '' OR r.file = ?)
+			ORDER BY r.file, r.start_offset`
+	} else {
+		q = `SELECT file, start_line, start_column, kind || ' ' || name, json FROM defs
+			WHERE name GLOB ? AND (? = '' OR file = ?)
+			ORDER BY file, start_offset`
+	}
+	rows, err := db.Query(q, pattern, c.File, c.File)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var found []*queryRow
+	for rows.Next() {
+		var r queryRow
+		var data string
+		if err := rows.Scan(&r.File, &r.Line, &r.Column, &r.Text, &data); err != nil {
+			return nil, err
+		}
+		r.JSON = json.RawMessage(data)
+		found = append(found, &r)
+	}
+	return found, rows.Err()
+}
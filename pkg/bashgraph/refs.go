@@ -0,0 +1,114 @@
+package bashgraph
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"sourcegraph.com/sourcegraph/srclib/buildstore"
+	"sourcegraph.com/sourcegraph/srclib/graph"
+)
+
+type RefsCmd struct {
+	JSON    bool `long:"json" description:"output refs as JSON"`
+	NoCache bool `long:"no-cache" description:"always graph the source units read from STDIN"`
+	Args    struct {
+		DefPathOrFile string `positional-arg-name:"DEFPATH|FILE" required:"yes"`
+		Name          string `positional-arg-name:"NAME"`
+	} `positional-args:"yes"`
+}
+
+func (c *RefsCmd) Execute(args []string) error {
+	out, err := loadGraphOutput(c.NoCache)
+	if err != nil {
+		return err
+	}
+
+	defPath := c.Args.DefPathOrFile
+	if c.Args.Name != "" {
+		defPath = scriptDefPath(c.Args.DefPathOrFile) + "/" + c.Args.Name
+	}
+
+	idx := NewSymbolIndex(out.Defs, out.Refs)
+	refs := idx.RefsTo(graph.DefKey{UnitType: "BashDirectory", Unit: "bash", Path: defPath})
+
+	if c.JSON {
+		if err := json.NewEncoder(os.Stdout).Encode(refs); err != nil {
+			return fmt.Errorf("Failed to output refs: %s", err)
+		}
+		return nil
+	}
+	return printRefs(refs)
+}
+
+// loadGraphOutput returns the graph output of the source units, from the
+// srclib build cache if possible and noCache is not set, or else by graphing
+// the source units read from STDIN.
+func loadGraphOutput(noCache bool) (*graph.Output, error) {
+	if !noCache {
+		if path, ok := cachedGraphPath(); ok {
+			data, err := ioutil.ReadFile(path)
+			if err != nil {
+				return nil, fmt.Errorf("Failed to read cached graph output: %s", err)
+			}
+			var out graph.Output
+			if err := json.Unmarshal(data, &out); err != nil {
+				return nil, fmt.Errorf("Failed to parse cached graph output %s: %s", path, err)
+			}
+			return &out, nil
+		}
+	}
+
+	units, err := readSourceUnits()
+	if err != nil {
+		return nil, err
+	}
+	out, err := graphUnits(units, commandOptions())
+	if err != nil {
+		return nil, err
+	}
+	return &out.Output, nil
+}
+
+// cachedGraphPath returns the path of the graph output that srclib cached
+// for the bash source unit at the current commit, if there is one.
+func cachedGraphPath() (string, bool) {
+	commitID, err := exec.Command("git", "rev-parse", "HEAD").Output()
+	if err != nil {
+		return "", false
+	}
+	path := filepath.Join(buildstore.BuildDataDirName, strings.TrimSpace(string(commitID)), "bash", "BashDirectory.graph.json")
+	if _, err := os.Stat(path); err != nil {
+		return "", false
+	}
+	return path, true
+}
+
+// printRefs prints refs in the FILE:LINE:COL: TEXT format of grep and
+// compilers.
+func printRefs(refs []*graph.Ref) error {
+	files := map[string]*lineIndex{}
+	for _, ref := range refs {
+		li, ok := files[ref.File]
+		if !ok {
+			data, err := ioutil.ReadFile(ref.File)
+			if err != nil {
+				return fmt.Errorf("Failed to read file %s: %s", ref.File, err)
+			}
+			li = newLineIndex(data)
+			files[ref.File] = li
+		}
+		line, col := li.position(int(ref.Start))
+		text := li.data[li.start[line-1]:]
+		if i := bytes.IndexByte(text, '\n'); i >= 0 {
+			text = text[:i]
+		}
+		fmt.Printf("%s:%d:%d: %s\n", ref.File, line, col, bytes.TrimSpace(text))
+	}
+	return nil
+}
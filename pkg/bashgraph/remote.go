@@ -0,0 +1,104 @@
+package bashgraph
+
+import "strings"
+
+// sshOptsWithArg are the single-letter options of ssh that take an
+// argument.
+const sshOptsWithArg = "BbcDEeFIiJLlmOopQRSWw"
+
+// dockerExecOptsWithArg are the options of docker exec that take an
+// argument.
+var dockerExecOptsWithArg = map[string]bool{
+	"-e": true, "--env": true, "--env-file": true, "-u": true, "--user": true,
+	"-w": true, "--workdir": true, "--detach-keys": true,
+}
+
+// remoteCommandLists returns the words of the command strings that the
+// commands in words pass to another shell to run, such as 'ls -l' in
+// ssh host 'ls -l', docker exec app sh -c 'ls -l' or kubectl exec app --
+// sh -c 'ls -l'. Command strings in command strings are included. Word
+// offsets are in the same text as words', inside the string literals.
+func remoteCommandLists(words []word) [][]word {
+	var lists [][]word
+	for _, cmd := range commandWords(words) {
+		for _, a := range remoteArgs(words, cmd) {
+			inner, ok := quotedWords(a)
+			if !ok {
+				continue
+			}
+			lists = append(lists, inner)
+			lists = append(lists, remoteCommandLists(inner)...)
+		}
+	}
+	return lists
+}
+
+// remoteArgs returns the arguments of the command named by cmd that make up
+// the command it runs elsewhere: the arguments after the host for ssh, the
+// container for docker exec and "--" for kubectl exec, and the argument of
+// -c for shells.
+func remoteArgs(words []word, cmd word) []word {
+	args := commandArgs(words, cmd)
+	switch unquote(cmd.text) {
+	case "ssh":
+		for i := 0; i < len(args); i++ {
+			a := unquote(args[i].text)
+			switch {
+			case len(a) == 2 && a[0] == '-' && strings.IndexByte(sshOptsWithArg, a[1]) >= 0:
+				i++
+			case strings.HasPrefix(a, "-"):
+			default:
+				return args[i+1:]
+			}
+		}
+	case "docker":
+		if len(args) == 0 || unquote(args[0].text) != "exec" {
+			return nil
+		}
+		for i := 1; i < len(args); i++ {
+			a := unquote(args[i].text)
+			switch {
+			case dockerExecOptsWithArg[a]:
+				i++
+			case strings.HasPrefix(a, "-"):
+			default:
+				return args[i+1:]
+			}
+		}
+	case "kubectl":
+		if len(args) == 0 || unquote(args[0].text) != "exec" {
+			return nil
+		}
+		for i, a := range args {
+			if a.text == "--" {
+				return args[i+1:]
+			}
+		}
+	case "sh", "bash":
+		for i, a := range args {
+			if a.text == "-c" && i+1 < len(args) {
+				return args[i+1 : i+2]
+			}
+		}
+	}
+	return nil
+}
+
+// quotedWords returns the words in the string literal w, such as ls and -l
+// in 'ls -l', or false if w is not a single string literal.
+func quotedWords(w word) ([]word, bool) {
+	text := w.text
+	if len(text) < 2 || text[0] != text[len(text)-1] || text[0] != '\'' && text[0] != '"' {
+		return nil, false
+	}
+	inner := text[1 : len(text)-1]
+	if text[0] == '\'' && strings.IndexByte(inner, '\'') >= 0 {
+		return nil, false
+	}
+	words := splitWords(inner)
+	for i := range words {
+		words[i].start += w.start + 1
+		words[i].end += w.start + 1
+	}
+	return words, true
+}
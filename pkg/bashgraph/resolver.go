@@ -0,0 +1,146 @@
+package bashgraph
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"sourcegraph.com/sourcegraph/srclib/unit"
+)
+
+// A Resolver links the commands that scripts run to defs outside the unit,
+// such as the entries of an internal tool catalog or an artifact registry.
+// Resolvers are consulted after the command map and before man pages.
+type Resolver interface {
+	// Resolve returns the def that runs of cmd link to, or nil to leave
+	// cmd to the next resolver.
+	Resolve(cmd *Command) (*CommandTarget, error)
+}
+
+// A ResolverFunc is a function that is a Resolver.
+type ResolverFunc func(cmd *Command) (*CommandTarget, error)
+
+// Resolve calls f(cmd).
+func (f ResolverFunc) Resolve(cmd *Command) (*CommandTarget, error) {
+	return f(cmd)
+}
+
+// A Command is a command run in a script, as a Resolver sees it.
+type Command struct {
+	// Name is the name of the command, without the directory it may be
+	// run from.
+	Name string
+	// Path is the command as written, such as /usr/local/bin/deployctl.
+	Path string
+	// File is the script that runs the command.
+	File string
+	// Unit is the name of the source unit that File is in, and Config is
+	// the unit's config, as set in the Srcfile.
+	Unit   string
+	Config map[string]string
+}
+
+var (
+	resolversMu sync.Mutex
+	resolvers   = map[string]Resolver{}
+)
+
+// RegisterResolver makes r available as the resolver named name. It is
+// meant to be called from the init functions of packages that are compiled
+// into a build of srclib-bash, and panics if name is already registered.
+func RegisterResolver(name string, r Resolver) {
+	resolversMu.Lock()
+	defer resolversMu.Unlock()
+	if _, ok := resolvers[name]; ok {
+		panic(fmt.Sprintf("resolver %s registered twice", name))
+	}
+	resolvers[name] = r
+}
+
+// enabledResolvers returns the registered resolvers named in names, in
+// order, or all of them in order of name if names is empty.
+func enabledResolvers(names []string) ([]Resolver, error) {
+	resolversMu.Lock()
+	defer resolversMu.Unlock()
+	if len(names) == 0 {
+		for name := range resolvers {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+	}
+	var enabled []Resolver
+	for _, name := range names {
+		r, ok := resolvers[name]
+		if !ok {
+			return nil, fmt.Errorf("Unknown resolver %s", name)
+		}
+		enabled = append(enabled, r)
+	}
+	return enabled, nil
+}
+
+// A commandResolver finds the defs that the commands run in a unit link to:
+// the unit's command map, then the enabled resolvers in order. A nil
+// commandResolver resolves nothing.
+type commandResolver struct {
+	unit      *unit.SourceUnit
+	commands  commandMap
+	resolvers []Resolver
+	// resolved holds the targets of the commands already resolved, by
+	// file and command as written.
+	resolved map[[2]string]*CommandTarget
+	// opts are the options of the analysis, which failures are logged
+	// through.
+	opts *Options
+}
+
+// newCommandResolver returns the commandResolver for u, with the command
+// map and resolvers that opts choose.
+func newCommandResolver(u *unit.SourceUnit, opts *Options) (*commandResolver, error) {
+	commands, err := unitCommandMap(u, opts.CommandMap)
+	if err != nil {
+		return nil, err
+	}
+	rs, err := enabledResolvers(opts.Resolvers)
+	if err != nil {
+		return nil, err
+	}
+	return &commandResolver{unit: u, commands: commands, resolvers: rs, resolved: map[[2]string]*CommandTarget{}, opts: opts}, nil
+}
+
+// lookup returns the target of the command that cmd names in the named
+// file, if any. Commands may be named by path, as in
+// /usr/local/bin/deployctl. A resolver that fails is logged and skipped.
+func (r *commandResolver) lookup(cmd word, file string) *CommandTarget {
+	if r == nil || strings.ContainsAny(cmd.text, "$`") {
+		return nil
+	}
+	path := unquote(cmd.text)
+	if t := r.commands[filepath.Base(path)]; t != nil {
+		return t
+	}
+	if len(r.resolvers) == 0 {
+		return nil
+	}
+	key := [2]string{file, path}
+	if t, ok := r.resolved[key]; ok {
+		return t
+	}
+	c := &Command{Name: filepath.Base(path), Path: path, File: file, Unit: r.unit.Name, Config: r.unit.Config}
+	var target *CommandTarget
+	for _, res := range r.resolvers {
+		t, err := res.Resolve(c)
+		if err != nil {
+			r.opts.warnf("Failed to resolve command %s in %s: %s", path, file, err)
+			continue
+		}
+		if t != nil {
+			target = t
+			break
+		}
+	}
+	r.resolved[key] = target
+	return target
+}
@@ -0,0 +1,48 @@
+package bashgraph
+
+import (
+	"fmt"
+	"testing"
+)
+
+func init() {
+	// The test resolver only knows a command that no testdata script runs,
+	// so it leaves the golden files alone.
+	RegisterResolver("test-catalog", ResolverFunc(func(cmd *Command) (*CommandTarget, error) {
+		switch cmd.Name {
+		case "acme-deployctl":
+			return &CommandTarget{DefRepo: "example.com/tools", DefUnitType: "Catalog", DefUnit: "tools", DefPath: "deployctl"}, nil
+		case "acme-broken":
+			return nil, fmt.Errorf("catalog unavailable")
+		}
+		return nil, nil
+	}))
+}
+
+func TestResolver(t *testing.T) {
+	r, err := AnalyzeFile("deploy.sh", []byte("/opt/acme/bin/acme-deployctl rollout\nacme-broken\nacme-deployctl status\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var starts []uint32
+	for _, ref := range r.Refs {
+		if ref.DefRepo == "example.com/tools" && ref.DefPath == "deployctl" {
+			starts = append(starts, ref.Start)
+		}
+		if ref.DefPath == "acme-broken" {
+			t.Errorf("ref to acme-broken, whose resolver failed: %+v", ref)
+		}
+	}
+	if len(starts) != 2 || starts[0] != 0 || starts[1] != 49 {
+		t.Errorf("got refs to deployctl at %v, want [0 49]", starts)
+	}
+}
+
+func TestEnabledResolvers(t *testing.T) {
+	if _, err := enabledResolvers([]string{"test-catalog"}); err != nil {
+		t.Error(err)
+	}
+	if _, err := enabledResolvers([]string{"no-such-resolver"}); err == nil {
+		t.Error("no error for an unknown resolver")
+	}
+}
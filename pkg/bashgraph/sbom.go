@@ -0,0 +1,135 @@
+package bashgraph
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"sourcegraph.com/sourcegraph/srclib/unit"
+)
+
+type SBOMCmd struct {
+	CommandMap string   `long:"command-map" description:"JSON file mapping command names to the defs to link them to" value-name:"FILE"`
+	Resolvers  []string `long:"resolver" description:"compiled-in resolver to link commands with, in order; may be given more than once (default: all of them, by name)" value-name:"NAME"`
+	LocalMan   bool     `long:"local-man" description:"count commands documented by the man pages installed on this host as known"`
+}
+
+// A UnitSBOM lists the external commands that the scripts of a source unit
+// run.
+type UnitSBOM struct {
+	UnitType string
+	Unit     string
+	Commands []*SBOMCommand
+}
+
+// An SBOMCommand is an external command that scripts run.
+type SBOMCommand struct {
+	Name  string
+	Count int
+	// Files are the files that run the command, sorted.
+	Files []string
+	// Known is set if the command resolves to a known package: a target of
+	// the command map or a resolver, or a man page.
+	Known bool
+	// Target is the def that the command map or a resolver links the
+	// command to.
+	Target *CommandTarget `json:",omitempty"`
+	// ManPage is the repository and path of the command's man page, if it
+	// has no Target.
+	ManPage string `json:",omitempty"`
+}
+
+func (c *SBOMCmd) Execute(args []string) error {
+	units, err := readSourceUnits()
+	if err != nil {
+		return err
+	}
+	opts := commandOptions()
+	opts.CommandMap, opts.Resolvers = c.CommandMap, c.Resolvers
+	if err := opts.applySettings(unitsConfig(units)); err != nil {
+		return err
+	}
+
+	sboms, err := commandSBOMs(units, c.LocalMan, opts)
+	if err != nil {
+		return fmt.Errorf("Failed to list commands: %s", err)
+	}
+	if err := json.NewEncoder(os.Stdout).Encode(sboms); err != nil {
+		return fmt.Errorf("Failed to output commands: %s", err)
+	}
+	return nil
+}
+
+// commandSBOMs returns the external commands that the scripts of each of
+// units run, sorted by name. Commands named by a relative path are
+// scripts, not installed programs, and are left out, as in man-coverage.
+func commandSBOMs(units unit.SourceUnits, localMan bool, opts *Options) ([]*UnitSBOM, error) {
+	var sboms []*UnitSBOM
+	for _, u := range units {
+		files := parseUnit(u, opts)
+		resolver, err := newCommandResolver(u, opts)
+		if err != nil {
+			return nil, err
+		}
+		funcs := map[string]bool{}
+		for name := range newFuncIndex(files) {
+			funcs[name] = true
+		}
+
+		commands := map[string]*SBOMCommand{}
+		inFile := map[string]map[string]bool{}
+		for _, f := range files {
+			for _, s := range f.scripts {
+				for _, cmd := range s.commands {
+					path, ok := externalCommand(f, cmd, funcs)
+					if !ok || strings.Contains(path, "/") && !filepath.IsAbs(path) {
+						continue
+					}
+					name := filepath.Base(path)
+					c := commands[name]
+					if c == nil {
+						c = &SBOMCommand{Name: name}
+						commands[name] = c
+						inFile[name] = map[string]bool{}
+					}
+					c.Count++
+					if !inFile[name][f.name] {
+						inFile[name][f.name] = true
+						c.Files = append(c.Files, f.name)
+					}
+					if c.Target == nil {
+						c.Target = resolver.lookup(cmd, f.name)
+					}
+				}
+			}
+		}
+
+		us := &UnitSBOM{UnitType: u.Type, Unit: u.Name}
+		for _, c := range commands {
+			if c.Target == nil {
+				page, ok := manPages[c.Name]
+				if !ok && localMan {
+					page, ok = localManPage(c.Name, opts.LocalManRepo)
+				}
+				if ok {
+					c.ManPage = page.repo + "/" + page.path
+				}
+			}
+			c.Known = c.Target != nil || c.ManPage != ""
+			sort.Strings(c.Files)
+			us.Commands = append(us.Commands, c)
+		}
+		sort.Sort(sbomCommandsByName(us.Commands))
+		sboms = append(sboms, us)
+	}
+	return sboms, nil
+}
+
+type sbomCommandsByName []*SBOMCommand
+
+func (cs sbomCommandsByName) Len() int           { return len(cs) }
+func (cs sbomCommandsByName) Swap(i, j int)      { cs[i], cs[j] = cs[j], cs[i] }
+func (cs sbomCommandsByName) Less(i, j int) bool { return cs[i].Name < cs[j].Name }
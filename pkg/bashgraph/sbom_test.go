@@ -0,0 +1,54 @@
+package bashgraph
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"sourcegraph.com/sourcegraph/srclib/unit"
+)
+
+func TestCommandSBOMs(t *testing.T) {
+	dir, err := ioutil.TempDir("", "srclib-bash-sbom")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	name := filepath.Join(dir, "deploy.sh")
+	script := "acme-deployctl rollout\ncurl -fsS https://example.com\n./build.sh\ncurl -O https://example.com/a\nacme-unknown-tool\necho done\n"
+	if err := ioutil.WriteFile(name, []byte(script), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	opts := testOptions()
+	if err := opts.applySettings(nil); err != nil {
+		t.Fatal(err)
+	}
+	u := &unit.SourceUnit{Key: unit.Key{Name: "app", Type: "BashDirectory"}, Info: unit.Info{Files: []string{name}}}
+	sboms, err := commandSBOMs(unit.SourceUnits{u}, false, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sboms) != 1 {
+		t.Fatalf("got %d units, want 1", len(sboms))
+	}
+	var names []string
+	for _, c := range sboms[0].Commands {
+		names = append(names, c.Name)
+	}
+	if want := []string{"acme-deployctl", "acme-unknown-tool", "curl"}; !reflect.DeepEqual(names, want) {
+		t.Fatalf("got commands %v, want %v", names, want)
+	}
+	deployctl, unknown, curl := sboms[0].Commands[0], sboms[0].Commands[1], sboms[0].Commands[2]
+	if !deployctl.Known || deployctl.Target == nil || deployctl.Target.DefPath != "deployctl" {
+		t.Errorf("got %+v, want acme-deployctl resolved by the test resolver", deployctl)
+	}
+	if unknown.Known {
+		t.Errorf("got %+v, want acme-unknown-tool unknown", unknown)
+	}
+	if !curl.Known || curl.ManPage == "" || curl.Count != 2 || !reflect.DeepEqual(curl.Files, []string{name}) {
+		t.Errorf("got %+v, want curl run twice and linked to its man page", curl)
+	}
+}
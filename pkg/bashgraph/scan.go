@@ -0,0 +1,234 @@
+package bashgraph
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"sourcegraph.com/sourcegraph/srclib/unit"
+)
+
+type ScanCmd struct {
+	Extensions []string `long:"extension" description:"also scan files with extension EXT, such as .zsh; may be given more than once" value-name:"EXT"`
+	Excludes   []string `long:"exclude" description:"skip the files and directories matching PATTERN; may be given more than once" value-name:"PATTERN"`
+}
+
+// UnitData is the scanner-specific data of a BashDirectory source unit.
+type UnitData struct {
+	// HookScripts are the files in the unit that are git hooks.
+	HookScripts []string `json:",omitempty"`
+}
+
+func (c *ScanCmd) Execute(args []string) error {
+	scanDir, err := currentDir()
+	if err != nil {
+		return fmt.Errorf("resolving the path to scan failed with: %s", err)
+	}
+
+	config, err := readTreeConfig()
+	if err != nil {
+		return err
+	}
+	units, err := c.scanUnits(scanDir, config)
+	if err != nil {
+		return err
+	}
+
+	bytes, err := json.MarshalIndent(units, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshalling source units failed with: %s, units: %s", err, units)
+	}
+
+	if _, err := os.Stdout.Write(bytes); err != nil {
+		return fmt.Errorf("writing output failed with: %s", err)
+	}
+
+	return nil
+}
+
+// scanUnits scans scanDir with the settings of c, filling in those not
+// given with flags from the environment or config, the source tree config,
+// which is also recorded in the units.
+func (c *ScanCmd) scanUnits(scanDir string, config map[string]string) ([]*unit.SourceUnit, error) {
+	c.Extensions = listSetting(c.Extensions, "EXTENSIONS", config, "bashExtensions")
+	c.Excludes = listSetting(c.Excludes, "EXCLUDES", config, "bashExcludes")
+
+	units, err := c.scan(scanDir)
+	if err != nil {
+		return nil, fmt.Errorf("scanning the path failed with: %s", err)
+	}
+	if len(config) > 0 {
+		for _, u := range units {
+			u.Config = config
+		}
+	}
+	return units, nil
+}
+
+// currentDir returns the current directory, which scan and index scan, with
+// symlinks resolved.
+func currentDir() (string, error) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+	return filepath.EvalSymlinks(dir)
+}
+
+// scan finds the shell scripts in the tree rooted at scanDir, with the
+// extensions and excludes of c.
+func (c *ScanCmd) scan(scanDir string) ([]*unit.SourceUnit, error) {
+	var units []*unit.SourceUnit
+	var files []string
+	var data UnitData
+
+	err := filepath.Walk(scanDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return fmt.Errorf("walking directory %s failed with: %s", scanDir, err)
+		}
+		if info.IsDir() && (info.Name() == "node_modules" || info.Name() == ".git") {
+			return filepath.SkipDir
+		}
+		relpath, err := filepath.Rel(scanDir, path)
+		if err != nil {
+			return fmt.Errorf("making path %s relative to %s failed with: %s", path, scanDir, err)
+		}
+		if relpath != "." && matchesGlob(c.Excludes, relpath) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		hook := isGitHook(relpath)
+		if isShellFile(relpath) || hasExtension(relpath, c.Extensions) || hook && hasShellShebang(path) {
+			files = append(files, relpath)
+			if hook {
+				data.HookScripts = append(data.HookScripts, relpath)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("scanning for Bash scripts failed with: %s", err)
+	}
+	sort.Strings(files)
+	sort.Strings(data.HookScripts)
+	logDebugf("Found %d Bash scripts in %s", len(files), scanDir)
+
+	dataJSON, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("marshalling unit data failed with: %s", err)
+	}
+
+	units = append(units, &unit.SourceUnit{
+		Key: unit.Key{
+			Name: "bash",
+			Type: "BashDirectory",
+		},
+		Info: unit.Info{
+			Files:        files,
+			Dependencies: imageDeps(scanDir, files),
+			Data:         dataJSON,
+		},
+	})
+
+	sort.Sort(unitsByKey(units))
+	return units, nil
+}
+
+// isShellFile reports whether the file at path, relative to the scanned
+// directory, contains shell code, either as a script or embedded in another
+// format that graph knows how to extract.
+func isShellFile(path string) bool {
+	// TODO(mate): implement a more sophisticated filter
+	_, name := filepath.Split(path)
+	switch {
+	case strings.HasSuffix(name, ".sh"), strings.HasSuffix(name, ".bash"):
+		return true
+	case name == "package.json":
+		return true
+	case isCIConfigFile(path), isCrontabFile(path), isSystemdServiceFile(path):
+		return true
+	case isAnsibleFile(path), isVagrantfile(path):
+		return true
+	}
+	return false
+}
+
+// hasExtension reports whether path has one of extensions, which may be
+// given with or without the leading dot.
+func hasExtension(path string, extensions []string) bool {
+	for _, ext := range extensions {
+		if !strings.HasPrefix(ext, ".") {
+			ext = "." + ext
+		}
+		if strings.HasSuffix(path, ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasShellShebang reports whether the file at path starts with a #! line
+// naming a shell interpreter, either directly or through env.
+func hasShellShebang(path string) bool {
+	f, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	head, err := ioutil.ReadAll(io.LimitReader(f, 1024))
+	if err != nil {
+		return false
+	}
+	line, ok := shebangLine(head)
+	if !ok {
+		return false
+	}
+	switch shebangInterpreter(line) {
+	case "sh", "bash", "dash", "ash", "ksh":
+		return true
+	}
+	return false
+}
+
+// shebangLine returns the rest of the #! line at the start of data, if there
+// is one. A byte order mark and blank lines before the #! are skipped: the
+// kernel would not run such a script with the interpreter, but the #! line
+// still tells which shell it is written for.
+func shebangLine(data []byte) (string, bool) {
+	data = bytes.TrimPrefix(data, utf8BOM)
+	data = bytes.TrimLeft(data, " \t\r\n")
+	if !bytes.HasPrefix(data, []byte("#!")) {
+		return "", false
+	}
+	data = data[2:]
+	if i := bytes.IndexByte(data, '\n'); i >= 0 {
+		data = data[:i]
+	}
+	return string(data), true
+}
+
+// shebangInterpreter returns the name of the interpreter that the #! line
+// line runs, looking through env, as in #!/usr/bin/env bash, or "" if there
+// is none.
+func shebangInterpreter(line string) string {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return ""
+	}
+	interp := filepath.Base(fields[0])
+	if interp == "env" && len(fields) > 1 {
+		interp = fields[1]
+	}
+	return interp
+}
@@ -0,0 +1,139 @@
+package bashgraph
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"sourcegraph.com/sourcegraph/srclib/graph"
+	"sourcegraph.com/sourcegraph/srclib/unit"
+)
+
+// checkSchema checks that units and data, the graph output emitted for
+// them, survive a round trip through the srclib unit and graph types, in
+// JSON and in the protobuf encoding the srclib driver stores, and that they
+// pass srclib's own stricter checks. The toolchain has no depresolve op, so
+// there are no resolved deps to check.
+func checkSchema(units unit.SourceUnits, data []byte) []*Violation {
+	var violations []*Violation
+	add := func(v *Violation) {
+		v.Check = "schema"
+		violations = append(violations, v)
+	}
+
+	for _, u := range units {
+		if u.Name == "" || u.Type == "" {
+			add(&Violation{Message: fmt.Sprintf("unit %q of type %q has no name or type", u.Name, u.Type)})
+		}
+		if name, typ, err := unit.ParseID(string(u.ID())); err != nil || name != u.Name || typ != u.Type {
+			add(&Violation{Message: fmt.Sprintf("unit %s has an ID that doesn't parse back to its name and type", u.Name)})
+		}
+		if len(u.Data) > 0 && !json.Valid(u.Data) {
+			add(&Violation{Message: fmt.Sprintf("unit %s has data that is not valid JSON", u.Name)})
+		}
+		if err := roundTripUnit(u); err != nil {
+			add(&Violation{Message: fmt.Sprintf("unit %s: %s", u.Name, err)})
+		}
+	}
+
+	var out graph.Output
+	if err := json.Unmarshal(data, &out); err != nil {
+		add(&Violation{Message: fmt.Sprintf("graph output does not decode as a srclib graph output: %s", err)})
+		return violations
+	}
+	if err := roundTripOutput(&out); err != nil {
+		add(&Violation{Message: err.Error()})
+	}
+
+	for _, def := range out.Defs {
+		at := func(msg string) *Violation {
+			return &Violation{File: def.File, Start: def.DefStart, End: def.DefEnd, Message: msg}
+		}
+		if def.Path == "" || def.Name == "" || def.UnitType == "" || def.Unit == "" {
+			add(at(fmt.Sprintf("def %q has no path, name or unit", def.Path)))
+		}
+		if def.TreePath != "" && !graph.IsValidTreePath(def.TreePath) {
+			add(at(fmt.Sprintf("def %s has an invalid TreePath %q", def.Path, def.TreePath)))
+		}
+		if len(def.Data) > 0 && !json.Valid(def.Data) {
+			add(at(fmt.Sprintf("def %s has data that is not valid JSON", def.Path)))
+		}
+	}
+	refs := graph.NewRefSet()
+	for _, ref := range out.Refs {
+		at := func(msg string) *Violation {
+			return &Violation{File: ref.File, Start: ref.Start, End: ref.End, Message: msg}
+		}
+		if ref.DefUnitType == "" || ref.DefUnit == "" || ref.DefPath == "" {
+			add(at("ref has no def unit or path"))
+		}
+		if refs.AddAndCheckUnique(*ref) {
+			add(at(fmt.Sprintf("ref to %s is emitted more than once", refTarget(ref))))
+		}
+	}
+	for _, a := range out.Anns {
+		if a.Type == "" {
+			add(&Violation{File: a.File, Message: "annotation has no type"})
+		}
+		if len(a.Data) > 0 && !json.Valid(a.Data) {
+			add(&Violation{File: a.File, Message: fmt.Sprintf("%s annotation has data that is not valid JSON", a.Type)})
+		}
+	}
+	return violations
+}
+
+// roundTripUnit checks that u encodes and decodes to the same source unit
+// in JSON and in protobuf.
+func roundTripUnit(u *unit.SourceUnit) error {
+	want, err := json.Marshal(u)
+	if err != nil {
+		return fmt.Errorf("failed to encode as JSON: %s", err)
+	}
+	var fromJSON unit.SourceUnit
+	if err := json.Unmarshal(want, &fromJSON); err != nil {
+		return fmt.Errorf("failed to decode from JSON: %s", err)
+	}
+	pb, err := fromJSON.Marshal()
+	if err != nil {
+		return fmt.Errorf("failed to encode as protobuf: %s", err)
+	}
+	var fromPB unit.SourceUnit
+	if err := fromPB.Unmarshal(pb); err != nil {
+		return fmt.Errorf("failed to decode from protobuf: %s", err)
+	}
+	for _, v := range []*unit.SourceUnit{&fromJSON, &fromPB} {
+		got, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Errorf("failed to re-encode as JSON: %s", err)
+		}
+		if !bytes.Equal(got, want) {
+			return fmt.Errorf("changes in a round trip through the srclib unit type")
+		}
+	}
+	return nil
+}
+
+// roundTripOutput checks that out, decoded from JSON, encodes and decodes
+// to the same graph output in JSON and in protobuf.
+func roundTripOutput(out *graph.Output) error {
+	want, err := json.Marshal(out)
+	if err != nil {
+		return fmt.Errorf("graph output fails to encode as JSON: %s", err)
+	}
+	pb, err := out.Marshal()
+	if err != nil {
+		return fmt.Errorf("graph output fails to encode as protobuf: %s", err)
+	}
+	var fromPB graph.Output
+	if err := fromPB.Unmarshal(pb); err != nil {
+		return fmt.Errorf("graph output fails to decode from protobuf: %s", err)
+	}
+	got, err := json.Marshal(&fromPB)
+	if err != nil {
+		return fmt.Errorf("graph output fails to re-encode as JSON: %s", err)
+	}
+	if !bytes.Equal(got, want) {
+		return fmt.Errorf("graph output changes in a round trip through protobuf")
+	}
+	return nil
+}
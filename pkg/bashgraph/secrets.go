@@ -0,0 +1,119 @@
+package bashgraph
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// A secretPattern is the form of a kind of credential, which is flagged
+// wherever it is written in a script.
+type secretPattern struct {
+	rule string
+	desc string
+	re   *regexp.Regexp
+}
+
+var secretPatterns = []secretPattern{
+	{"aws-access-key", "an AWS access key ID", regexp.MustCompile(`\b(AKIA|ASIA)[0-9A-Z]{16}\b`)},
+	{"github-token", "a GitHub token", regexp.MustCompile(`\b(gh[pousr]_[A-Za-z0-9]{36}|github_pat_[A-Za-z0-9_]{22,})\b`)},
+	{"slack-token", "a Slack token", regexp.MustCompile(`\bxox[abprs]-[A-Za-z0-9-]{10,}`)},
+	{"private-key", "a private key", regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----`)},
+}
+
+// secretAssignmentRule is a literal value assigned to a variable whose name
+// says it holds a credential, as in export API_TOKEN=3f9a....
+const secretAssignmentRule = "secret-assignment"
+
+var (
+	// secretVarName matches the names of variables that hold credentials.
+	secretVarName = regexp.MustCompile(`(?i)(secret|token|passw(or)?d|api_?key|private_?key|access_?key|credential)`)
+	// secretRefVarName matches the names of variables that say where a
+	// credential is, rather than holding it, such as TOKEN_FILE.
+	secretRefVarName = regexp.MustCompile(`(?i)_(file|path|dir|url|name|env|var)$`)
+)
+
+// findSecrets adds a security annotation to output for each credential
+// written in f: each match of secretPatterns, and each literal value
+// assigned to a variable named like a credential. The annotations never
+// include the credentials themselves.
+func findSecrets(f *parsedFile, output *graphOutput) error {
+	var lines *lineIndex
+	add := func(finding *SecurityFinding) error {
+		if lines == nil {
+			lines = newLineIndex(f.data)
+		}
+		a, err := makeSecurityAnn(f.name, lines, finding)
+		if err != nil {
+			return fmt.Errorf("failed to create secret annotation: %s", err)
+		}
+		output.Anns = append(output.Anns, a)
+		return nil
+	}
+	for i, s := range f.scripts {
+		src := f.sources[i]
+		// matched records the spans of pattern matches, so that assignments
+		// of them aren't flagged twice.
+		var matched [][2]int
+		for _, p := range secretPatterns {
+			for _, m := range p.re.FindAllStringIndex(src.text, -1) {
+				matched = append(matched, [2]int{m[0], m[1]})
+				if err := add(&SecurityFinding{
+					Rule:     p.rule,
+					Severity: "high",
+					Message:  fmt.Sprintf("%s is written in the script", p.desc),
+					Start:    uint32(src.fileOffset(m[0])),
+					End:      uint32(src.fileEnd(m[1])),
+				}); err != nil {
+					return err
+				}
+			}
+		}
+
+		for _, v := range assignedValues(s) {
+			if !secretVarName.MatchString(v.name) || secretRefVarName.MatchString(v.name) {
+				continue
+			}
+			if !isSecretLiteral(src.text[v.start:v.end]) {
+				continue
+			}
+			overlaps := false
+			for _, m := range matched {
+				if m[0] < v.end && v.start < m[1] {
+					overlaps = true
+				}
+			}
+			if overlaps {
+				continue
+			}
+			if err := add(&SecurityFinding{
+				Rule:     secretAssignmentRule,
+				Severity: "medium",
+				Message:  fmt.Sprintf("a literal value is assigned to %s, which looks like a credential", v.name),
+				Start:    uint32(src.fileOffset(v.start)),
+				End:      uint32(src.fileEnd(v.end)),
+			}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// isSecretLiteral reports whether value, as written, is a literal that may
+// be a credential: it expands nothing and is not empty, short or an obvious
+// placeholder such as <token> or xxxxxxxx.
+func isSecretLiteral(value string) bool {
+	if strings.ContainsAny(value, "$`") {
+		return false
+	}
+	v := unquote(value)
+	if len(v) < 8 || strings.HasPrefix(v, "<") || strings.Trim(v, v[:1]) == "" {
+		return false
+	}
+	switch strings.ToLower(v) {
+	case "changeme", "password", "placeholder":
+		return false
+	}
+	return true
+}
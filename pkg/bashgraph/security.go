@@ -0,0 +1,253 @@
+package bashgraph
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"sourcegraph.com/sourcegraph/srclib/ann"
+)
+
+// securityAnnType is the type of annotations that carry a SecurityFinding.
+const securityAnnType = "security"
+
+// A SecurityFinding is a risky pattern found in a file, such as a script
+// downloaded and run without being checked, emitted as the Data of an
+// annotation.
+type SecurityFinding struct {
+	// Rule identifies the pattern, such as "pipe-to-shell".
+	Rule     string
+	Severity string
+	Message  string
+	// URL is the URL the code is downloaded from, if it is literal.
+	URL string `json:",omitempty"`
+	// Start and End are the byte offsets of the pattern in the file, from
+	// the download command to the end of the command that runs what it
+	// downloads.
+	Start uint32
+	End   uint32
+	// StartPos and EndPos are the line and column positions of Start and
+	// End. They are filled in by makeSecurityAnn.
+	StartPos Position
+	EndPos   Position
+}
+
+// The rules of security findings.
+const (
+	// pipeToShellRule is a download piped to a shell, as in
+	// curl -fsSL URL | bash.
+	pipeToShellRule = "pipe-to-shell"
+	// evalRemoteRule is a download run as a command string, as in
+	// eval "$(curl -s URL)" or sh -c "$(wget -O- URL)".
+	evalRemoteRule = "eval-remote"
+	// sourceRemoteRule is a download run through a process substitution,
+	// as in bash <(curl -s URL) or source <(curl -s URL).
+	sourceRemoteRule = "source-remote"
+)
+
+// shellNames are the shells that downloaded code is run with.
+var shellNames = map[string]bool{
+	"sh": true, "bash": true, "dash": true, "ksh": true, "mksh": true, "zsh": true,
+}
+
+// makeSecurityAnn returns an annotation for s, which was found in the named
+// file whose lines are indexed by li.
+func makeSecurityAnn(filename string, li *lineIndex, s *SecurityFinding) (*ann.Ann, error) {
+	var startLine, endLine int
+	s.StartPos, s.EndPos, startLine, endLine = spanLines(li, s.Start, s.End)
+	data, err := json.Marshal(s)
+	if err != nil {
+		return nil, err
+	}
+	return &ann.Ann{
+		UnitType:  "BashDirectory",
+		Unit:      "bash",
+		File:      filename,
+		StartLine: uint32(startLine),
+		EndLine:   uint32(endLine),
+		Type:      securityAnnType,
+		Data:      data,
+	}, nil
+}
+
+// findSecurityIssues adds a security annotation to output for each place
+// in f that downloads code and runs it: piping curl or wget to a shell,
+// evaluating their output, or running it through a process substitution.
+func findSecurityIssues(f *parsedFile, output *graphOutput) error {
+	var lines *lineIndex
+	for i, s := range f.scripts {
+		for _, cmd := range s.commands {
+			if !isDownloadToStdout(s.words, cmd) {
+				continue
+			}
+			finding := remoteRunFinding(s.words, cmd)
+			if finding == nil {
+				continue
+			}
+			if lines == nil {
+				lines = newLineIndex(f.data)
+			}
+			finding.URL = downloadURL(s.words, cmd)
+			finding.Start = uint32(f.sources[i].fileOffset(int(finding.Start)))
+			finding.End = uint32(f.sources[i].fileEnd(int(finding.End)))
+			a, err := makeSecurityAnn(f.name, lines, finding)
+			if err != nil {
+				return fmt.Errorf("failed to create security annotation: %s", err)
+			}
+			output.Anns = append(output.Anns, a)
+		}
+	}
+	return nil
+}
+
+// isDownloadToStdout reports whether the command named by cmd is curl or
+// wget writing what it downloads to standard output.
+func isDownloadToStdout(words []word, cmd word) bool {
+	args := commandArgs(words, cmd)
+	switch unquote(cmd.text) {
+	case "curl":
+		// curl writes to standard output unless told to write to a file.
+		for _, a := range args {
+			a := unquote(a.text)
+			switch {
+			case a == "--output" || strings.HasPrefix(a, "--output=") || strings.HasPrefix(a, "--remote-name"):
+				return false
+			case len(a) > 1 && a[0] == '-' && a[1] != '-' && strings.ContainsAny(a, "oO"):
+				return false
+			}
+		}
+		return true
+	case "wget":
+		// wget writes to standard output with -O - or -O /dev/stdout.
+		for i, a := range args {
+			a := unquote(a.text)
+			var file string
+			switch {
+			case a == "--output-document" || len(a) > 1 && a[0] == '-' && a[1] != '-' && strings.HasSuffix(a, "O"):
+				if i+1 < len(args) {
+					file = unquote(args[i+1].text)
+				}
+			case strings.HasPrefix(a, "--output-document="):
+				file = strings.TrimPrefix(a, "--output-document=")
+			case len(a) > 2 && a[0] == '-' && a[1] != '-' && strings.Contains(a, "O"):
+				file = a[strings.Index(a, "O")+1:]
+			}
+			if file == "-" || file == "/dev/stdout" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// remoteRunFinding returns the finding for the download command named by
+// cmd if what it downloads is run as shell code, with the offsets of its
+// span in the same text as words', or nil.
+func remoteRunFinding(words []word, cmd word) *SecurityFinding {
+	ws := enclosingWords(words, cmd)
+	for i, w := range ws {
+		if w.start != cmd.start {
+			continue
+		}
+		// Find the command the download is piped to.
+		j := i + 1
+		for j < len(ws) && !ws[j].isControlOp() {
+			j++
+		}
+		if j+1 >= len(ws) || ws[j].text != "|" && ws[j].text != "|&" {
+			break
+		}
+		runner, args := runCommand(ws, ws[j+1])
+		if !shellNames[runner] {
+			break
+		}
+		end := ws[j+1].end
+		if len(args) > 0 {
+			end = args[len(args)-1].end
+		}
+		return &SecurityFinding{
+			Rule:     pipeToShellRule,
+			Severity: "high",
+			Message:  fmt.Sprintf("%s output is piped to %s, which runs downloaded code without verifying it", unquote(cmd.text), runner),
+			Start:    uint32(cmd.start),
+			End:      uint32(end),
+		}
+	}
+
+	// Find the substitution the download is in, and the command it is an
+	// argument of.
+	for _, c := range commandWords(words) {
+		runner, args := runCommand(words, c)
+		for k, a := range args {
+			if a.start >= cmd.start || a.end < cmd.end {
+				continue
+			}
+			f := &SecurityFinding{Severity: "high", Start: uint32(c.start), End: uint32(a.end)}
+			switch {
+			case isProcessSubstitution(a) && (shellNames[runner] || runner == "source" || runner == "."):
+				f.Rule = sourceRemoteRule
+				f.Message = fmt.Sprintf("%s runs code downloaded by %s without verifying it", runner, unquote(cmd.text))
+			case isProcessSubstitution(a):
+				continue
+			case runner == "eval" || shellNames[runner] && k > 0 && unquote(args[k-1].text) == "-c":
+				f.Rule = evalRemoteRule
+				f.Message = fmt.Sprintf("%s runs the output of %s, which is downloaded code, without verifying it", runner, unquote(cmd.text))
+			default:
+				continue
+			}
+			return f
+		}
+	}
+	return nil
+}
+
+// enclosingWords returns the words of the innermost command substitution
+// in words that contains cmd, or words if cmd is not in one.
+func enclosingWords(words []word, cmd word) []word {
+	for _, w := range words {
+		if w.start >= cmd.start || w.end < cmd.end {
+			continue
+		}
+		for _, sub := range substitutionWords(w) {
+			if len(sub) > 0 && sub[0].start <= cmd.start && cmd.end <= sub[len(sub)-1].end {
+				return enclosingWords(sub, cmd)
+			}
+		}
+	}
+	return words
+}
+
+// runCommand returns the name and arguments of the command that the
+// command named by cmd runs, skipping sudo and env and their options and
+// assignments, as in sudo -E bash -s.
+func runCommand(words []word, cmd word) (string, []word) {
+	name, args := unquote(cmd.text), commandArgs(words, cmd)
+	for name == "sudo" || name == "env" {
+		i := 0
+		for i < len(args) && (strings.HasPrefix(args[i].text, "-") || isAssignment(args[i].text)) {
+			i++
+		}
+		if i == len(args) {
+			return name, nil
+		}
+		name, args = unquote(args[i].text), args[i+1:]
+	}
+	return name, args
+}
+
+// downloadURL returns the first literal URL among the arguments of the
+// download command named by cmd, or "".
+func downloadURL(words []word, cmd word) string {
+	for _, a := range commandArgs(words, cmd) {
+		if strings.ContainsAny(a.text, "$`") {
+			continue
+		}
+		u := unquote(a.text)
+		for _, scheme := range []string{"https://", "http://", "ftp://"} {
+			if strings.HasPrefix(u, scheme) {
+				return u
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,105 @@
+package bashgraph
+
+import (
+	"fmt"
+)
+
+type SelftestCmd struct{}
+
+// selftestScript is the fixture script that selftest graphs.
+const selftestScript = `#!/bin/bash
+# Greet someone loudly.
+GREETING="hello"
+
+greet() {
+	local name=$1
+	echo "$GREETING, $name"
+}
+
+greet world | tr a-z A-Z
+`
+
+// selftestExpected is the graph output expected for selftestScript, one
+// def or ref per line, as formatted by selftestLines.
+var selftestExpected = []string{
+	"def selftest.sh 0-0",
+	"def selftest.sh/$GREETING 36-44",
+	"def selftest.sh/greet 54-59",
+	"def selftest.sh/greet/$name 71-75",
+	"def-ref selftest.sh/$GREETING 36-44",
+	"def-ref selftest.sh/greet 54-59",
+	"def-ref selftest.sh/greet/$name 71-75",
+	"ref github.com/sourcegraph/man-pages-posix:man1p/echo.1p.txt/echo 80-84",
+	"ref selftest.sh/$GREETING 87-95",
+	"ref selftest.sh/greet/$name 98-102",
+	"ref selftest.sh/greet 107-112",
+	"ref github.com/sourcegraph/man-pages-posix:man1p/tr.1p.txt/tr 121-123",
+}
+
+func (c *SelftestCmd) Execute(args []string) error {
+	got, err := selftestLines()
+	if err != nil {
+		return fmt.Errorf("Self-test failed: %s", err)
+	}
+	if missing, unexpected := diffLineSets(selftestExpected, got); len(missing) > 0 || len(unexpected) > 0 {
+		for _, l := range missing {
+			fmt.Println("missing:    " + l)
+		}
+		for _, l := range unexpected {
+			fmt.Println("unexpected: " + l)
+		}
+		return fmt.Errorf("Self-test failed: the graph output differs from the expected output")
+	}
+	fmt.Printf("ok: %d defs and refs graphed as expected\n", len(got))
+	return nil
+}
+
+// selftestLines graphs selftestScript and returns its defs and refs, one
+// per line.
+func selftestLines() ([]string, error) {
+	f, err := parseData("selftest.sh", []byte(selftestScript))
+	if err != nil {
+		return nil, err
+	}
+	output := newGraphOutput()
+	if err := graphFile(f, newUnitIndex([]*parsedFile{f}), output); err != nil {
+		return nil, err
+	}
+	sortOutput(&output.Output)
+
+	var lines []string
+	for _, def := range output.Defs {
+		lines = append(lines, fmt.Sprintf("def %s %d-%d", def.Path, def.DefStart, def.DefEnd))
+	}
+	for _, ref := range output.Refs {
+		kind := "ref"
+		if ref.Def {
+			kind = "def-ref"
+		}
+		lines = append(lines, fmt.Sprintf("%s %s %d-%d", kind, refTarget(ref), ref.Start, ref.End))
+	}
+	return lines, nil
+}
+
+// diffLineSets returns the lines of want that are not in got and the lines
+// of got that are not in want.
+func diffLineSets(want, got []string) (missing, unexpected []string) {
+	count := map[string]int{}
+	for _, l := range got {
+		count[l]++
+	}
+	for _, l := range want {
+		if count[l] > 0 {
+			count[l]--
+			continue
+		}
+		missing = append(missing, l)
+	}
+	for _, l := range got {
+		if count[l] > 0 {
+			count[l]--
+			unexpected = append(unexpected, l)
+		}
+	}
+	return missing, unexpected
+}
@@ -0,0 +1,244 @@
+package bashgraph
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+
+	"sourcegraph.com/sourcegraph/srclib/unit"
+)
+
+type ServeCmd struct {
+	Socket string `long:"socket" description:"listen on the unix socket PATH instead of serving STDIN and STDOUT" value-name:"PATH"`
+}
+
+func (c *ServeCmd) Execute(args []string) error {
+	s := &server{cache: newParseCache()}
+	if c.Socket == "" {
+		return s.serve(os.Stdin, os.Stdout)
+	}
+
+	l, err := net.Listen("unix", c.Socket)
+	if err != nil {
+		return fmt.Errorf("Failed to listen on %s: %s", c.Socket, err)
+	}
+	defer l.Close()
+	// Closing the listener on SIGINT or SIGTERM removes the socket.
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+	stopped := make(chan struct{})
+	go func() {
+		<-stop
+		close(stopped)
+		l.Close()
+	}()
+	logInfof("Listening on %s", c.Socket)
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			select {
+			case <-stopped:
+				return nil
+			default:
+			}
+			return fmt.Errorf("Failed to accept connection: %s", err)
+		}
+		go func() {
+			defer conn.Close()
+			if err := s.serve(conn, conn); err != nil {
+				logWarnf("Connection failed: %s", err)
+			}
+		}()
+	}
+}
+
+// A server handles JSON-RPC requests. The scan and graph commands keep
+// their settings in package variables, so it handles one request at a time,
+// even when several connections are open.
+type server struct {
+	mu sync.Mutex
+	// cache holds the files that were parsed already, for all requests.
+	cache *parseCache
+}
+
+// rpcRequest and rpcResponse are JSON-RPC 2.0 messages. A request without
+// an ID is a notification, which gets no response.
+type rpcRequest struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+type rpcResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	// Result is the JSON encoding of the result, which is null rather
+	// than absent when the method succeeds without one.
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  *rpcError       `json:"error,omitempty"`
+}
+
+// newRPCResponse returns the response to the request with the given ID
+// that returned result and rerr.
+func newRPCResponse(id json.RawMessage, result interface{}, rerr *rpcError) *rpcResponse {
+	resp := &rpcResponse{JSONRPC: "2.0", ID: id, Error: rerr}
+	if rerr == nil {
+		data, err := json.Marshal(result)
+		if err != nil {
+			resp.Error = &rpcError{rpcInternalError, fmt.Sprintf("Failed to encode result: %s", err)}
+		} else {
+			resp.Result = data
+		}
+	}
+	return resp
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// The JSON-RPC error codes that the server returns.
+const (
+	rpcParseError     = -32700
+	rpcMethodNotFound = -32601
+	rpcInvalidParams  = -32602
+	rpcInternalError  = -32603
+)
+
+// serve reads requests from r, one per line, and writes the responses to w,
+// until r ends.
+func (s *server) serve(r io.Reader, w io.Writer) error {
+	in := bufio.NewReader(r)
+	enc := json.NewEncoder(w)
+	for {
+		line, err := in.ReadBytes('\n')
+		if len(bytes.TrimSpace(line)) > 0 {
+			if resp := s.handle(line); resp != nil {
+				if err := enc.Encode(resp); err != nil {
+					return fmt.Errorf("Failed to write response: %s", err)
+				}
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("Failed to read request: %s", err)
+		}
+	}
+}
+
+// handle returns the response to the request in data, or nil if it is a
+// notification.
+func (s *server) handle(data []byte) *rpcResponse {
+	var req rpcRequest
+	if err := json.Unmarshal(data, &req); err != nil {
+		return &rpcResponse{JSONRPC: "2.0", ID: json.RawMessage("null"), Error: &rpcError{rpcParseError, err.Error()}}
+	}
+	result, rerr := s.call(req.Method, req.Params)
+	if len(req.ID) == 0 {
+		return nil
+	}
+	return newRPCResponse(req.ID, result, rerr)
+}
+
+// scanParams are the params of a scan request.
+type scanParams struct {
+	// Config is the source tree config, as scan reads from STDIN.
+	Config map[string]interface{}
+}
+
+// graphParams are the params of a graph request: the source units to graph,
+// as graph reads from STDIN, or the name and contents of a file to graph
+// alone, as with graph --filename.
+type graphParams struct {
+	Units    unit.SourceUnits
+	Filename string
+	Contents string
+}
+
+// call runs the named method with params and returns its result.
+func (s *server) call(method string, params json.RawMessage) (interface{}, *rpcError) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch method {
+	case "scan":
+		var p scanParams
+		if err := unmarshalParams(params, &p); err != nil {
+			return nil, err
+		}
+		config, err := stringConfig(p.Config)
+		if err != nil {
+			return nil, &rpcError{rpcInvalidParams, err.Error()}
+		}
+		dir, err := currentDir()
+		if err != nil {
+			return nil, &rpcError{rpcInternalError, err.Error()}
+		}
+		units, err := new(ScanCmd).scanUnits(dir, config)
+		if err != nil {
+			return nil, &rpcError{rpcInternalError, err.Error()}
+		}
+		return units, nil
+	case "graph":
+		var p graphParams
+		if err := unmarshalParams(params, &p); err != nil {
+			return nil, err
+		}
+		out, err := s.graph(p)
+		if err != nil {
+			return nil, &rpcError{rpcInternalError, err.Error()}
+		}
+		return out, nil
+	}
+	return nil, &rpcError{rpcMethodNotFound, fmt.Sprintf("unknown method %q", method)}
+}
+
+// graph graphs the units or file given in p, as the graph command does with
+// its default settings.
+func (s *server) graph(p graphParams) (*positionedOutput, error) {
+	opts := commandOptions()
+	opts.cache = s.cache
+	var out *graphOutput
+	var err error
+	if p.Filename != "" {
+		if err := opts.applySettings(nil); err != nil {
+			return nil, err
+		}
+		if out, err = graphBuffer(p.Filename, []byte(p.Contents), opts); err != nil {
+			return nil, fmt.Errorf("Failed to graph %s: %s", p.Filename, err)
+		}
+	} else {
+		if len(p.Units) == 0 {
+			return nil, fmt.Errorf("Request contains no source unit data.")
+		}
+		if err := opts.applySettings(unitsConfig(p.Units)); err != nil {
+			return nil, err
+		}
+		if out, err = graphUnits(p.Units, opts); err != nil {
+			return nil, fmt.Errorf("Failed to graph source units: %s", err)
+		}
+	}
+	return withPositions(out, opts)
+}
+
+// unmarshalParams decodes the params of a request into v. Missing params
+// leave v empty.
+func unmarshalParams(params json.RawMessage, v interface{}) *rpcError {
+	if len(params) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(params, v); err != nil {
+		return &rpcError{rpcInvalidParams, err.Error()}
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package bashgraph
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestServe(t *testing.T) {
+	requests := strings.Join([]string{
+		`{"jsonrpc":"2.0","id":1,"method":"graph","params":{"Filename":"x.sh","Contents":"f() { :; }\nf\n"}}`,
+		`{"jsonrpc":"2.0","method":"graph","params":{"Filename":"y.sh","Contents":""}}`,
+		`{"jsonrpc":"2.0","id":2,"method":"index"}`,
+		`not json`,
+	}, "\n")
+	var out bytes.Buffer
+	if err := (&server{}).serve(strings.NewReader(requests), &out); err != nil {
+		t.Fatal(err)
+	}
+
+	// The notification gets no response.
+	dec := json.NewDecoder(&out)
+	var graph struct {
+		ID     int
+		Result positionedOutput
+	}
+	if err := dec.Decode(&graph); err != nil {
+		t.Fatal(err)
+	}
+	if graph.ID != 1 || len(graph.Result.Refs) != 2 {
+		t.Errorf("got response %d with %d refs, want response 1 with 2 refs", graph.ID, len(graph.Result.Refs))
+	}
+	for _, want := range []int{rpcMethodNotFound, rpcParseError} {
+		var resp rpcResponse
+		if err := dec.Decode(&resp); err != nil {
+			t.Fatal(err)
+		}
+		if resp.Error == nil || resp.Error.Code != want {
+			t.Errorf("got error %+v, want code %d", resp.Error, want)
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package bashgraph
+
+import (
+	"bytes"
+	"sort"
+)
+
+// A source is a piece of shell code to graph. Most sources are whole
+// scripts, but some are extracted from files in other formats (such as the
+// scripts section of a package.json). smap translates offsets in text to
+// offsets in the host file; a nil smap means text is the whole file.
+type source struct {
+	text string
+	smap sourceMap
+	// end is the offset in the host file just past the source, such as
+	// the offset of a closing quote.
+	end int
+}
+
+// A sourceMap maps the text of an extracted source back to its host file,
+// as a list of segments in text order.
+type sourceMap []sourceSegment
+
+// A sourceSegment maps text[text:text+textLen] to the span of the host
+// file that starts at host and is hostLen bytes long. Text that was copied
+// verbatim maps byte for byte; decoded text, such as the character an
+// escape sequence stands for, maps as a whole to the escape sequence.
+type sourceSegment struct {
+	text, textLen int
+	host, hostLen int
+}
+
+func (seg *sourceSegment) verbatim() bool {
+	return seg.textLen == seg.hostLen
+}
+
+// segment returns the segment of m containing offset i of the text, or nil
+// if there is none.
+func (m sourceMap) segment(i int) *sourceSegment {
+	k := sort.Search(len(m), func(k int) bool { return m[k].text+m[k].textLen > i })
+	if k == len(m) || m[k].text > i {
+		return nil
+	}
+	return &m[k]
+}
+
+// fileOffset translates an offset in s.text, where a span starts, to an
+// offset in the host file.
+func (s *source) fileOffset(i int) int {
+	if s.smap == nil {
+		return i
+	}
+	seg := s.smap.segment(i)
+	switch {
+	case seg == nil:
+		return s.end
+	case seg.verbatim():
+		return seg.host + i - seg.text
+	}
+	return seg.host
+}
+
+// fileEnd translates an offset in s.text, where a span ends, to an offset in
+// the host file. Unlike fileOffset, it maps the end of decoded text to the
+// end of what it was decoded from, and doesn't extend a span ending at the
+// end of a segment over the gap to the next one.
+func (s *source) fileEnd(i int) int {
+	if s.smap == nil || i == 0 {
+		return s.fileOffset(i)
+	}
+	seg := s.smap.segment(i - 1)
+	switch {
+	case seg == nil:
+		return s.end
+	case seg.verbatim():
+		return seg.host + i - seg.text
+	}
+	return seg.host + seg.hostLen
+}
+
+// shift moves s n bytes further into its host file.
+func (s *source) shift(n int) {
+	if s.smap == nil {
+		s.smap = sourceMap{{textLen: len(s.text), hostLen: len(s.text)}}
+		s.end = len(s.text)
+	}
+	for k := range s.smap {
+		s.smap[k].host += n
+	}
+	s.end += n
+}
+
+// A sourceBuilder assembles a source from pieces of a host file.
+type sourceBuilder struct {
+	text bytes.Buffer
+	smap sourceMap
+	end  int
+}
+
+// add appends data[start:end] of the host file to the source.
+func (b *sourceBuilder) add(data []byte, start, end int) {
+	b.text.Write(data[start:end])
+	b.addSegment(end-start, start, end-start)
+}
+
+// addByte appends c to the source as if it were found at offset off.
+func (b *sourceBuilder) addByte(c byte, off int) {
+	b.text.WriteByte(c)
+	b.addSegment(1, off, 1)
+}
+
+// addDecoded appends text to the source as the decoding of the span
+// start:end of the host file, such as an escape sequence. A zero-length
+// span inserts text that does not appear in the host file, such as a
+// separator between joined lines.
+func (b *sourceBuilder) addDecoded(text string, start, end int) {
+	b.text.WriteString(text)
+	b.addSegment(len(text), start, end-start)
+}
+
+func (b *sourceBuilder) addSegment(textLen, host, hostLen int) {
+	if textLen == 0 {
+		return
+	}
+	seg := sourceSegment{text: b.text.Len() - textLen, textLen: textLen, host: host, hostLen: hostLen}
+	if n := len(b.smap); n > 0 && seg.verbatim() {
+		// Merge runs of verbatim text to keep the map small.
+		last := &b.smap[n-1]
+		if last.verbatim() && last.host+last.hostLen == host {
+			last.textLen += textLen
+			last.hostLen += hostLen
+			b.end = host + hostLen
+			return
+		}
+	}
+	b.smap = append(b.smap, seg)
+	b.end = host + hostLen
+}
+
+func (b *sourceBuilder) source() *source {
+	smap := b.smap
+	if smap == nil {
+		// An empty source extracted from another file still needs a map,
+		// so that it is not mistaken for a whole file.
+		smap = sourceMap{}
+	}
+	return &source{text: b.text.String(), smap: smap, end: b.end}
+}
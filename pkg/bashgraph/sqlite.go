@@ -0,0 +1,180 @@
+package bashgraph
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"sourcegraph.com/sourcegraph/srclib/unit"
+)
+
+// sqliteSchema creates the tables of a SQLite index, replacing any that
+// exist. Each row holds the columns that queries look up and, in json, the
+// def, ref, doc or annotation as graph outputs it, so no field is lost.
+const sqliteSchema = `
+DROP TABLE IF EXISTS units;
+DROP TABLE IF EXISTS defs;
+DROP TABLE IF EXISTS refs;
+DROP TABLE IF EXISTS docs;
+DROP TABLE IF EXISTS anns;
+CREATE TABLE units (name TEXT, type TEXT, json TEXT);
+CREATE TABLE defs (
+	unit_type TEXT, unit TEXT, path TEXT, name TEXT, kind TEXT,
+	file TEXT, start_offset INTEGER, end_offset INTEGER,
+	start_line INTEGER, start_column INTEGER, end_line INTEGER, end_column INTEGER,
+	exported INTEGER, json TEXT
+);
+CREATE INDEX defs_name ON defs (name);
+CREATE INDEX defs_path ON defs (unit, path);
+CREATE INDEX defs_span ON defs (file, start_offset, end_offset);
+CREATE TABLE refs (
+	def_repo TEXT, def_unit_type TEXT, def_unit TEXT, def_path TEXT,
+	unit_type TEXT, unit TEXT, is_def INTEGER,
+	file TEXT, start_offset INTEGER, end_offset INTEGER,
+	start_line INTEGER, start_column INTEGER, end_line INTEGER, end_column INTEGER,
+	json TEXT
+);
+CREATE INDEX refs_def ON refs (def_path, def_unit, def_repo);
+CREATE INDEX refs_span ON refs (file, start_offset, end_offset);
+CREATE TABLE docs (
+	unit_type TEXT, unit TEXT, path TEXT, format TEXT, data TEXT,
+	file TEXT, start_offset INTEGER, end_offset INTEGER, json TEXT
+);
+CREATE INDEX docs_path ON docs (unit, path);
+CREATE INDEX docs_span ON docs (file, start_offset, end_offset);
+CREATE TABLE anns (
+	unit_type TEXT, unit TEXT, type TEXT,
+	file TEXT, start_line INTEGER, end_line INTEGER, data TEXT, json TEXT
+);
+CREATE INDEX anns_type ON anns (type, file);
+`
+
+// errNoSQLite is the error of opening a database when srclib-bash was built
+// without SQLite support.
+var errNoSQLite = errors.New("srclib-bash was built without SQLite support; build it with -tags sqlite, which requires cgo")
+
+// openSQLite opens the SQLite database in the named file, creating it if
+// it doesn't exist.
+func openSQLite(name string) (*sql.DB, error) {
+	if sqliteDriver == "" {
+		return nil, fmt.Errorf("Failed to open database %s: %s", name, errNoSQLite)
+	}
+	db, err := sql.Open(sqliteDriver, name)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to open database %s: %s", name, err)
+	}
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("Failed to open database %s: %s", name, err)
+	}
+	return db, nil
+}
+
+// writeSQLite writes the units and their graph output to the SQLite
+// database in the named file, replacing the index it holds.
+func writeSQLite(name string, units []*unit.SourceUnit, outs ...*positionedOutput) error {
+	db, err := openSQLite(name)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	if _, err := db.Exec(sqliteSchema); err != nil {
+		return fmt.Errorf("Failed to create tables in %s: %s", name, err)
+	}
+	return updateSQLite(db, units, nil, outs...)
+}
+
+// updateSQLite adds units and the defs, refs, docs and annotations of outs to db, in one
+// transaction, after deleting those in the given files.
+func updateSQLite(db *sql.DB, units []*unit.SourceUnit, files []string, outs ...*positionedOutput) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("Failed to write index: %s", err)
+	}
+	if err := insertSQLite(tx, units, files, outs); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("Failed to write index: %s", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("Failed to write index: %s", err)
+	}
+	return nil
+}
+
+func insertSQLite(tx *sql.Tx, units []*unit.SourceUnit, files []string, outs []*positionedOutput) error {
+	for _, file := range files {
+		for _, table := range []string{"defs", "refs", "docs", "anns"} {
+			if _, err := tx.Exec("DELETE FROM "+table+" WHERE file = ?", file); err != nil {
+				return err
+			}
+		}
+	}
+	for _, u := range units {
+		data, err := json.Marshal(u)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec("INSERT INTO units VALUES (?, ?, ?)", u.Name, u.Type, string(data)); err != nil {
+			return err
+		}
+	}
+
+	insertDef, err := tx.Prepare("INSERT INTO defs VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)")
+	if err != nil {
+		return err
+	}
+	insertRef, err := tx.Prepare("INSERT INTO refs VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)")
+	if err != nil {
+		return err
+	}
+	insertDoc, err := tx.Prepare("INSERT INTO docs VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)")
+	if err != nil {
+		return err
+	}
+	insertAnn, err := tx.Prepare("INSERT INTO anns VALUES (?, ?, ?, ?, ?, ?, ?, ?)")
+	if err != nil {
+		return err
+	}
+	for _, out := range outs {
+		for _, d := range out.Defs {
+			data, err := json.Marshal(d)
+			if err != nil {
+				return err
+			}
+			if _, err := insertDef.Exec(d.UnitType, d.Unit, d.Path, d.Name, d.Kind, d.File, d.DefStart, d.DefEnd,
+				d.StartPos.Line, d.StartPos.Column, d.EndPos.Line, d.EndPos.Column, d.Exported, string(data)); err != nil {
+				return err
+			}
+		}
+		for _, r := range out.Refs {
+			data, err := json.Marshal(r)
+			if err != nil {
+				return err
+			}
+			if _, err := insertRef.Exec(r.DefRepo, r.DefUnitType, r.DefUnit, r.DefPath, r.UnitType, r.Unit, r.Def, r.File, r.Start, r.End,
+				r.StartPos.Line, r.StartPos.Column, r.EndPos.Line, r.EndPos.Column, string(data)); err != nil {
+				return err
+			}
+		}
+		for _, d := range out.Docs {
+			data, err := json.Marshal(d)
+			if err != nil {
+				return err
+			}
+			if _, err := insertDoc.Exec(d.UnitType, d.Unit, d.Path, d.Format, d.Data, d.File, d.Start, d.End, string(data)); err != nil {
+				return err
+			}
+		}
+		for _, a := range out.Anns {
+			data, err := json.Marshal(a)
+			if err != nil {
+				return err
+			}
+			if _, err := insertAnn.Exec(a.UnitType, a.Unit, a.Type, a.File, a.StartLine, a.EndLine, string(a.Data), string(data)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
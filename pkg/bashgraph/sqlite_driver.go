@@ -0,0 +1,10 @@
+//go:build sqlite
+// +build sqlite
+
+package bashgraph
+
+import _ "github.com/mattn/go-sqlite3"
+
+// sqliteDriver is the name of the database/sql driver that opens SQLite
+// databases. The driver uses cgo, so it is only built with the sqlite tag.
+const sqliteDriver = "sqlite3"
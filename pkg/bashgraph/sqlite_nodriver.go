@@ -0,0 +1,8 @@
+//go:build !sqlite
+// +build !sqlite
+
+package bashgraph
+
+// sqliteDriver is empty when srclib-bash is built without the sqlite tag,
+// so that it builds without cgo; opening a database then fails.
+const sqliteDriver = ""
@@ -0,0 +1,82 @@
+//go:build sqlite
+// +build sqlite
+
+package bashgraph
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"sourcegraph.com/sourcegraph/srclib/unit"
+)
+
+func TestSQLite(t *testing.T) {
+	dir, err := ioutil.TempDir("", "srclib-bash-sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	name := filepath.Join(dir, "index.db")
+
+	opts := testOptions()
+	if err := opts.applySettings(nil); err != nil {
+		t.Fatal(err)
+	}
+	u := &unit.SourceUnit{
+		Key:  unit.Key{Name: "bash", Type: "BashDirectory"},
+		Info: unit.Info{Files: []string{filepath.Join("testdata", "graph", "functions.sh"), filepath.Join("testdata", "graph", "security.sh")}},
+	}
+	out, err := graphUnits(unit.SourceUnits{u}, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pout, err := withPositions(out, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Writing twice replaces the index rather than adding to it.
+	for i := 0; i < 2; i++ {
+		if err := writeSQLite(name, []*unit.SourceUnit{u}, pout); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	db, err := openSQLite(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	c := &QueryCmd{}
+	c.Args.Pattern = "deploy-*"
+	defs, err := c.query(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(defs) != 1 || defs[0].Text != "func deploy-app" {
+		t.Fatalf("got defs %+v, want deploy-app", defs)
+	}
+	c.Refs = true
+	refs, err := c.query(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := 0
+	for _, r := range pout.Refs {
+		if r.DefPath == "testdata/graph/functions.sh/deploy-app" && !r.Def {
+			want++
+		}
+	}
+	if want == 0 || len(refs) != want {
+		t.Errorf("got %d refs to deploy-app, want %d", len(refs), want)
+	}
+
+	var n int
+	if err := db.QueryRow("SELECT COUNT(*) FROM anns WHERE type = ? AND file = ?", securityAnnType, "testdata/graph/security.sh").Scan(&n); err != nil {
+		t.Fatal(err)
+	}
+	if n != 8 {
+		t.Errorf("got %d security annotations, want 8", n)
+	}
+}
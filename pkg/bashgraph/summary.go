@@ -0,0 +1,117 @@
+package bashgraph
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// ANSI escape sequences for the colors of the summary table.
+const (
+	ansiBold   = "\x1b[1m"
+	ansiYellow = "\x1b[33m"
+	ansiReset  = "\x1b[0m"
+)
+
+// A fileSummary is a row of the table printed by graph --summary.
+type fileSummary struct {
+	file                   string
+	defs, refs, docs, warn int
+	elapsed                time.Duration
+}
+
+// printSummary prints a table of the numbers of defs, refs, docs and
+// warnings in out for each file, and the time it took to graph the file,
+// followed by the totals. With color, the header and totals are bold and
+// nonzero warning counts are yellow.
+func printSummary(w io.Writer, out *graphOutput, color bool) {
+	rows := map[string]*fileSummary{}
+	row := func(file string) *fileSummary {
+		r, ok := rows[file]
+		if !ok {
+			r = &fileSummary{file: file, elapsed: out.elapsed[file]}
+			rows[file] = r
+		}
+		return r
+	}
+	for _, def := range out.Defs {
+		r := row(def.File)
+		if def.Kind != "script" {
+			r.defs++
+		}
+	}
+	for _, ref := range out.Refs {
+		row(ref.File).refs++
+	}
+	for _, doc := range out.Docs {
+		row(doc.File).docs++
+	}
+	for _, warning := range out.warnings {
+		row(warning.File).warn++
+	}
+
+	var files []string
+	for file := range rows {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+	total := &fileSummary{file: "total"}
+	table := [][]string{{"FILE", "DEFS", "REFS", "DOCS", "WARNINGS", "TIME"}}
+	cells := func(r *fileSummary) []string {
+		return []string{r.file, fmt.Sprint(r.defs), fmt.Sprint(r.refs), fmt.Sprint(r.docs), fmt.Sprint(r.warn), r.elapsed.Round(time.Microsecond).String()}
+	}
+	for _, file := range files {
+		r := rows[file]
+		table = append(table, cells(r))
+		total.defs += r.defs
+		total.refs += r.refs
+		total.docs += r.docs
+		total.warn += r.warn
+		total.elapsed += r.elapsed
+	}
+	table = append(table, cells(total))
+
+	widths := make([]int, len(table[0]))
+	for _, cs := range table {
+		for i, c := range cs {
+			if n := utf8.RuneCountInString(c); n > widths[i] {
+				widths[i] = n
+			}
+		}
+	}
+	for n, cs := range table {
+		bold := color && (n == 0 || n == len(table)-1)
+		var line []string
+		for i, c := range cs {
+			// The file names are aligned left and the numbers right.
+			pad := strings.Repeat(" ", widths[i]-utf8.RuneCountInString(c))
+			if i == 0 {
+				c += pad
+			} else {
+				c = pad + c
+			}
+			switch {
+			case bold:
+				c = ansiBold + c + ansiReset
+			case color && i == 4 && cs[i] != "0":
+				c = ansiYellow + c + ansiReset
+			}
+			line = append(line, c)
+		}
+		fmt.Fprintln(w, strings.Join(line, "  "))
+	}
+}
+
+// isColorTerminal reports whether f is a terminal that output to it may be
+// colored for, which it isn't if the NO_COLOR environment variable is set.
+func isColorTerminal(f *os.File) bool {
+	if os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
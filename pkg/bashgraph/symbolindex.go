@@ -0,0 +1,121 @@
+package bashgraph
+
+import (
+	"sort"
+
+	"sourcegraph.com/sourcegraph/srclib/graph"
+)
+
+// A SymbolIndex indexes the defs and refs of graph output, of one source
+// unit or several, for lookups by name, def, file and position, so queries
+// don't scan the whole output. The refs, symbols and lsp commands answer
+// their queries with it; it is built once a unit is graphed, so graphing
+// itself resolves names with the unitIndex. It must not be modified once
+// built, and may be shared by goroutines.
+type SymbolIndex struct {
+	defs       []*graph.Def
+	defsByName map[string][]*graph.Def
+	defsByKey  map[graph.DefKey]*graph.Def
+	defsByFile map[string][]*graph.Def
+	refsByDef  map[graph.DefKey][]*graph.Ref
+	refsByFile map[string][]*graph.Ref
+}
+
+// NewSymbolIndex returns the index of defs and refs. The defs and refs in
+// each file are ordered by position.
+func NewSymbolIndex(defs []*graph.Def, refs []*graph.Ref) *SymbolIndex {
+	x := &SymbolIndex{
+		defs:       defs,
+		defsByName: map[string][]*graph.Def{},
+		defsByKey:  map[graph.DefKey]*graph.Def{},
+		defsByFile: map[string][]*graph.Def{},
+		refsByDef:  map[graph.DefKey][]*graph.Ref{},
+		refsByFile: map[string][]*graph.Ref{},
+	}
+	for _, def := range defs {
+		x.defsByName[def.Name] = append(x.defsByName[def.Name], def)
+		if key := symbolKey(def.DefKey); x.defsByKey[key] == nil {
+			x.defsByKey[key] = def
+		}
+		x.defsByFile[def.File] = append(x.defsByFile[def.File], def)
+	}
+	for _, ref := range refs {
+		key := symbolKey(ref.DefKey())
+		x.refsByDef[key] = append(x.refsByDef[key], ref)
+		x.refsByFile[ref.File] = append(x.refsByFile[ref.File], ref)
+	}
+	for _, defs := range x.defsByFile {
+		sort.Sort(defsByStart(defs))
+	}
+	for _, refs := range x.refsByFile {
+		sort.Sort(refsByStart(refs))
+	}
+	return x
+}
+
+// symbolKey returns key without its commit, which graph output leaves
+// unset for defs and refs alike.
+func symbolKey(key graph.DefKey) graph.DefKey {
+	key.CommitID = ""
+	return key
+}
+
+// Defs returns all the defs, in the order they were given.
+func (x *SymbolIndex) Defs() []*graph.Def {
+	return x.defs
+}
+
+// DefsNamed returns the defs with the given name, such as the functions
+// named name in several scripts.
+func (x *SymbolIndex) DefsNamed(name string) []*graph.Def {
+	return x.defsByName[name]
+}
+
+// Def returns the def with the given key, or nil. A key without a Repo
+// names a def in the indexed output.
+func (x *SymbolIndex) Def(key graph.DefKey) *graph.Def {
+	return x.defsByKey[symbolKey(key)]
+}
+
+// DefOf returns the def that ref links to, or nil if it isn't indexed, as
+// when it is in another repository or is a man page.
+func (x *SymbolIndex) DefOf(ref *graph.Ref) *graph.Def {
+	if ref.DefRepo != "" {
+		return nil
+	}
+	return x.Def(ref.DefKey())
+}
+
+// DefsIn returns the defs in the named file.
+func (x *SymbolIndex) DefsIn(file string) []*graph.Def {
+	return x.defsByFile[file]
+}
+
+// RefsTo returns the refs to the def with the given key, including those
+// that define it.
+func (x *SymbolIndex) RefsTo(key graph.DefKey) []*graph.Ref {
+	return x.refsByDef[symbolKey(key)]
+}
+
+// RefsIn returns the refs in the named file.
+func (x *SymbolIndex) RefsIn(file string) []*graph.Ref {
+	return x.refsByFile[file]
+}
+
+// RefAt returns the ref in the named file whose span contains offset, the
+// narrowest if several do, or nil.
+func (x *SymbolIndex) RefAt(file string, offset uint32) *graph.Ref {
+	refs := x.refsByFile[file]
+	// Refs starting after offset can't contain it.
+	n := sort.Search(len(refs), func(i int) bool { return refs[i].Start > offset })
+	var found *graph.Ref
+	for _, ref := range refs[:n] {
+		if offset >= ref.End {
+			continue
+		}
+		if found == nil || ref.End-ref.Start < found.End-found.Start {
+			found = ref
+		}
+	}
+	return found
+}
@@ -0,0 +1,43 @@
+package bashgraph
+
+import (
+	"testing"
+
+	"sourcegraph.com/sourcegraph/srclib/graph"
+)
+
+func TestSymbolIndex(t *testing.T) {
+	r, err := AnalyzeFile("lib.sh", []byte("greet() { echo hi; }\ngreet\nx=1\necho \"$x\" greet\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	x := r.Index()
+
+	defs := x.DefsNamed("greet")
+	if len(defs) != 1 || defs[0].Path != "lib.sh/greet" {
+		t.Fatalf("got defs named greet %v, want lib.sh/greet", defs)
+	}
+	key := graph.DefKey{UnitType: "BashDirectory", Unit: "bash", Path: "lib.sh/greet"}
+	if x.Def(key) != defs[0] {
+		t.Errorf("Def(%v) is not the def named greet", key)
+	}
+	refs := x.RefsTo(key)
+	if len(refs) != 2 || !refs[0].Def || refs[1].Def {
+		t.Errorf("got refs to greet %v, want its def ref and one call", refs)
+	}
+
+	// The call on line 2 starts at offset 21.
+	ref := x.RefAt("lib.sh", 23)
+	if ref == nil || ref.DefPath != "lib.sh/greet" || x.DefOf(ref) != defs[0] {
+		t.Errorf("got ref %v at offset 23, want the call of greet", ref)
+	}
+	if ref := x.RefAt("lib.sh", 20); ref != nil {
+		t.Errorf("got ref %v at the newline at offset 20, want none", ref)
+	}
+	if n := len(x.DefsIn("lib.sh")); n != len(r.Defs) {
+		t.Errorf("got %d defs in lib.sh, want %d", n, len(r.Defs))
+	}
+	if n := len(x.DefsIn("other.sh")); n != 0 {
+		t.Errorf("got %d defs in other.sh, want none", n)
+	}
+}
@@ -0,0 +1,122 @@
+package bashgraph
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+
+	"sourcegraph.com/sourcegraph/srclib/graph"
+)
+
+type SymbolsCmd struct {
+	Regexp  bool `short:"e" long:"regexp" description:"interpret the pattern as a regular expression"`
+	JSON    bool `long:"json" description:"output symbols as JSON"`
+	NoCache bool `long:"no-cache" description:"always graph the source units read from STDIN"`
+	Args    struct {
+		Pattern string `positional-arg-name:"PATTERN"`
+	} `positional-args:"yes"`
+}
+
+// A Symbol is a def found by the symbols command.
+type Symbol struct {
+	Name    string
+	Kind    string
+	DefPath string
+	File    string
+	Start   uint32
+	End     uint32
+	Line    int
+	Column  int
+}
+
+func (c *SymbolsCmd) Execute(args []string) error {
+	match, err := c.matcher()
+	if err != nil {
+		return err
+	}
+
+	out, err := loadGraphOutput(c.NoCache)
+	if err != nil {
+		return err
+	}
+
+	idx := NewSymbolIndex(out.Defs, out.Refs)
+	defs := idx.Defs()
+	if p := c.Args.Pattern; p != "" && !c.Regexp && !strings.ContainsAny(p, `*?[\`) {
+		// A pattern without wildcards is a name to look up.
+		defs = idx.DefsNamed(p)
+	}
+	symbols, err := findSymbols(defs, match)
+	if err != nil {
+		return err
+	}
+
+	if c.JSON {
+		if err := json.NewEncoder(os.Stdout).Encode(symbols); err != nil {
+			return fmt.Errorf("Failed to output symbols: %s", err)
+		}
+		return nil
+	}
+	for _, s := range symbols {
+		fmt.Printf("%s:%d:%d: %s %s\n", s.File, s.Line, s.Column, s.Kind, s.Name)
+	}
+	return nil
+}
+
+// matcher returns a function that reports whether a def name matches the
+// pattern.
+func (c *SymbolsCmd) matcher() (func(string) bool, error) {
+	pattern := c.Args.Pattern
+	if pattern == "" {
+		return func(string) bool { return true }, nil
+	}
+	if c.Regexp {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern: %s", err)
+		}
+		return re.MatchString, nil
+	}
+	if _, err := path.Match(pattern, ""); err != nil {
+		return nil, fmt.Errorf("invalid pattern: %s", err)
+	}
+	return func(name string) bool {
+		ok, _ := path.Match(pattern, name)
+		return ok
+	}, nil
+}
+
+func findSymbols(defs []*graph.Def, match func(string) bool) ([]*Symbol, error) {
+	var symbols []*Symbol
+	files := map[string]*lineIndex{}
+	for _, def := range defs {
+		if !match(def.Name) {
+			continue
+		}
+		li, ok := files[def.File]
+		if !ok {
+			data, err := ioutil.ReadFile(def.File)
+			if err != nil {
+				return nil, fmt.Errorf("Failed to read file %s: %s", def.File, err)
+			}
+			li = newLineIndex(data)
+			files[def.File] = li
+		}
+		line, col := li.position(int(def.DefStart))
+		symbols = append(symbols, &Symbol{
+			Name:    def.Name,
+			Kind:    def.Kind,
+			DefPath: def.Path,
+			File:    def.File,
+			Start:   def.DefStart,
+			End:     def.DefEnd,
+			Line:    line,
+			Column:  col,
+		})
+	}
+	return symbols, nil
+}
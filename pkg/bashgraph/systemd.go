@@ -0,0 +1,74 @@
+package bashgraph
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// systemdExecKeys are the settings of a systemd service whose values are
+// command lines.
+var systemdExecKeys = map[string]bool{
+	"ExecStart":     true,
+	"ExecStartPre":  true,
+	"ExecStartPost": true,
+	"ExecReload":    true,
+	"ExecStop":      true,
+	"ExecStopPost":  true,
+}
+
+// isSystemdServiceFile reports whether path is a systemd service unit.
+func isSystemdServiceFile(path string) bool {
+	return filepath.Ext(path) == ".service"
+}
+
+// systemdExecSources returns the command line of every Exec setting in a
+// systemd service unit as a shell source. Lines continued with a trailing
+// backslash are joined.
+func systemdExecSources(data []byte) []*source {
+	var sources []*source
+	start := 0
+	for start < len(data) {
+		end := start
+		for end < len(data) && data[end] != '\n' {
+			end++
+		}
+		i := skipBlanks(data, start, end)
+		eq := strings.IndexByte(string(data[i:end]), '=')
+		if eq < 0 || !systemdExecKeys[strings.TrimSpace(string(data[i:i+eq]))] {
+			start = end + 1
+			continue
+		}
+
+		// Skip the prefixes that modify how the command is run.
+		i = skipBlanks(data, i+eq+1, end)
+		for i < end && strings.IndexByte("@-:+!", data[i]) >= 0 {
+			i++
+		}
+
+		var b sourceBuilder
+		for {
+			lineEnd := end
+			if lineEnd > i && data[lineEnd-1] == '\r' {
+				lineEnd--
+			}
+			if lineEnd > i && data[lineEnd-1] == '\\' {
+				b.add(data, i, lineEnd-1)
+				b.addDecoded(" ", lineEnd-1, lineEnd)
+				if end >= len(data) {
+					break
+				}
+				i = end + 1
+				end = i
+				for end < len(data) && data[end] != '\n' {
+					end++
+				}
+				continue
+			}
+			b.add(data, i, lineEnd)
+			break
+		}
+		sources = append(sources, b.source())
+		start = end + 1
+	}
+	return sources
+}
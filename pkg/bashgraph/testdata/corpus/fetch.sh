@@ -0,0 +1,21 @@
+#!/bin/sh
+# fetch.sh clones the projects listed in projects.txt into DIR, for
+# go test -corpus DIR. Projects already in DIR are left as they are.
+set -e
+
+if [ $# -ne 1 ]; then
+	echo "usage: $0 DIR" >&2
+	exit 2
+fi
+dir=$1
+list=$(dirname "$0")/projects.txt
+
+mkdir -p "$dir"
+grep -v '^#' "$list" | while read -r name repo rev; do
+	[ -n "$name" ] || continue
+	if [ -d "$dir/$name" ]; then
+		echo "$name: already fetched" >&2
+		continue
+	fi
+	git clone --quiet --depth 1 --branch "$rev" "$repo" "$dir/$name"
+done
@@ -0,0 +1,6 @@
+#!/bin/bash
+# Aliases and sourced files.
+
+. ./vars.sh
+alias ll='ls -l'
+ll /tmp
@@ -0,0 +1,21 @@
+#!/bin/bash
+mapfile -t lines < /etc/hosts
+readarray -d '' -t entries < <(find . -print0)
+echo "${#lines[@]} ${entries[0]}"
+
+coproc WORKER { while read -r l; do echo "$l"; done; }
+echo hello >&"${WORKER[1]}"
+read -r reply <&"${WORKER[0]}"
+wait "$WORKER_PID"
+
+coproc bc -l
+echo "1+1" >&"${COPROC[1]}"
+
+settings=(a b)
+pick() {
+  declare -n out=$1
+  local -n src=settings
+  out=${src[0]}
+}
+pick reply
+echo "$reply"
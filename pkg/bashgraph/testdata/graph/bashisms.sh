@@ -0,0 +1,14 @@
+#!/bin/sh
+# Written for sh, but uses Bash features.
+
+declare -A ports
+ports[web]=8080
+files=(*.conf)
+name=${1,,}
+readarray -t lines < input.txt
+if [[ -n $name ]]; then
+  echo "${name/-/_}" "${name@Q}" &>> build.log
+fi
+exec {log}>build.log
+for i in {1..3}; do :; done
+printf '%s\n' "${ports[web]}" "${name:0:3}" "$EPOCHSECONDS"
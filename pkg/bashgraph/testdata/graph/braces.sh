@@ -0,0 +1,10 @@
+#!/bin/bash
+cp file.{txt,bak}
+mkdir -p build/{bin,lib,share/{man,doc}}
+for i in {1..10}; do echo $i; done
+echo {a..z..2}
+touch {sort,join}.log
+mv {cat,head}/x /tmp
+{ ls; }
+f() { echo in; }
+cp /etc/{passwd,group} .
@@ -0,0 +1,11 @@
+#!/bin/bash
+# Commands and their options, across control operators, substitutions and
+# redirections.
+
+tar -xzf archive.tgz -C /tmp 2>&1 | tee -a log
+if grep -q pattern file; then sort -u file; else wc -l file; fi
+files=$(find . -name '*.sh' | xargs ls -l)
+diff <(sort -u a) <(sort -u b) >/dev/null
+time -p make -j4 build
+exec >log 2>&1
+grep foo <<< "cat $files"
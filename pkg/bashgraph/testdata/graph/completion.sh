@@ -0,0 +1,11 @@
+#!/bin/bash
+mytool() {
+  echo run "$@"
+}
+
+_mytool() {
+  COMPREPLY=( $(compgen -W "start stop" -- "${COMP_WORDS[COMP_CWORD]}") )
+}
+
+complete -o default -F _mytool mytool mt
+complete -W "a b" other
@@ -0,0 +1,17 @@
+#!/bin/bash
+COUNT=0
+VAR=x
+limit=10
+file=/etc/hosts
+if [[ -n $VAR && $COUNT -gt 3 ]]; then echo big; fi
+[[ -f $file && -r "$file" ]] || exit 1
+[[ $VAR =~ ^[a-z]+$ ]] && echo word
+[[ -d /tmp && -x /usr/bin/sort ]] && echo dirs
+(( COUNT++ ))
+(( total = COUNT * (limit + 1) ))
+if (( COUNT > limit )); then echo over; fi
+for (( i = 0; i < limit; i++ )); do echo $i; done
+echo $total
+(( a <= b ))
+(( c != d ))
+(( e <<= 2 ))
@@ -0,0 +1,12 @@
+#!/bin/bash
+# Loads its configuration from dotenv files.
+
+set -a
+. config/settings.env
+set +a
+source config/.env
+
+echo "Starting in $APP_ENV on port $PORT"
+psql "$DATABASE_URL" -c 'select 1'
+echo "$GREETING" >&2
+echo "$LOG_LEVEL $UNSET_VAR"
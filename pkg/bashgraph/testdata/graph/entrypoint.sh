@@ -0,0 +1,12 @@
+#!/bin/bash
+set -euo pipefail
+
+usage() { echo "usage: $0 FILE" >&2; }
+
+main() {
+  [[ $# -eq 1 ]] || { usage; exit 2; }
+  wc -l "$1"
+}
+
+[[ "${BASH_SOURCE[0]}" != "$0" ]] && return
+main "$@"
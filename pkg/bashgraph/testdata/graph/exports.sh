@@ -0,0 +1,22 @@
+#!/bin/bash
+process() { echo "processing $1"; }
+helper() { :; }
+report() { :; }
+
+export -f process
+declare -fx report
+find . -name '*.txt' -print0 | xargs -0 -n1 bash -c 'process "$0"'
+helper
+
+# Names given with -f or -F are functions, not variables.
+declare -F helper
+typeset -f report >/dev/null
+
+# export -n removes the export attribute; only declare, typeset and local
+# make namerefs with -n. Options end at the first operand or at --.
+export -n EDITOR=vim
+configure() {
+  local -- level=-ng
+  echo "$level $EDITOR"
+}
+configure
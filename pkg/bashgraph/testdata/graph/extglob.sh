@@ -0,0 +1,15 @@
+#!/bin/bash
+shopt -s extglob globstar
+rm -f !(keep).log
+cp @(foo|bar).txt /tmp
+ls +([0-9]).csv *([a-z])?(x) !(*.sh|*.bash)
+for f in **/*.md; do wc -l "$f"; done
+case $name in
+  @(start|stop)) echo run ;;
+  !(*.tmp)) echo other ;;
+  +(a)|?(b)) echo ab ;;
+esac
+[[ $name == @(yes|y) ]] && echo ok
+cat !(sort)
+if !(grep -q x glob.sh); then echo none; fi
+! (true) && echo never
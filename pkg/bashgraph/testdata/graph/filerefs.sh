@@ -0,0 +1,15 @@
+#!/bin/bash
+# Runs the other scripts and reads the configuration.
+
+./functions.sh
+bash aliases.sh --verbose
+grep -v '^#' config/settings.env
+while read -r line; do
+  echo "$line"
+done < ./config/settings.env
+envsubst --config=config/settings.env
+
+# Not files in the repository.
+cat /etc/hosts
+./missing.sh
+cat config/*.env
@@ -0,0 +1,27 @@
+#!/bin/bash
+# Functions, including composite names, and calls to them.
+
+log::info() {
+	echo "info: $*"
+}
+
+function deploy-app {
+	local target=$1
+	log::info "deploying to $target"
+	mod.init
+}
+
+mod.init() {
+	mkdir -p /tmp/app && cd /tmp/app
+}
+
+deploy-app production
+
+# Prints a greeting for the user named by $1.
+#
+# Names such as <admin> are printed as is.
+greet_user() {
+  # The number of greetings so far.
+  declare -i greeted=1
+  echo "Hello, $1 ($greeted)"
+}
@@ -0,0 +1,9 @@
+#!/bin/bash
+init() {
+  declare -g CONFIG_DIR="$HOME/.app"
+  declare -gA SETTINGS=([a]=1)
+  local tmp=1
+  typeset -g -r VERSION=2
+}
+init
+echo "$CONFIG_DIR ${SETTINGS[a]} $VERSION $tmp"
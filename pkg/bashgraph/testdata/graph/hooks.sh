@@ -0,0 +1,14 @@
+#!/bin/bash
+update_prompt() { PS1="> "; }
+_on_err() { echo "failed" >&2; }
+cleanup() { rm -f "$tmp"; }
+log_cmd() { :; }
+
+PROMPT_COMMAND=update_prompt
+PROMPT_COMMAND="history -a; update_prompt"
+PROMPT_COMMAND+=(log_cmd)
+trap '_on_err' ERR
+trap "cleanup; exit" INT TERM
+trap cleanup EXIT
+precmd_functions+=(update_prompt)
+add-zsh-hook chpwd log_cmd
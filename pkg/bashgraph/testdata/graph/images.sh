@@ -0,0 +1,22 @@
+#!/bin/bash
+# Builds and tests the app in containers.
+
+docker pull golang:1.21
+docker run --rm -v "$PWD":/src -w /src golang:1.21 go test ./...
+podman container create --name=cache redis@sha256:2f1b7e8a6c4d3e5f60718293a4b5c6d7e8f90a1b2c3d4e5f60718293a4b5c6d7
+docker run -it ghcr.io/acme/tools:latest
+docker run "$IMAGE"
+
+cat > Dockerfile <<'DOCKERFILE'
+ARG VERSION=1.21
+FROM golang:${VERSION} AS build
+FROM node:20-alpine as assets
+FROM --platform=linux/amd64 debian:bookworm-slim
+COPY --from=build /app /app
+FROM build
+DOCKERFILE
+
+psql <<SQL
+SELECT id
+FROM users
+SQL
@@ -0,0 +1,13 @@
+#!/bin/bash
+# Commands named in variables and eval strings.
+
+EDITOR=vi
+RSYNC="rsync -a"
+$RSYNC src/ dest/
+
+cleanup() {
+	rm -rf /tmp/work
+}
+
+eval 'cleanup; ls -l'
+eval "$RSYNC --delete"
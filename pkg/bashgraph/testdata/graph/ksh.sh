@@ -0,0 +1,27 @@
+#!/bin/ksh
+typeset -i count=0
+typeset -u NAME
+typeset -r -x VERSION=1.2
+typeset -A table
+integer total=5
+float ratio=0.5
+
+function bump {
+  typeset -i step=$1
+  total=$((total + step))
+}
+
+function report
+{
+  print -r -- "$NAME $count $VERSION ${table[x]}"
+  print -u2 "total=$total ratio=$ratio"
+}
+
+bump 2
+report
+whence -v bump
+posix_style() {
+  typeset shared=1
+}
+posix_style
+echo $shared
@@ -0,0 +1,13 @@
+#!/bin/bash
+# Fetches a release and deploys it to the web servers.
+
+curl -fsSL -o app.tgz "https://releases.example.com/app/$VERSION.tgz"
+wget -q https://mirror.example.org/app.sha256
+for host in web1 web2; do
+  scp -i ~/.ssh/deploy app.tgz "deploy@$host:/srv/app/"
+  ssh -p 2222 deploy@bastion.example.com "ssh $host tar -xzf /srv/app/app.tgz"
+done
+rsync -az ./static/ deploy@cdn.example.net:/var/www/static/
+rsync -a ./build/ ./dist/
+dig +short @8.8.8.8 releases.example.com A
+nc -z -w 3 db.internal 5432
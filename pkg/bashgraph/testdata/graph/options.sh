@@ -0,0 +1,9 @@
+#!/usr/bin/env bash
+set -euo pipefail
+shopt -s nullglob globstar
+
+for f in **/*.log; do
+  set +e
+  gzip "$f"
+  set -e
+done
@@ -0,0 +1,14 @@
+#!/bin/bash
+# Installs a service, which requires root.
+
+sudo apt-get install -y nginx
+install -m 0644 app.conf /etc/nginx/conf.d/app.conf
+chown -R root:root /opt/app
+setcap cap_net_bind_service=+ep /opt/app/bin/server
+echo "127.0.0.1 app.local" >> /etc/hosts
+echo "$PATH" | tee /usr/local/share/app/path >/dev/null
+
+# Not privileged.
+cp app.conf /var/tmp/app.conf
+chown "$USER" build
+echo done > "$LOG_DIR/install.log"
@@ -0,0 +1,10 @@
+#!/bin/bash
+NAME=x
+IFS=$'\n\t'
+msg=$'it\'s a $NAME cat\x41 sort'
+printf $'%s\n' "$NAME"
+grep $'\t' file
+echo $"Hello $NAME, sort these"
+sep=$'\'' ; ls
+tr $'\\' /
+$'\x6cs' -l
@@ -0,0 +1,7 @@
+#!/bin/sh
+# A stray control character on the second command line is skipped to the
+# end of its line, and the rest of the file is still graphed.
+
+ls -l
+foo  cat x
+grep -r y .
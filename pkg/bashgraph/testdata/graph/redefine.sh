@@ -0,0 +1,21 @@
+#!/bin/bash
+# A function defined differently depending on the platform, and one
+# redefined later in the file. Both definitions of each share a def.
+
+if [ "$(uname)" = Darwin ]; then
+  open_url() {
+    local url=$1
+    open "$url"
+  }
+else
+  open_url() {
+    local url=$1
+    xdg-open "$url"
+  }
+fi
+
+log() { echo "$*"; }
+log() { echo "$(date): $*" >&2; }
+
+open_url "https://example.com"
+log done
@@ -0,0 +1,18 @@
+#!/bin/bash
+# Installers that download code and run it.
+
+curl -fsSL https://get.example.com/install.sh | bash
+curl -sSL "https://example.com/setup.sh" | sudo -E bash -s -- --yes
+wget -qO- http://example.com/bootstrap.sh | sh
+wget -O - "$MIRROR/bootstrap.sh" | sudo sh
+
+eval "$(curl -s https://example.com/env.sh)"
+sh -c "$(wget -O- https://example.com/install.sh)"
+bash <(curl -fsSL https://example.com/install.sh)
+source <(curl -s https://example.com/lib.sh)
+
+# Downloads that are not run.
+curl -fsSLo install.sh https://get.example.com/install.sh
+wget -q https://example.com/bootstrap.sh
+curl -s https://example.com/data.json | jq .
+version=$(curl -s https://example.com/VERSION)
@@ -0,0 +1,15 @@
+#!/bin/sh
+# Global and local variables, expansions and arithmetic.
+
+COUNT=0
+NAME="world"
+
+greet() {
+	local prefix="Hello"
+	echo "$prefix, ${NAME}!"
+	COUNT=$((COUNT + 1))
+}
+
+read -r date
+greet
+echo "$COUNT $date"
@@ -0,0 +1,36 @@
+#!/usr/bin/env zsh
+autoload -Uz compinit colors
+setopt extended_glob no_beep
+typeset -g ZCACHE=$HOME/.zcache
+typeset -gA HOOKS
+local -a files
+
+() {
+  local tmp=$1
+  echo $tmp
+} /tmp
+
+function greet() {
+  print -P "%F{green}$1%f"
+}
+
+build_list() {
+  files=( **/*.sh(.N) )
+  for f in *.zsh(N.om[1,3]); do
+    greet $f
+  done
+  echo ${files:t} ${(j:,:)files} $ZCACHE
+}
+
+build_list
+
+function {
+  local scratch=$TMPDIR
+  echo $scratch
+}
+
+function start stop {
+  print $0
+}
+
+start; stop
@@ -0,0 +1,113 @@
+// Code generated by gen_manpages.go from tldrpages.txt; DO NOT EDIT.
+
+package bashgraph
+
+// tldrPages maps command names to the pages that document them.
+var tldrPages = map[string]manPage{
+	"apt":        {"github.com/tldr-pages/tldr", "pages/linux/apt.md"},
+	"apt-get":    {"github.com/tldr-pages/tldr", "pages/linux/apt-get.md"},
+	"awk":        {"github.com/tldr-pages/tldr", "pages/common/awk.md"},
+	"base64":     {"github.com/tldr-pages/tldr", "pages/common/base64.md"},
+	"basename":   {"github.com/tldr-pages/tldr", "pages/common/basename.md"},
+	"bc":         {"github.com/tldr-pages/tldr", "pages/common/bc.md"},
+	"blkid":      {"github.com/tldr-pages/tldr", "pages/linux/blkid.md"},
+	"cat":        {"github.com/tldr-pages/tldr", "pages/common/cat.md"},
+	"cd":         {"github.com/tldr-pages/tldr", "pages/common/cd.md"},
+	"chgrp":      {"github.com/tldr-pages/tldr", "pages/common/chgrp.md"},
+	"chmod":      {"github.com/tldr-pages/tldr", "pages/common/chmod.md"},
+	"chown":      {"github.com/tldr-pages/tldr", "pages/common/chown.md"},
+	"cmp":        {"github.com/tldr-pages/tldr", "pages/common/cmp.md"},
+	"comm":       {"github.com/tldr-pages/tldr", "pages/common/comm.md"},
+	"cp":         {"github.com/tldr-pages/tldr", "pages/common/cp.md"},
+	"crontab":    {"github.com/tldr-pages/tldr", "pages/common/crontab.md"},
+	"curl":       {"github.com/tldr-pages/tldr", "pages/common/curl.md"},
+	"cut":        {"github.com/tldr-pages/tldr", "pages/common/cut.md"},
+	"date":       {"github.com/tldr-pages/tldr", "pages/common/date.md"},
+	"dd":         {"github.com/tldr-pages/tldr", "pages/common/dd.md"},
+	"df":         {"github.com/tldr-pages/tldr", "pages/common/df.md"},
+	"diff":       {"github.com/tldr-pages/tldr", "pages/common/diff.md"},
+	"dirname":    {"github.com/tldr-pages/tldr", "pages/common/dirname.md"},
+	"dnf":        {"github.com/tldr-pages/tldr", "pages/linux/dnf.md"},
+	"docker":     {"github.com/tldr-pages/tldr", "pages/common/docker.md"},
+	"du":         {"github.com/tldr-pages/tldr", "pages/common/du.md"},
+	"echo":       {"github.com/tldr-pages/tldr", "pages/common/echo.md"},
+	"env":        {"github.com/tldr-pages/tldr", "pages/common/env.md"},
+	"expr":       {"github.com/tldr-pages/tldr", "pages/common/expr.md"},
+	"fdisk":      {"github.com/tldr-pages/tldr", "pages/linux/fdisk.md"},
+	"file":       {"github.com/tldr-pages/tldr", "pages/common/file.md"},
+	"find":       {"github.com/tldr-pages/tldr", "pages/common/find.md"},
+	"free":       {"github.com/tldr-pages/tldr", "pages/linux/free.md"},
+	"git":        {"github.com/tldr-pages/tldr", "pages/common/git.md"},
+	"grep":       {"github.com/tldr-pages/tldr", "pages/common/grep.md"},
+	"gzip":       {"github.com/tldr-pages/tldr", "pages/common/gzip.md"},
+	"head":       {"github.com/tldr-pages/tldr", "pages/common/head.md"},
+	"hostname":   {"github.com/tldr-pages/tldr", "pages/common/hostname.md"},
+	"id":         {"github.com/tldr-pages/tldr", "pages/common/id.md"},
+	"ip":         {"github.com/tldr-pages/tldr", "pages/linux/ip.md"},
+	"iptables":   {"github.com/tldr-pages/tldr", "pages/linux/iptables.md"},
+	"journalctl": {"github.com/tldr-pages/tldr", "pages/linux/journalctl.md"},
+	"jq":         {"github.com/tldr-pages/tldr", "pages/common/jq.md"},
+	"kill":       {"github.com/tldr-pages/tldr", "pages/common/kill.md"},
+	"less":       {"github.com/tldr-pages/tldr", "pages/common/less.md"},
+	"ln":         {"github.com/tldr-pages/tldr", "pages/common/ln.md"},
+	"ls":         {"github.com/tldr-pages/tldr", "pages/common/ls.md"},
+	"lsblk":      {"github.com/tldr-pages/tldr", "pages/linux/lsblk.md"},
+	"lsmod":      {"github.com/tldr-pages/tldr", "pages/linux/lsmod.md"},
+	"lsof":       {"github.com/tldr-pages/tldr", "pages/common/lsof.md"},
+	"make":       {"github.com/tldr-pages/tldr", "pages/common/make.md"},
+	"man":        {"github.com/tldr-pages/tldr", "pages/common/man.md"},
+	"mkdir":      {"github.com/tldr-pages/tldr", "pages/common/mkdir.md"},
+	"mktemp":     {"github.com/tldr-pages/tldr", "pages/common/mktemp.md"},
+	"modprobe":   {"github.com/tldr-pages/tldr", "pages/linux/modprobe.md"},
+	"mv":         {"github.com/tldr-pages/tldr", "pages/common/mv.md"},
+	"nc":         {"github.com/tldr-pages/tldr", "pages/common/nc.md"},
+	"nohup":      {"github.com/tldr-pages/tldr", "pages/common/nohup.md"},
+	"od":         {"github.com/tldr-pages/tldr", "pages/common/od.md"},
+	"paste":      {"github.com/tldr-pages/tldr", "pages/common/paste.md"},
+	"patch":      {"github.com/tldr-pages/tldr", "pages/common/patch.md"},
+	"ping":       {"github.com/tldr-pages/tldr", "pages/common/ping.md"},
+	"printf":     {"github.com/tldr-pages/tldr", "pages/common/printf.md"},
+	"ps":         {"github.com/tldr-pages/tldr", "pages/common/ps.md"},
+	"pwd":        {"github.com/tldr-pages/tldr", "pages/common/pwd.md"},
+	"readlink":   {"github.com/tldr-pages/tldr", "pages/common/readlink.md"},
+	"realpath":   {"github.com/tldr-pages/tldr", "pages/common/realpath.md"},
+	"reboot":     {"github.com/tldr-pages/tldr", "pages/linux/reboot.md"},
+	"rm":         {"github.com/tldr-pages/tldr", "pages/common/rm.md"},
+	"rmdir":      {"github.com/tldr-pages/tldr", "pages/common/rmdir.md"},
+	"rsync":      {"github.com/tldr-pages/tldr", "pages/common/rsync.md"},
+	"scp":        {"github.com/tldr-pages/tldr", "pages/common/scp.md"},
+	"sed":        {"github.com/tldr-pages/tldr", "pages/common/sed.md"},
+	"seq":        {"github.com/tldr-pages/tldr", "pages/common/seq.md"},
+	"service":    {"github.com/tldr-pages/tldr", "pages/linux/service.md"},
+	"sha256sum":  {"github.com/tldr-pages/tldr", "pages/common/sha256sum.md"},
+	"shuf":       {"github.com/tldr-pages/tldr", "pages/common/shuf.md"},
+	"shutdown":   {"github.com/tldr-pages/tldr", "pages/linux/shutdown.md"},
+	"sleep":      {"github.com/tldr-pages/tldr", "pages/common/sleep.md"},
+	"sort":       {"github.com/tldr-pages/tldr", "pages/common/sort.md"},
+	"split":      {"github.com/tldr-pages/tldr", "pages/common/split.md"},
+	"ss":         {"github.com/tldr-pages/tldr", "pages/linux/ss.md"},
+	"ssh":        {"github.com/tldr-pages/tldr", "pages/common/ssh.md"},
+	"stat":       {"github.com/tldr-pages/tldr", "pages/common/stat.md"},
+	"sysctl":     {"github.com/tldr-pages/tldr", "pages/linux/sysctl.md"},
+	"systemctl":  {"github.com/tldr-pages/tldr", "pages/linux/systemctl.md"},
+	"tail":       {"github.com/tldr-pages/tldr", "pages/common/tail.md"},
+	"tar":        {"github.com/tldr-pages/tldr", "pages/common/tar.md"},
+	"tee":        {"github.com/tldr-pages/tldr", "pages/common/tee.md"},
+	"test":       {"github.com/tldr-pages/tldr", "pages/common/test.md"},
+	"top":        {"github.com/tldr-pages/tldr", "pages/common/top.md"},
+	"touch":      {"github.com/tldr-pages/tldr", "pages/common/touch.md"},
+	"tr":         {"github.com/tldr-pages/tldr", "pages/common/tr.md"},
+	"uname":      {"github.com/tldr-pages/tldr", "pages/common/uname.md"},
+	"uniq":       {"github.com/tldr-pages/tldr", "pages/common/uniq.md"},
+	"unzip":      {"github.com/tldr-pages/tldr", "pages/common/unzip.md"},
+	"useradd":    {"github.com/tldr-pages/tldr", "pages/linux/useradd.md"},
+	"userdel":    {"github.com/tldr-pages/tldr", "pages/linux/userdel.md"},
+	"usermod":    {"github.com/tldr-pages/tldr", "pages/linux/usermod.md"},
+	"wc":         {"github.com/tldr-pages/tldr", "pages/common/wc.md"},
+	"wget":       {"github.com/tldr-pages/tldr", "pages/common/wget.md"},
+	"which":      {"github.com/tldr-pages/tldr", "pages/common/which.md"},
+	"whoami":     {"github.com/tldr-pages/tldr", "pages/common/whoami.md"},
+	"xargs":      {"github.com/tldr-pages/tldr", "pages/common/xargs.md"},
+	"yum":        {"github.com/tldr-pages/tldr", "pages/linux/yum.md"},
+	"zip":        {"github.com/tldr-pages/tldr", "pages/common/zip.md"},
+}
@@ -0,0 +1,239 @@
+package bashgraph
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// parseTOML parses the subset of TOML that the global config file uses:
+// comments, [table] and [table.subtable] headers, and key = value pairs
+// whose values are strings, integers, booleans or arrays of them, which
+// may span several lines. Tables are returned as nested maps.
+func parseTOML(data []byte) (map[string]interface{}, error) {
+	root := map[string]interface{}{}
+	table := root
+	lines := strings.Split(string(data), "\n")
+	for n := 0; n < len(lines); n++ {
+		lineNum := n + 1
+		line := strings.TrimSpace(stripTOMLComment(lines[n]))
+		if line == "" {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") {
+			if !strings.HasSuffix(line, "]") || strings.HasPrefix(line, "[[") {
+				return nil, fmt.Errorf("line %d: invalid table header %s", lineNum, line)
+			}
+			keys, err := tomlDottedKey(line[1 : len(line)-1])
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %s", lineNum, err)
+			}
+			table = root
+			for _, key := range keys {
+				sub, ok := table[key].(map[string]interface{})
+				if !ok {
+					if _, exists := table[key]; exists {
+						return nil, fmt.Errorf("line %d: %s is not a table", lineNum, key)
+					}
+					sub = map[string]interface{}{}
+					table[key] = sub
+				}
+				table = sub
+			}
+			continue
+		}
+
+		key, rest, err := tomlKey(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %s", lineNum, err)
+		}
+		rest = strings.TrimLeft(rest, " \t")
+		if !strings.HasPrefix(rest, "=") {
+			return nil, fmt.Errorf("line %d: expected key = value", lineNum)
+		}
+		value := strings.TrimSpace(rest[1:])
+		// An array continues until its brackets balance.
+		for strings.HasPrefix(value, "[") && !tomlBalanced(value) && n+1 < len(lines) {
+			n++
+			value += " " + strings.TrimSpace(stripTOMLComment(lines[n]))
+		}
+		v, rest, err := tomlValue(value)
+		if err == nil && strings.TrimSpace(rest) != "" {
+			err = fmt.Errorf("unexpected %s after value", strings.TrimSpace(rest))
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %s", lineNum, err)
+		}
+		if _, exists := table[key]; exists {
+			return nil, fmt.Errorf("line %d: duplicate key %s", lineNum, key)
+		}
+		table[key] = v
+	}
+	return root, nil
+}
+
+// tomlKey returns the bare or quoted key at the start of s, and the rest
+// of s.
+func tomlKey(s string) (string, string, error) {
+	s = strings.TrimLeft(s, " \t")
+	if strings.HasPrefix(s, `"`) || strings.HasPrefix(s, "'") {
+		v, rest, err := tomlValue(s)
+		if err != nil {
+			return "", "", fmt.Errorf("invalid key %s: %s", s, err)
+		}
+		return v.(string), rest, nil
+	}
+	end := strings.IndexFunc(s, func(ch rune) bool {
+		return !(ch == '_' || ch == '-' || ch >= 'a' && ch <= 'z' || ch >= 'A' && ch <= 'Z' || ch >= '0' && ch <= '9')
+	})
+	if end < 0 {
+		end = len(s)
+	}
+	if end == 0 {
+		if s == "" || s[0] == '=' || s[0] == '.' {
+			return "", "", fmt.Errorf("missing key")
+		}
+		return "", "", fmt.Errorf("invalid key %s", s)
+	}
+	return s[:end], s[end:], nil
+}
+
+// tomlDottedKey returns the keys of the dotted key s, as in a table header
+// such as commands."deploy.sh", whose quoted keys may contain dots.
+func tomlDottedKey(s string) ([]string, error) {
+	var keys []string
+	for {
+		key, rest, err := tomlKey(s)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+		rest = strings.TrimLeft(rest, " \t")
+		if rest == "" {
+			return keys, nil
+		}
+		if rest[0] != '.' {
+			return nil, fmt.Errorf("invalid key %s", strings.TrimSpace(s))
+		}
+		s = rest[1:]
+	}
+}
+
+// tomlValue parses the value at the start of s, returning it and the rest
+// of s.
+func tomlValue(s string) (interface{}, string, error) {
+	s = strings.TrimLeft(s, " \t")
+	switch {
+	case s == "":
+		return nil, "", fmt.Errorf("missing value")
+	case s[0] == '"':
+		var b strings.Builder
+		for i := 1; i < len(s); i++ {
+			switch s[i] {
+			case '"':
+				return b.String(), s[i+1:], nil
+			case '\\':
+				if i+1 == len(s) {
+					return nil, "", fmt.Errorf("unterminated string")
+				}
+				i++
+				switch s[i] {
+				case 'n':
+					b.WriteByte('\n')
+				case 't':
+					b.WriteByte('\t')
+				case '"', '\\':
+					b.WriteByte(s[i])
+				default:
+					return nil, "", fmt.Errorf("unsupported escape \\%c", s[i])
+				}
+			default:
+				b.WriteByte(s[i])
+			}
+		}
+		return nil, "", fmt.Errorf("unterminated string")
+	case s[0] == '\'':
+		end := strings.IndexByte(s[1:], '\'')
+		if end < 0 {
+			return nil, "", fmt.Errorf("unterminated string")
+		}
+		return s[1 : end+1], s[end+2:], nil
+	case s[0] == '[':
+		var list []interface{}
+		rest := strings.TrimLeft(s[1:], " \t")
+		for !strings.HasPrefix(rest, "]") {
+			v, r, err := tomlValue(rest)
+			if err != nil {
+				return nil, "", err
+			}
+			list = append(list, v)
+			rest = strings.TrimLeft(r, " \t")
+			if strings.HasPrefix(rest, ",") {
+				rest = strings.TrimLeft(rest[1:], " \t")
+			} else if !strings.HasPrefix(rest, "]") {
+				return nil, "", fmt.Errorf("expected , or ] in array")
+			}
+		}
+		return list, rest[1:], nil
+	}
+	end := strings.IndexAny(s, " \t,]")
+	if end < 0 {
+		end = len(s)
+	}
+	word, rest := s[:end], s[end:]
+	switch word {
+	case "true":
+		return true, rest, nil
+	case "false":
+		return false, rest, nil
+	}
+	n, err := strconv.ParseInt(strings.Replace(word, "_", "", -1), 10, 64)
+	if err != nil {
+		return nil, "", fmt.Errorf("unsupported value %s", word)
+	}
+	return n, rest, nil
+}
+
+// stripTOMLComment removes the comment, if any, from the end of line.
+func stripTOMLComment(line string) string {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		switch ch := line[i]; {
+		case quote != 0:
+			if ch == '\\' && quote == '"' {
+				i++
+			} else if ch == quote {
+				quote = 0
+			}
+		case ch == '"' || ch == '\'':
+			quote = ch
+		case ch == '#':
+			return line[:i]
+		}
+	}
+	return line
+}
+
+// tomlBalanced reports whether the brackets outside strings in s balance.
+func tomlBalanced(s string) bool {
+	depth := 0
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		switch ch := s[i]; {
+		case quote != 0:
+			if ch == '\\' && quote == '"' {
+				i++
+			} else if ch == quote {
+				quote = 0
+			}
+		case ch == '"' || ch == '\'':
+			quote = ch
+		case ch == '[':
+			depth++
+		case ch == ']':
+			depth--
+		}
+	}
+	return depth <= 0
+}
@@ -0,0 +1,76 @@
+package bashgraph
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseTOML(t *testing.T) {
+	tests := []struct {
+		data string
+		want map[string]interface{}
+	}{
+		{
+			data: "jobs = 4\nkeywords = true\n",
+			want: map[string]interface{}{"jobs": int64(4), "keywords": true},
+		},
+		{
+			// Quoted keys may contain dots, in table headers too.
+			data: "[commands.\"deploy.sh\"]\nrepo = \"example.com/deploy\"\n[commands.'run.d' . x]\n\"a=b\" = 'c'\n",
+			want: map[string]interface{}{"commands": map[string]interface{}{
+				"deploy.sh": map[string]interface{}{"repo": "example.com/deploy"},
+				"run.d":     map[string]interface{}{"x": map[string]interface{}{"a=b": "c"}},
+			}},
+		},
+		{
+			data: "files = [\"a.sh\", 'b.sh',]\nnested = [[1, 2], []]\nlong = [\n  \"x\", # first\n  \"y\",\n]\n",
+			want: map[string]interface{}{
+				"files":  []interface{}{"a.sh", "b.sh"},
+				"nested": []interface{}{[]interface{}{int64(1), int64(2)}, []interface{}(nil)},
+				"long":   []interface{}{"x", "y"},
+			},
+		},
+		{
+			// A # in a string doesn't start a comment.
+			data: "# settings\nurl = \"http://example.com/#top\" # the url\nglob = 'a#b'\nq = \"say \\\"#\\\"\"\n",
+			want: map[string]interface{}{"url": "http://example.com/#top", "glob": "a#b", "q": `say "#"`},
+		},
+	}
+	for _, test := range tests {
+		got, err := parseTOML([]byte(test.data))
+		if err != nil {
+			t.Errorf("%q: %s", test.data, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%q: got %#v, want %#v", test.data, got, test.want)
+		}
+	}
+}
+
+func TestParseTOMLErrors(t *testing.T) {
+	tests := []string{
+		"[commands\n",
+		"[[commands]]\n",
+		"[commands.]\n",
+		"[commands.\"deploy.sh]\n",
+		"[a b]\n",
+		"key\n",
+		"= 1\n",
+		"a b = 1\n",
+		"key = \n",
+		"key = \"unterminated\n",
+		"key = 'unterminated\n",
+		"key = \"bad \\q escape\"\n",
+		"key = [1 2]\n",
+		"key = 1.5\n",
+		"key = 1 2\n",
+		"key = 1\nkey = 2\n",
+		"key = 1\n[key]\n",
+	}
+	for _, data := range tests {
+		if got, err := parseTOML([]byte(data)); err == nil {
+			t.Errorf("%q: got %#v, want an error", data, got)
+		}
+	}
+}
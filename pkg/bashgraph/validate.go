@@ -0,0 +1,126 @@
+package bashgraph
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"sourcegraph.com/sourcegraph/srclib/graph"
+	"sourcegraph.com/sourcegraph/srclib/unit"
+)
+
+type ValidateCmd struct {
+	Graph  string `long:"graph" description:"validate the graph output in FILE instead of graphing the source units" value-name:"FILE"`
+	Schema bool   `long:"schema" description:"also check the output against the srclib unit and graph types"`
+}
+
+// A Violation is a way in which graph output is inconsistent.
+type Violation struct {
+	// Check is the invariant that is violated: "file-exists", "span",
+	// "def-ref", "unique-def-path" or "schema".
+	Check   string
+	Message string
+	File    string `json:",omitempty"`
+	Start   uint32 `json:",omitempty"`
+	End     uint32 `json:",omitempty"`
+}
+
+func (c *ValidateCmd) Execute(args []string) error {
+	units, err := readSourceUnits()
+	if err != nil {
+		return err
+	}
+
+	var data []byte
+	if c.Graph != "" {
+		if data, err = ioutil.ReadFile(c.Graph); err != nil {
+			return fmt.Errorf("Failed to read graph output: %s", err)
+		}
+	} else {
+		opts := commandOptions()
+		gout, err := graphUnits(units, opts)
+		if err != nil {
+			return fmt.Errorf("Failed to graph source units: %s", err)
+		}
+		pout, err := withPositions(gout, opts)
+		if err != nil {
+			return fmt.Errorf("Failed to compute positions: %s", err)
+		}
+		if data, err = json.Marshal(pout); err != nil {
+			return fmt.Errorf("Failed to encode graph output: %s", err)
+		}
+	}
+	var out graph.Output
+	if err := json.Unmarshal(data, &out); err != nil {
+		return fmt.Errorf("Failed to parse graph output: %s", err)
+	}
+
+	violations := validateGraph(units, &out)
+	if c.Schema {
+		violations = append(violations, checkSchema(units, data)...)
+	}
+	if violations == nil {
+		violations = []*Violation{}
+	}
+	if err := json.NewEncoder(os.Stdout).Encode(violations); err != nil {
+		return fmt.Errorf("Failed to output violations: %s", err)
+	}
+	if len(violations) > 0 {
+		return fmt.Errorf("Found %d violations", len(violations))
+	}
+	return nil
+}
+
+// validateGraph checks that out is consistent with itself and with units,
+// the source units that were graphed to produce it.
+func validateGraph(units unit.SourceUnits, out *graph.Output) []*Violation {
+	var violations []*Violation
+	add := func(v *Violation) {
+		violations = append(violations, v)
+	}
+
+	sizes := map[string]uint32{}
+	for _, u := range units {
+		for _, file := range u.Files {
+			info, err := os.Stat(file)
+			if err != nil {
+				add(&Violation{Check: "file-exists", File: file, Message: fmt.Sprintf("unit %s lists a file that doesn't exist", u.Name)})
+				continue
+			}
+			sizes[file] = uint32(info.Size())
+		}
+	}
+	checkSpan := func(what, file string, start, end uint32) {
+		size, ok := sizes[file]
+		switch {
+		case !ok:
+			add(&Violation{Check: "span", File: file, Start: start, End: end, Message: fmt.Sprintf("%s is in a file that is not in any unit", what)})
+		case start > end || end > size:
+			add(&Violation{Check: "span", File: file, Start: start, End: end, Message: fmt.Sprintf("%s spans %d-%d of a %d-byte file", what, start, end, size)})
+		}
+	}
+
+	defs := map[graph.DefKey]*graph.Def{}
+	for _, def := range out.Defs {
+		checkSpan("def "+def.Path, def.File, def.DefStart, def.DefEnd)
+		key := def.DefKey
+		key.Repo, key.CommitID = "", ""
+		if d, ok := defs[key]; ok {
+			add(&Violation{Check: "unique-def-path", File: def.File, Start: def.DefStart, End: def.DefEnd, Message: fmt.Sprintf("def %s in unit %s is also defined at %s:%d-%d", def.Path, def.Unit, d.File, d.DefStart, d.DefEnd)})
+			continue
+		}
+		defs[key] = def
+	}
+	for _, ref := range out.Refs {
+		checkSpan("ref to "+refTarget(ref), ref.File, ref.Start, ref.End)
+		if !ref.Def {
+			continue
+		}
+		key := graph.DefKey{UnitType: ref.DefUnitType, Unit: ref.DefUnit, Path: ref.DefPath}
+		if ref.DefRepo != "" || defs[key] == nil {
+			add(&Violation{Check: "def-ref", File: ref.File, Start: ref.Start, End: ref.End, Message: fmt.Sprintf("def ref to %s has no def", refTarget(ref))})
+		}
+	}
+	return violations
+}
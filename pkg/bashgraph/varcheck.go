@@ -0,0 +1,207 @@
+package bashgraph
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"sourcegraph.com/sourcegraph/srclib/graph"
+	"sourcegraph.com/sourcegraph/srclib/unit"
+)
+
+type VarcheckCmd struct {
+	Nounset bool `long:"nounset" description:"only report undefined variables in files that enable set -u"`
+}
+
+func (c *VarcheckCmd) Execute(args []string) error {
+	units, err := readSourceUnits()
+	if err != nil {
+		return err
+	}
+
+	out, err := checkVars(units, c.Nounset, commandOptions())
+	if err != nil {
+		return fmt.Errorf("Failed to check variables: %s", err)
+	}
+
+	if err := json.NewEncoder(os.Stdout).Encode(out); err != nil {
+		return fmt.Errorf("Failed to output variable diagnostics: %s", err)
+	}
+	return nil
+}
+
+// envVars are the variables that are set by the shell or commonly come from
+// the environment. They are never undefined, and assigning them is never
+// useless, since the shell or the programs it runs read them.
+var envVars = map[string]bool{
+	"CDPATH": true, "COLUMNS": true, "COMP_CWORD": true, "COMP_LINE": true,
+	"COMP_WORDS": true, "COMPREPLY": true, "DISPLAY": true, "EDITOR": true,
+	"EUID": true, "FUNCNAME": true, "GLOBIGNORE": true, "GROUPS": true,
+	"HISTFILE": true, "HISTSIZE": true, "HOME": true, "HOSTNAME": true,
+	"HOSTTYPE": true, "IFS": true, "LANG": true, "LINENO": true, "LINES": true,
+	"LOGNAME": true, "MACHTYPE": true, "MAIL": true, "MAPFILE": true,
+	"OLDPWD": true, "OPTARG": true, "OPTERR": true, "OPTIND": true,
+	"OSTYPE": true, "PAGER": true, "PATH": true, "PIPESTATUS": true,
+	"PPID": true, "PROMPT_COMMAND": true, "PS1": true, "PS2": true, "PS3": true,
+	"PS4": true, "PWD": true, "RANDOM": true, "REPLY": true, "SECONDS": true,
+	"SHELL": true, "SHELLOPTS": true, "SHLVL": true, "TERM": true,
+	"TMPDIR": true, "TZ": true, "UID": true, "USER": true, "VISUAL": true,
+}
+
+// isEnvVar reports whether the named variable is set by the shell or the
+// environment.
+func isEnvVar(name string) bool {
+	return envVars[name] || strings.HasPrefix(name, "BASH") || strings.HasPrefix(name, "LC_")
+}
+
+// checkVars reports the variables in units that are assigned but never
+// read, and the expansions of variables that are never assigned. If nounset
+// is set, undefined variables are only reported in files that run set -u,
+// where they are fatal.
+//
+// Variables assigned in sourced dotenv files are meant for the environment
+// of the commands the scripts run, so they are never reported unused.
+//
+// Globals are considered used if any global of the same name is read, since
+// which assignment a read sees depends on the order files are sourced in.
+// Names that are expanded where expansions are not parsed, such as in
+// here-documents, strings passed to eval or trap, and arithmetic, also
+// count as reads.
+func checkVars(units unit.SourceUnits, nounset bool, opts *Options) (*graph.Output, error) {
+	output := graph.Output{}
+	for _, u := range units {
+		files := parseUnit(u, opts)
+		idx := newVarIndex(files)
+
+		usedGlobals := map[string]bool{}
+		usedLocals := map[*varDecl]bool{}
+		for _, f := range files {
+			for name := range textReads(string(f.data)) {
+				usedGlobals[name] = true
+			}
+			for _, s := range f.scripts {
+				for _, ref := range s.varRefs {
+					fn := s.enclosingFunc(word{start: ref.start, end: ref.end})
+					d := idx.resolve(ref.name, f, fn)
+					switch {
+					case d == nil:
+					case d.site.local != nil:
+						usedLocals[d] = true
+					default:
+						usedGlobals[ref.name] = true
+					}
+				}
+			}
+		}
+
+		for _, f := range files {
+			lines := newLineIndex(f.data)
+			checkUndefined := !nounset || enablesNounset(f)
+			for i, s := range f.scripts {
+				src := f.sources[i]
+				for _, site := range s.vars {
+					if !idx.isDecl(site) || isEnvVar(site.name) || site.name == "_" || site.keyword == "export" || f.dotenv {
+						continue
+					}
+					d := idx.resolve(site.name, f, site.local)
+					if site.local == nil && usedGlobals[site.name] || site.local != nil && usedLocals[d] {
+						continue
+					}
+					a, err := makeDiagnosticAnn(f.name, lines, &Diagnostic{
+						Source:  "varcheck",
+						Code:    "unused-variable",
+						Level:   "warning",
+						Message: fmt.Sprintf("%s is assigned but never used", site.name),
+						Start:   uint32(src.fileOffset(site.start)),
+						End:     uint32(src.fileEnd(site.end)),
+					})
+					if err != nil {
+						return nil, err
+					}
+					output.Anns = append(output.Anns, a)
+				}
+
+				if !checkUndefined {
+					continue
+				}
+				for _, ref := range s.varRefs {
+					fn := s.enclosingFunc(word{start: ref.start, end: ref.end})
+					if idx.resolve(ref.name, f, fn) != nil || isEnvVar(ref.name) || hasDefaultValue(src.text, ref) {
+						continue
+					}
+					a, err := makeDiagnosticAnn(f.name, lines, &Diagnostic{
+						Source:  "varcheck",
+						Code:    "undefined-variable",
+						Level:   "warning",
+						Message: fmt.Sprintf("%s is used but never assigned", ref.name),
+						Start:   uint32(src.fileOffset(ref.start)),
+						End:     uint32(src.fileEnd(ref.end)),
+					})
+					if err != nil {
+						return nil, err
+					}
+					output.Anns = append(output.Anns, a)
+				}
+			}
+		}
+	}
+	return &output, nil
+}
+
+// hasDefaultValue reports whether ref is a braced expansion that handles
+// an unset variable itself, as in ${NAME:-default} or ${NAME+set}.
+func hasDefaultValue(text string, ref *varRef) bool {
+	if ref.start == 0 || text[ref.start-1] != '{' {
+		return false
+	}
+	rest := strings.TrimPrefix(text[ref.end:], ":")
+	return rest != "" && strings.IndexByte("-=?+", rest[0]) >= 0
+}
+
+// textReads returns the names of the variables that text appears to read:
+// names following $ or ${ anywhere, whether or not in quotes, and the
+// identifiers in arithmetic expressions between (( and )).
+func textReads(text string) map[string]bool {
+	names := map[string]bool{}
+	for i := 0; i < len(text); i++ {
+		switch {
+		case text[i] == '$':
+			j := i + 1
+			if j < len(text) && text[j] == '{' {
+				j++
+			}
+			k := j
+			for k < len(text) && isNameChar(text[k], k == j) {
+				k++
+			}
+			if k > j {
+				names[text[j:k]] = true
+			}
+		case strings.HasPrefix(text[i:], "(("):
+			end := strings.Index(text[i:], "))")
+			if end < 0 {
+				continue
+			}
+			expr := text[i+2 : i+end]
+			for j := 0; j < len(expr); j++ {
+				if !isNameChar(expr[j], true) || j > 0 && isNameChar(expr[j-1], false) {
+					continue
+				}
+				k := j
+				for k < len(expr) && isNameChar(expr[k], k == j) {
+					k++
+				}
+				names[expr[j:k]] = true
+				j = k
+			}
+		}
+	}
+	return names
+}
+
+// enablesNounset reports whether f makes expanding unset variables an
+// error, with set -u, set -o nounset or a -u option on its #! line.
+func enablesNounset(f *parsedFile) bool {
+	return hasOption(shellOptions(f), "nounset")
+}
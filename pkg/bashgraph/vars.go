@@ -0,0 +1,461 @@
+package bashgraph
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"sourcegraph.com/sourcegraph/srclib/graph"
+)
+
+// A varSite is a place where a variable is assigned or declared.
+type varSite struct {
+	name string
+	// start and end are the offsets of the variable's name.
+	start, end int
+	// local is the function that the variable is local to, or nil if the
+	// variable is global.
+	local *function
+	// keyword is the command that declared the variable, such as "local" or
+	// "read", or "" for a plain assignment.
+	keyword string
+	// nameref is what a nameref declared here refers to, as written: the
+	// name of a variable, or an expansion such as $1 when the variable is
+	// passed by reference.
+	nameref string
+}
+
+// A varRef is an expansion of a variable.
+type varRef struct {
+	name string
+	// start and end are the offsets of the variable's name.
+	start, end int
+}
+
+// declCommands are the builtins whose arguments declare variables.
+var declCommands = map[string]bool{
+	"declare":  true,
+	"export":   true,
+	"local":    true,
+	"readonly": true,
+	"typeset":  true,
+}
+
+// readOptsWithArg are the options of the read builtin that take an argument.
+const readOptsWithArg = "dinNptu"
+
+// mapfileOptsWithArg are the options of the mapfile builtin that take an
+// argument.
+const mapfileOptsWithArg = "dnOsuCc"
+
+// parseVars finds the variable assignments and expansions in s.
+func parseVars(s *script) {
+	// A plain assignment in a function refers to a local variable if the
+	// function declares one by that name, so look at declarations before
+	// assignments.
+	locals := map[*function]map[string]bool{}
+	addSite := func(w word, nameStart int, name string, keyword string, local bool) {
+		site := &varSite{name: name, start: nameStart, end: nameStart + len(name), keyword: keyword}
+		if fn := s.enclosingFunc(w); fn != nil && (local || locals[fn][name]) {
+			site.local = fn
+			if locals[fn] == nil {
+				locals[fn] = map[string]bool{}
+			}
+			locals[fn][name] = true
+		}
+		s.vars = append(s.vars, site)
+	}
+
+	for _, cmd := range s.commands {
+		name := unquote(cmd.text)
+		args := commandArgs(s.words, cmd)
+		switch {
+		case declCommands[name] || dialectDeclCommands[s.dialect][name]:
+			local := name == "local"
+			scoped := name == "declare" || name == "typeset" || dialectDeclCommands[s.dialect][name]
+			if fn := s.enclosingFunc(cmd); s.dialect == "ksh" && fn != nil && fn.start == fn.nameStart {
+				// ksh scopes variables only to functions defined with the
+				// function keyword; NAME() functions share the caller's.
+				scoped = false
+			}
+			set, _, operands := declArgs(args)
+			if strings.ContainsAny(set, "fF") {
+				// The operands name functions, which emitDeclaredFuncs
+				// links.
+				continue
+			}
+			// declare -g creates a global even in a function, and -n a
+			// nameref; export -n unexports the variable instead.
+			declares := name == "declare" || name == "typeset" || name == "local" || dialectDeclCommands[s.dialect][name]
+			if declares && strings.Contains(set, "g") {
+				local, scoped = false, false
+			}
+			nameref := name == "nameref" || declares && strings.Contains(set, "n")
+			for _, a := range operands {
+				if n := assignmentName(a.text); n != "" {
+					addSite(a, a.start, n, name, local || scoped)
+					// A nameref refers to the variable it is set to.
+					target := a.text[strings.IndexByte(a.text, '=')+1:]
+					if nameref && isName(target) {
+						s.varRefs = append(s.varRefs, &varRef{name: target, start: a.end - len(target), end: a.end})
+					}
+					if nameref {
+						s.vars[len(s.vars)-1].nameref = unquote(target)
+					}
+				} else if isName(a.text) {
+					addSite(a, a.start, a.text, name, local || scoped)
+				}
+			}
+		case name == "read":
+			for j := 0; j < len(args); j++ {
+				a := args[j]
+				if strings.HasPrefix(a.text, "-") {
+					opt := a.text[len(a.text)-1]
+					if opt == 'a' && j+1 < len(args) && isName(args[j+1].text) {
+						addSite(args[j+1], args[j+1].start, args[j+1].text, "read", false)
+						j++
+					} else if strings.IndexByte(readOptsWithArg, opt) >= 0 {
+						j++
+					}
+					continue
+				}
+				if isName(a.text) {
+					addSite(a, a.start, a.text, "read", false)
+				}
+			}
+		case name == "getopts":
+			if len(args) >= 2 && isName(args[1].text) {
+				addSite(args[1], args[1].start, args[1].text, "getopts", false)
+			}
+		case name == "printf":
+			if len(args) >= 2 && args[0].text == "-v" && isName(args[1].text) {
+				addSite(args[1], args[1].start, args[1].text, "printf", false)
+			}
+		case name == "mapfile" || name == "readarray":
+			for j := 0; j < len(args); j++ {
+				a := args[j]
+				if strings.HasPrefix(a.text, "-") {
+					if strings.IndexByte(mapfileOptsWithArg, a.text[len(a.text)-1]) >= 0 {
+						j++
+					}
+					continue
+				}
+				if isName(a.text) {
+					addSite(a, a.start, a.text, name, false)
+				}
+				break
+			}
+		}
+	}
+
+	pos := commandPositions(s.words)
+	for i, w := range s.words {
+		if w.op {
+			continue
+		}
+		refs := expansions(w)
+
+		switch {
+		case pos[i] && w.text == "coproc" && i+1 < len(s.words) && isCoprocName(s.words, i+1):
+			// A named coprocess defines NAME, an array of its file
+			// descriptors, and NAME_PID, whose def is at NAME too.
+			name := s.words[i+1]
+			addSite(w, name.start, name.text, "coproc", false)
+			addSite(w, name.start, name.text+"_PID", "coproc", false)
+			s.vars[len(s.vars)-1].end = name.end
+		case pos[i] && isAssignment(w.text):
+			addSite(w, w.start, assignmentName(w.text), "", false)
+		case pos[i] && w.text == "for" && i+1 < len(s.words) && isName(s.words[i+1].text):
+			addSite(w, s.words[i+1].start, s.words[i+1].text, "for", false)
+		case isArithmeticCommand(w) && (pos[i] || i > 0 && s.words[i-1].text == "for"):
+			// The variables assigned to are defined, not referred to.
+			assigned := map[int]bool{}
+			for _, a := range arithmeticAssignments(w) {
+				addSite(w, a.start, a.name, "", false)
+				assigned[a.start] = true
+			}
+			var used []*varRef
+			for _, ref := range refs {
+				if !assigned[ref.start] {
+					used = append(used, ref)
+				}
+			}
+			refs = used
+		}
+		s.varRefs = append(s.varRefs, refs...)
+	}
+
+	sort.Sort(varSitesByStart(s.vars))
+}
+
+// expansions returns the variables expanded in w, as $NAME, ${NAME...} or
+// in an arithmetic expansion $((...)) or command ((...)). The span of each ref is exactly the
+// variable's name. Text in single quotes is not expanded.
+func expansions(w word) []*varRef {
+	var refs []*varRef
+	text := w.text
+	inDouble := false
+	for i := 0; i < len(text); i++ {
+		switch ch := text[i]; {
+		case ch == '\\':
+			i++
+		case ch == '\'' && !inDouble:
+			i = scanSingleQuoted(text, i) - 1
+		case ch == '"':
+			inDouble = !inDouble
+		case ch == '$' && strings.HasPrefix(text[i+1:], "(("):
+			end := arithmeticEnd(text, i+3)
+			refs = append(refs, arithmeticRefs(text[:end], i+3, w.start)...)
+			i = end + 1
+		case i == 0 && isArithmeticCommand(w):
+			end := arithmeticEnd(text, 2)
+			refs = append(refs, arithmeticRefs(text[:end], 2, w.start)...)
+			i = end + 1
+		case ch == '$' && i+1 < len(text):
+			j := i + 1
+			if text[j] == '{' {
+				j++
+				if j < len(text) && text[j] == '(' {
+					// Skip zsh parameter expansion flags, as in ${(j:,:)list}.
+					if end := strings.IndexByte(text[j:], ')'); end >= 0 {
+						j += end + 1
+					}
+				}
+				if j+1 < len(text) && (text[j] == '#' || text[j] == '!') && isNameChar(text[j+1], true) {
+					j++
+				}
+			}
+			k := j
+			for k < len(text) && isNameChar(text[k], k == j) {
+				k++
+			}
+			if k > j {
+				refs = append(refs, &varRef{name: text[j:k], start: w.start + j, end: w.start + k})
+				i = k - 1
+			}
+		}
+	}
+	return refs
+}
+
+// arithmeticEnd returns the offset of the "))" that closes the arithmetic
+// expansion whose expression starts at text[i], or len(text) if there is
+// none.
+func arithmeticEnd(text string, i int) int {
+	depth := 0
+	for ; i < len(text); i++ {
+		switch text[i] {
+		case '(':
+			depth++
+		case ')':
+			if depth == 0 && strings.HasPrefix(text[i:], "))") {
+				return i
+			}
+			depth--
+		}
+	}
+	return len(text)
+}
+
+// arithmeticAssignments returns the variables assigned to in w, an
+// arithmetic command, as in (( total = n * 2 )) or (( i += 1 )).
+func arithmeticAssignments(w word) []*varRef {
+	var assigned []*varRef
+	end := arithmeticEnd(w.text, 2)
+	for _, ref := range arithmeticRefs(w.text[:end], 2, 0) {
+		rest := strings.TrimLeft(w.text[ref.end:end], " \t")
+		op := strings.IndexByte(rest, '=')
+		if op < 0 || strings.HasPrefix(rest[op:], "==") || strings.Trim(rest[:op], "+-*/%<>&^|") != "" || rest[:op] == "<" || rest[:op] == ">" {
+			continue
+		}
+		assigned = append(assigned, &varRef{name: ref.name, start: w.start + ref.start, end: w.start + ref.end})
+	}
+	return assigned
+}
+
+// arithmeticRefs returns the variables referred to in the arithmetic
+// expression starting at text[i]. In arithmetic, variables may be named
+// with or without a $. Names that are part of a number, such as the digits
+// of 0xff, are not variables. offset is the offset of text in the source.
+func arithmeticRefs(text string, i, offset int) []*varRef {
+	var refs []*varRef
+	for ; i < len(text); i++ {
+		if !isNameChar(text[i], true) || i > 0 && isNameChar(text[i-1], false) {
+			continue
+		}
+		j := i
+		for j < len(text) && isNameChar(text[j], j == i) {
+			j++
+		}
+		refs = append(refs, &varRef{name: text[i:j], start: offset + i, end: offset + j})
+		i = j
+	}
+	return refs
+}
+
+type varSitesByStart []*varSite
+
+func (vs varSitesByStart) Len() int           { return len(vs) }
+func (vs varSitesByStart) Swap(i, j int)      { vs[i], vs[j] = vs[j], vs[i] }
+func (vs varSitesByStart) Less(i, j int) bool { return vs[i].start < vs[j].start }
+
+// A varDecl is the site of the first assignment or declaration of a
+// variable in a scope, which is where the variable's def is.
+type varDecl struct {
+	file *parsedFile
+	src  *source
+	site *varSite
+}
+
+// defPath returns the DefPath of the variable.
+func (d *varDecl) defPath() string {
+	path := d.file.defPath() + "/"
+	if d.site.local != nil {
+		path += d.site.local.name + "/"
+	}
+	return path + "$" + d.site.name
+}
+
+// A varIndex holds the variable declarations of a source unit.
+type varIndex struct {
+	// globals holds the first assignment of each global variable in every
+	// file, in unit order.
+	globals map[string][]*varDecl
+	// locals holds the first declaration of each local variable of a
+	// function, by the function's first definition in its file.
+	locals map[*function]map[string]*varDecl
+	// firstDefs maps the later definitions of functions redefined in a
+	// file to their first, whose def they share.
+	firstDefs map[*function]*function
+}
+
+func newVarIndex(files []*parsedFile) *varIndex {
+	idx := &varIndex{
+		globals:   map[string][]*varDecl{},
+		locals:    map[*function]map[string]*varDecl{},
+		firstDefs: map[*function]*function{},
+	}
+	for _, f := range files {
+		first := map[string]*function{}
+		for _, s := range f.scripts {
+			for _, fn := range s.functions {
+				if first[fn.name] == nil {
+					first[fn.name] = fn
+				} else {
+					idx.firstDefs[fn] = first[fn.name]
+				}
+			}
+		}
+		seen := map[string]bool{}
+		for i, s := range f.scripts {
+			for _, site := range s.vars {
+				d := &varDecl{file: f, src: f.sources[i], site: site}
+				if site.local != nil {
+					fn := idx.scope(site.local)
+					if idx.locals[fn] == nil {
+						idx.locals[fn] = map[string]*varDecl{}
+					}
+					if idx.locals[fn][site.name] == nil {
+						idx.locals[fn][site.name] = d
+					}
+				} else if !seen[site.name] {
+					seen[site.name] = true
+					idx.globals[site.name] = append(idx.globals[site.name], d)
+				}
+			}
+		}
+	}
+	return idx
+}
+
+// resolve returns the declaration of the named variable as seen from the
+// function fn (or the top level, if fn is nil) of the file f. Local
+// variables take precedence, then globals in the same file, then the first
+// global in the unit.
+func (idx *varIndex) resolve(name string, f *parsedFile, fn *function) *varDecl {
+	if fn != nil {
+		if d := idx.locals[idx.scope(fn)][name]; d != nil {
+			return d
+		}
+	}
+	defs := idx.globals[name]
+	for _, d := range defs {
+		if d.file == f {
+			return d
+		}
+	}
+	if len(defs) > 0 {
+		return defs[0]
+	}
+	return nil
+}
+
+// scope returns the function whose local variables those of fn are: the
+// first definition of fn in its file.
+func (idx *varIndex) scope(fn *function) *function {
+	if first, ok := idx.firstDefs[fn]; ok {
+		return first
+	}
+	return fn
+}
+
+// isDecl reports whether site is the declaration in idx of its variable.
+func (idx *varIndex) isDecl(site *varSite) bool {
+	if site.local != nil {
+		return idx.locals[idx.scope(site.local)][site.name].site == site
+	}
+	for _, d := range idx.globals[site.name] {
+		if d.site == site {
+			return true
+		}
+	}
+	return false
+}
+
+func makeVarDef(d *varDecl) (*graph.Def, error) {
+	sig, typ := varSignature(d)
+	data, err := json.Marshal(DefData{
+		Name:      "$" + d.site.name,
+		Keyword:   d.site.keyword,
+		Type:      typ,
+		Kind:      "variable",
+		Separator: " ",
+		Signature: sig,
+		DocHTML:   docHTML(d.file.data, d.src.fileOffset(d.site.start)),
+		Nameref:   d.site.nameref,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &graph.Def{
+		DefKey: graph.DefKey{
+			UnitType: "BashDirectory",
+			Unit:     "bash",
+			Path:     d.defPath(),
+		},
+		TreePath: treePath(d.defPath()),
+		Name:     d.site.name,
+		Kind:     "var",
+		File:     d.file.name,
+		DefStart: uint32(d.src.fileOffset(d.site.start)),
+		DefEnd:   uint32(d.src.fileEnd(d.site.end)),
+		Local:    d.site.local != nil,
+		Data:     data,
+	}, nil
+}
+
+// makeVarRef returns a ref to the variable d from the span start:end of the
+// source src of the named file.
+func makeVarRef(filename string, src *source, start, end int, d *varDecl, isDef bool) *graph.Ref {
+	return &graph.Ref{
+		DefUnitType: "BashDirectory",
+		DefUnit:     "bash",
+		DefPath:     d.defPath(),
+		UnitType:    "BashDirectory",
+		Unit:        "bash",
+		Def:         isDef,
+		File:        filename,
+		Start:       uint32(src.fileOffset(start)),
+		End:         uint32(src.fileEnd(end)),
+	}
+}
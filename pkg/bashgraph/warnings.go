@@ -0,0 +1,88 @@
+package bashgraph
+
+import (
+	"fmt"
+	"strings"
+)
+
+// A Warning is an issue with how well a file was indexed, such as a sourced
+// file that couldn't be found, emitted in the Warnings of the graph output
+// so that whoever consumes the output can see it.
+type Warning struct {
+	// Code is the kind of issue: "unknown-source", "unresolved-call",
+	// "skipped-heredoc" or "huge-file", or, in the Warnings of a Result,
+	// "analysis-error" for a file that couldn't be read or graphed
+	// completely.
+	Code    string
+	Message string
+	File    string
+	Start   uint32 `json:",omitempty"`
+	End     uint32 `json:",omitempty"`
+}
+
+// hugeFileSize is the size in bytes above which a file is suspiciously
+// large for a shell script, and likely generated or bundled.
+const hugeFileSize = 1 << 20
+
+// emitFileWarnings adds warnings about f, and about the refs graphed from
+// it, to output.
+func emitFileWarnings(f *parsedFile, idx *unitIndex, output *graphOutput) error {
+	refs := output.fileRefs()
+	warn := func(code, message string, start, end int) {
+		output.warnings = append(output.warnings, &Warning{Code: code, Message: message, File: f.name, Start: uint32(start), End: uint32(end)})
+	}
+
+	if len(f.data) > hugeFileSize {
+		warn("huge-file", fmt.Sprintf("file is %d bytes, which is unusually large for a shell script", len(f.data)), 0, 0)
+	}
+
+	linked := map[uint32]bool{}
+	for _, ref := range refs {
+		linked[ref.Start] = true
+	}
+	included := map[*source]map[int]bool{}
+	for _, inc := range includes(f) {
+		if included[inc.src] == nil {
+			included[inc.src] = map[int]bool{}
+		}
+		included[inc.src][inc.word.start] = true
+	}
+
+	autoloaded := autoloadedFuncs(f)
+	for i, s := range f.scripts {
+		src := f.sources[i]
+		for _, cmd := range s.commands {
+			name := unquote(cmd.text)
+			if name == "source" || name == "." {
+				args := commandArgs(s.words, cmd)
+				if len(args) > 0 && !included[src][args[0].start] {
+					warn("unknown-source", fmt.Sprintf("sourced file %s can't be found in the repository", args[0].text), src.fileOffset(args[0].start), src.fileEnd(args[0].end))
+				}
+				continue
+			}
+			if !isLiteralCommand(cmd) || strings.ContainsAny(name, "/=") || isBuiltin(f, name) || autoloaded[name] {
+				continue
+			}
+			if !linkedIn(linked, src.fileOffset(cmd.start), src.fileEnd(cmd.end)) {
+				warn("unresolved-call", fmt.Sprintf("%s is not a function, alias or documented command", name), src.fileOffset(cmd.start), src.fileEnd(cmd.end))
+			}
+		}
+
+		_, _, bodies := lexWords(src.text)
+		for _, b := range bodies {
+			warn("skipped-heredoc", "here-document body is not graphed", src.fileOffset(b.start), src.fileEnd(b.end))
+		}
+	}
+	return nil
+}
+
+// linkedIn reports whether a ref in linked, a set of ref starts, starts in
+// the span from start to end, such as a command name in quotes.
+func linkedIn(linked map[uint32]bool, start, end int) bool {
+	for i := start; i < end; i++ {
+		if linked[uint32(i)] {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,129 @@
+package bashgraph
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"gopkg.in/fsnotify.v1"
+
+	"sourcegraph.com/sourcegraph/srclib/unit"
+)
+
+// watchDelay is how long graph --watch waits after a file changes for more
+// changes, so that a batch of changes, such as a checkout, is graphed once.
+const watchDelay = 200 * time.Millisecond
+
+// A watchUpdate is the output of graph --watch when files change: the graph
+// of the files that changed, which replaces their earlier output, and the
+// names of the files that were removed.
+type watchUpdate struct {
+	*positionedOutput
+	// Files are the files whose output the update replaces.
+	Files   []string
+	Removed []string `json:",omitempty"`
+}
+
+// watch watches the files of units and passes a watchUpdate to emit for
+// each batch of them that changes. Only the files that changed are graphed
+// again, though the other files of their units still define what refs
+// resolve to. It runs until watching or emit fails.
+func (c *GraphCmd) watch(units unit.SourceUnits, emit func(*watchUpdate) error) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("Failed to watch files: %s", err)
+	}
+	defer watcher.Close()
+
+	unitOf := map[string]*unit.SourceUnit{}
+	dirs := map[string]bool{}
+	for _, u := range units {
+		for _, name := range u.Files {
+			name = filepath.Clean(name)
+			if !c.selected(name) {
+				continue
+			}
+			unitOf[name] = u
+			if dir := filepath.Dir(name); !dirs[dir] {
+				dirs[dir] = true
+				if err := watcher.Add(dir); err != nil {
+					return fmt.Errorf("Failed to watch %s: %s", dir, err)
+				}
+			}
+		}
+	}
+	logInfof("Watching %d files in %d directories", len(unitOf), len(dirs))
+
+	changed := map[string]bool{}
+	var timer <-chan time.Time
+	for {
+		select {
+		case ev := <-watcher.Events:
+			name := filepath.Clean(ev.Name)
+			if unitOf[name] != nil && ev.Op != fsnotify.Chmod {
+				changed[name] = true
+				timer = time.After(watchDelay)
+			}
+		case err := <-watcher.Errors:
+			return fmt.Errorf("Failed to watch files: %s", err)
+		case <-timer:
+			update, err := graphChanged(units, unitOf, changed, &c.Options)
+			if err != nil {
+				return err
+			}
+			if err := emit(update); err != nil {
+				return err
+			}
+			changed, timer = map[string]bool{}, nil
+		}
+	}
+}
+
+// graphChanged returns the update for the changed files, which are in the
+// units given by unitOf, graphed with opts.
+func graphChanged(units unit.SourceUnits, unitOf map[string]*unit.SourceUnit, changed map[string]bool, opts *Options) (*watchUpdate, error) {
+	update := &watchUpdate{}
+	for name := range changed {
+		if _, err := os.Stat(name); err != nil {
+			update.Removed = append(update.Removed, name)
+		} else {
+			update.Files = append(update.Files, name)
+		}
+	}
+	sort.Strings(update.Files)
+	sort.Strings(update.Removed)
+	logInfof("Graphing %d changed files, %d removed", len(update.Files), len(update.Removed))
+
+	output := newGraphOutput()
+	selected := func(name string) bool { return changed[filepath.Clean(name)] }
+	for _, u := range units {
+		graphed := false
+		for name := range changed {
+			graphed = graphed || unitOf[name] == u
+		}
+		if !graphed {
+			continue
+		}
+		// Removed files are left out rather than skipped with a warning.
+		present := *u
+		present.Files = nil
+		for _, name := range u.Files {
+			if _, err := os.Stat(name); err == nil {
+				present.Files = append(present.Files, name)
+			}
+		}
+		if err := graphUnit(&present, opts, selected, output); err != nil {
+			return nil, fmt.Errorf("Failed to graph source units: %s", err)
+		}
+	}
+	sortOutput(&output.Output)
+	sort.Sort(warningsByStart(output.warnings))
+
+	var err error
+	if update.positionedOutput, err = withPositions(output, opts); err != nil {
+		return nil, fmt.Errorf("Failed to compute positions: %s", err)
+	}
+	return update, nil
+}
@@ -0,0 +1,825 @@
+package bashgraph
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+)
+
+// A word is a shell word or control operator together with its byte span in
+// the source text. Quotes and backslashes are kept in text, so text is
+// exactly the source between start and end.
+type word struct {
+	text  string
+	start int
+	end   int
+	op    bool
+}
+
+// isControlOp reports whether w separates one command from the next.
+func (w word) isControlOp() bool {
+	if !w.op {
+		return false
+	}
+	switch w.text {
+	case ";", "&", "&&", "||", "|", "|&", ";;", "(", ")", "\n":
+		return true
+	}
+	return false
+}
+
+// splitWords splits shell source into words and operators. It understands
+// quoting, backslash escapes, substitutions, comments and here-documents well
+// enough to find word boundaries; it does not perform any expansion. The
+// bodies of here-documents are skipped.
+func splitWords(text string) []word {
+	words, _, _ := lexWords(text)
+	return words
+}
+
+// lexWords splits shell source into words and operators like splitWords,
+// and also returns the comments and here-document bodies it skips.
+func lexWords(text string) (words, comments, bodies []word) {
+	var heredocs []heredoc
+	i := 0
+	for i < len(text) {
+		ch := text[i]
+		switch {
+		case ch == '\n':
+			words = append(words, word{text: "\n", start: i, end: i + 1, op: true})
+			i++
+			for _, h := range heredocs {
+				j := skipHeredoc(text, i, h)
+				if j > i {
+					bodies = append(bodies, word{text: text[i:j], start: i, end: j})
+				}
+				i = j
+			}
+			heredocs = nil
+		case ch == ' ' || ch == '\t' || ch == '\r':
+			i++
+		case ch == '\\' && i+1 < len(text) && text[i+1] == '\n':
+			i += 2
+		case ch == '#':
+			j := i
+			for j < len(text) && text[j] != '\n' {
+				j++
+			}
+			comments = append(comments, word{text: text[i:j], start: i, end: j})
+			i = j
+		case ch == '(' && i+1 < len(text) && text[i+1] == '(':
+			// An arithmetic command, as in (( i++ )), is a word.
+			j := scanSubstitution(text, i)
+			words = append(words, word{text: text[i:j], start: i, end: j})
+			i = j
+		case (ch == '<' || ch == '>') && i+1 < len(text) && text[i+1] == '(':
+			// A process substitution is a word.
+			j := scanWord(text, scanSubstitution(text, i+1))
+			words = append(words, word{text: text[i:j], start: i, end: j})
+			i = j
+		case strings.IndexByte(";&|()<>", ch) >= 0:
+			j := i + 1
+			for j < len(text) && j-i < 2 && isOpContinuation(text[i:j], text[j]) {
+				j++
+			}
+			if text[i:j] == "<<" && j < len(text) && (text[j] == '-' || text[j] == '<') {
+				j++
+			}
+			op := text[i:j]
+			words = append(words, word{text: op, start: i, end: j, op: true})
+			i = j
+			if op == "<<" || op == "<<-" {
+				for i < len(text) && (text[i] == ' ' || text[i] == '\t') {
+					i++
+				}
+				j = scanWord(text, i)
+				if j > i {
+					words = append(words, word{text: text[i:j], start: i, end: j})
+					heredocs = append(heredocs, heredoc{delim: unquote(text[i:j]), stripTabs: op == "<<-"})
+					i = j
+				}
+			}
+		case ch == '!' && i+1 < len(text) && text[i+1] == '(' && isNegatedSubshell(text, i, words):
+			words = append(words, word{text: "!", start: i, end: i + 1})
+			i++
+		default:
+			j := scanWord(text, i)
+			words = append(words, word{text: text[i:j], start: i, end: j})
+			i = j
+		}
+	}
+	return words, comments, bodies
+}
+
+// A heredoc is a pending here-document whose body starts on the next line.
+type heredoc struct {
+	delim     string
+	stripTabs bool
+}
+
+// skipHeredoc returns the offset of the line after the body of h, which
+// starts at text[i].
+func skipHeredoc(text string, i int, h heredoc) int {
+	for i < len(text) {
+		end := strings.IndexByte(text[i:], '\n')
+		if end < 0 {
+			end = len(text)
+		} else {
+			end += i
+		}
+		line := strings.TrimSuffix(text[i:end], "\r")
+		if h.stripTabs {
+			line = strings.TrimLeft(line, "\t")
+		}
+		i = end + 1
+		if line == h.delim {
+			break
+		}
+	}
+	if i > len(text) {
+		i = len(text)
+	}
+	return i
+}
+
+// isOpContinuation reports whether ch extends the operator op.
+func isOpContinuation(op string, ch byte) bool {
+	switch op {
+	case ";":
+		return ch == ';'
+	case "&":
+		return ch == '&' || ch == '>'
+	case "|":
+		return ch == '|' || ch == '&'
+	case "<":
+		return ch == '<' || ch == '&' || ch == '>'
+	case ">":
+		return ch == '>' || ch == '&' || ch == '|'
+	}
+	return false
+}
+
+// scanWord returns the end offset of the word starting at text[i].
+func scanWord(text string, i int) int {
+	start := i
+	for i < len(text) {
+		switch ch := text[i]; {
+		case ch == '\\':
+			i += 2
+		case ch == '\'':
+			i = scanSingleQuoted(text, i)
+		case ch == '"':
+			i = scanDoubleQuoted(text, i+1)
+		case ch == '`':
+			i++
+			for i < len(text) && text[i] != '`' {
+				if text[i] == '\\' {
+					i++
+				}
+				i++
+			}
+			i++
+		case ch == '$' && i+1 < len(text) && (text[i+1] == '(' || text[i+1] == '{'):
+			i = scanSubstitution(text, i+1)
+		case ch == ' ' || ch == '\t' || ch == '\r' || ch == '\n':
+			return i
+		case ch == '(' && (isGlobQualifier(text[start:i], text[i+1:]) || isExtglob(text[start:i]) || isArrayAssignment(text[start:i])):
+			i = scanSubstitution(text, i)
+		case strings.IndexByte(";&|()<>", ch) >= 0:
+			return i
+		default:
+			i++
+		}
+	}
+	if i > len(text) {
+		i = len(text)
+	}
+	return i
+}
+
+// isGlobQualifier reports whether the parenthesis between the pattern
+// prefix and rest is part of the word, as the glob qualifiers of zsh are in
+// *.sh(.N). A parenthesis directly after a word is otherwise a syntax error,
+// except in a function definition, where it is followed by ")".
+func isGlobQualifier(prefix, rest string) bool {
+	if prefix == "" || strings.HasPrefix(rest, ")") {
+		return false
+	}
+	return strings.ContainsAny(prefix, "*?") || strings.HasSuffix(prefix, "]")
+}
+
+// isExtglob reports whether the parenthesis after prefix opens the pattern
+// list of an extended glob, as in @(foo|bar), !(tmp) or +([0-9]), whose
+// alternatives are part of the word rather than commands.
+func isExtglob(prefix string) bool {
+	return prefix != "" && strings.IndexByte("?*+@!", prefix[len(prefix)-1]) >= 0
+}
+
+// isNegatedSubshell reports whether the "!(" at text[i] negates a subshell,
+// as in "if !(grep -q x f); then", rather than starting the extended glob
+// !(...). It does if it is where a command starts, after an operator or a
+// reserved word, and the parenthesis is not followed by more of a pattern.
+func isNegatedSubshell(text string, i int, words []word) bool {
+	if len(words) > 0 {
+		prev := words[len(words)-1]
+		if _, reserved := reservedWords[prev.text]; !prev.op && !reserved {
+			return false
+		}
+	}
+	end := scanSubstitution(text, i+1)
+	return end == len(text) || strings.IndexByte(" \t\r\n;&", text[end]) >= 0
+}
+
+// isGlobPattern reports whether w is a glob pattern with groups, such as
+// @(foo|bar).txt or *.sh(.N). Outside of quotes and substitutions, a word
+// other than an assignment only contains a parenthesis in such a group.
+func isGlobPattern(w word) bool {
+	if w.op || isAssignment(w.text) || isProcessSubstitution(w) {
+		return false
+	}
+	text := w.text
+	for i := 0; i < len(text); i++ {
+		switch ch := text[i]; {
+		case ch == '\\':
+			i++
+		case ch == '\'':
+			i = scanSingleQuoted(text, i) - 1
+		case ch == '"':
+			i = scanDoubleQuoted(text, i+1) - 1
+		case ch == '`':
+			if end := strings.IndexByte(text[i+1:], '`'); end >= 0 {
+				i += end + 1
+			}
+		case ch == '$' && i+1 < len(text) && (text[i+1] == '(' || text[i+1] == '{'):
+			i = scanSubstitution(text, i+1) - 1
+		case ch == '(':
+			return true
+		}
+	}
+	return false
+}
+
+// isBraceExpansion reports whether w contains a brace expansion outside of
+// quotes and substitutions, a list as in file.{txt,bak} or a sequence as in
+// {1..10}.
+func isBraceExpansion(w word) bool {
+	if w.op {
+		return false
+	}
+	text := w.text
+	for i := 0; i < len(text); i++ {
+		switch ch := text[i]; {
+		case ch == '\\':
+			i++
+		case ch == '\'':
+			i = scanSingleQuoted(text, i) - 1
+		case ch == '"':
+			i = scanDoubleQuoted(text, i+1) - 1
+		case ch == '$' && i+1 < len(text) && (text[i+1] == '(' || text[i+1] == '{'):
+			i = scanSubstitution(text, i+1) - 1
+		case ch == '{':
+			end := scanSubstitution(text, i)
+			if end-i < 2 || text[end-1] != '}' {
+				break
+			}
+			if body := text[i+1 : end-1]; strings.Contains(body, ",") || strings.Contains(body, "..") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isArrayAssignment reports whether prefix is the start of an assignment of
+// a compound value, as in "list=(a b)" or "map+=([k]=v)", whose elements
+// are part of the word.
+func isArrayAssignment(prefix string) bool {
+	return strings.HasSuffix(prefix, "=") && assignmentName(prefix) != ""
+}
+
+// scanSingleQuoted returns the offset just past the closing quote of the
+// single-quoted string whose opening quote is text[i], or len(text) if it
+// is not closed. In an ANSI-C quoted string, as in $'it\'s\n', backslashes
+// escape quotes.
+func scanSingleQuoted(text string, i int) int {
+	ansi := i > 0 && text[i-1] == '$'
+	for i++; i < len(text) && text[i] != '\''; i++ {
+		if ansi && text[i] == '\\' {
+			i++
+		}
+	}
+	if i >= len(text) {
+		return len(text)
+	}
+	return i + 1
+}
+
+// scanDoubleQuoted returns the offset just past the closing quote of the
+// double-quoted string whose contents start at text[i].
+func scanDoubleQuoted(text string, i int) int {
+	for i < len(text) && text[i] != '"' {
+		switch {
+		case text[i] == '\\':
+			i += 2
+		case text[i] == '$' && i+1 < len(text) && (text[i+1] == '(' || text[i+1] == '{'):
+			i = scanSubstitution(text, i+1)
+		default:
+			i++
+		}
+	}
+	return i + 1
+}
+
+// scanSubstitution returns the offset just past the ')' or '}' that closes
+// the '(' or '{' at text[i], skipping over quoted and nested text.
+func scanSubstitution(text string, i int) int {
+	open := text[i]
+	close := byte(')')
+	if open == '{' {
+		close = '}'
+	}
+	depth := 0
+	for i < len(text) {
+		switch ch := text[i]; {
+		case ch == open:
+			depth++
+			i++
+		case ch == close:
+			depth--
+			i++
+			if depth == 0 {
+				return i
+			}
+		case ch == '\\':
+			i += 2
+		case ch == '\'' && open == '(':
+			i = scanSingleQuoted(text, i)
+		case ch == '"':
+			i = scanDoubleQuoted(text, i+1)
+		case ch == '$' && i+1 < len(text) && (text[i+1] == '(' || text[i+1] == '{') && text[i+1] != open:
+			i = scanSubstitution(text, i+1)
+		default:
+			i++
+		}
+	}
+	return len(text)
+}
+
+// reservedWords are the words that have special meaning at the start of a
+// command. The value reports whether another command may follow directly.
+var reservedWords = map[string]bool{
+	"!":        true,
+	"{":        true,
+	"}":        false,
+	"[[":       false,
+	"case":     false,
+	"coproc":   true,
+	"do":       true,
+	"done":     false,
+	"elif":     true,
+	"else":     true,
+	"esac":     false,
+	"fi":       false,
+	"for":      false,
+	"function": false,
+	"if":       true,
+	"in":       false,
+	"select":   false,
+	"then":     true,
+	"time":     true,
+	"until":    true,
+	"while":    true,
+}
+
+// commandPrefixes are the words that run the command named by the word
+// after them and their options, as in "exec gunicorn app" or "time -p make".
+// The values list the single-letter options that take an argument.
+var commandPrefixes = map[string]string{
+	"builtin": "",
+	"command": "",
+	"exec":    "a",
+	"time":    "",
+}
+
+// isIONumber reports whether words[i] is the file descriptor number of a
+// redirection, such as 2 in 2>&1 or {fd} in {fd}<file.
+func isIONumber(words []word, i int) bool {
+	w := words[i]
+	if w.op || i+1 == len(words) {
+		return false
+	}
+	next := words[i+1]
+	if !next.op || next.start != w.end || next.text[0] != '<' && next.text[0] != '>' {
+		return false
+	}
+	if strings.HasPrefix(w.text, "{") && strings.HasSuffix(w.text, "}") {
+		return isName(w.text[1 : len(w.text)-1])
+	}
+	return strings.Trim(w.text, "0123456789") == ""
+}
+
+// commandPositions reports for each of words whether it is at the start of
+// a command, where a reserved word, an assignment or a command name may
+// appear. Words in case patterns, redirection targets, the file descriptor
+// numbers of redirections and the names in function definitions are never
+// in command position; the command run by a prefix such as exec or time is.
+func commandPositions(words []word) []bool {
+	pos := make([]bool, len(words))
+	atStart := true
+	awaitingIn := false // after "case WORD"
+	inPattern := false  // in a case pattern list
+	inCond := false     // in a [[ ... ]] conditional
+	inPrefix := false   // after a command prefix, before its command
+	prefixOpts := ""    // the options of the prefix that take an argument
+	skipArg := false    // after such an option
+	for i, w := range words {
+		if w.op {
+			switch {
+			case inPattern && w.text == ")":
+				inPattern = false
+				atStart = true
+			case inPattern, inCond:
+			case w.text == ";;" || w.text == ";&" || w.text == ";;&":
+				inPattern = true
+			case w.isControlOp():
+				atStart = true
+				inPrefix = false
+			}
+			continue
+		}
+		if i > 0 && words[i-1].op && !words[i-1].isControlOp() || isIONumber(words, i) {
+			// A redirection consumes the following word as its target,
+			// and may be preceded by a file descriptor number.
+			continue
+		}
+		if inPrefix {
+			// Skip the options of a command prefix such as exec.
+			switch {
+			case skipArg:
+				skipArg = false
+				continue
+			case w.text == "--":
+				inPrefix = false
+				continue
+			case len(w.text) > 1 && w.text[0] == '-':
+				skipArg = strings.IndexByte(prefixOpts, w.text[len(w.text)-1]) >= 0
+				continue
+			}
+			inPrefix = false
+		}
+		if inCond {
+			// The operands of a conditional are never commands.
+			inCond = w.text != "]]"
+			continue
+		}
+		if inPattern {
+			if w.text == "esac" {
+				pos[i] = true
+				inPattern = false
+			}
+			continue
+		}
+		if k := functionKeyword(words, i); k >= 0 && pos[k] {
+			// The body follows the names unless "()" does.
+			atStart = i+1 == len(words) || functionKeyword(words, i+1) < 0
+			continue
+		}
+		if i > 0 && pos[i-1] && words[i-1].text == "function" && w.text == "{" {
+			// The body of an anonymous zsh function, "function { ... }".
+			atStart = true
+		}
+		if i > 0 && pos[i-1] && words[i-1].text == "coproc" && isCoprocName(words, i) {
+			// The compound command that the coprocess runs follows.
+			continue
+		}
+		if awaitingIn && w.text == "in" {
+			awaitingIn = false
+			inPattern = true
+			continue
+		}
+		if !atStart {
+			continue
+		}
+		pos[i] = true
+		if isAssignment(w.text) {
+			continue
+		}
+		next, reserved := reservedWords[w.text]
+		atStart = reserved && next
+		if opts, ok := commandPrefixes[w.text]; ok {
+			atStart, inPrefix, prefixOpts = true, true, opts
+		}
+		if w.text == "case" {
+			awaitingIn = true
+		}
+		if w.text == "[[" {
+			inCond = true
+		}
+	}
+	return pos
+}
+
+// isFuncDefName reports whether words[i] is the name in a function
+// definition of the form NAME() or "function NAME".
+func isFuncDefName(words []word, i int) bool {
+	if functionKeyword(words, i) >= 0 {
+		return true
+	}
+	return i+2 < len(words) && words[i+1].op && words[i+1].text == "(" &&
+		words[i+2].op && words[i+2].text == ")"
+}
+
+// functionKeyword returns the index of the function keyword before
+// words[i] if words[i] is a name it defines, or -1. zsh lets one keyword
+// define several functions with the same body, as in "function a b { ... }",
+// and "function { ... }" is an anonymous function, which has no name.
+func functionKeyword(words []word, i int) int {
+	isName := func(w word) bool { return !w.op && w.text != "{" && w.text != "function" }
+	if !isName(words[i]) {
+		return -1
+	}
+	k := i - 1
+	for k >= 0 && isName(words[k]) {
+		k--
+	}
+	if k < 0 || words[k].op || words[k].text != "function" {
+		return -1
+	}
+	if k == i-1 {
+		return k
+	}
+	// A name after the first must be followed by the body.
+	j := i + 1
+	for j < len(words) && isName(words[j]) {
+		j++
+	}
+	if j < len(words) && (words[j].text == "{" || words[j].op && words[j].text == "(") {
+		return k
+	}
+	return -1
+}
+
+// isCoprocName reports whether words[i], a word after the coproc keyword,
+// names the coprocess. Only a coprocess that runs a compound command may be
+// named, as in "coproc NAME { ...; }"; otherwise words[i] is the command.
+func isCoprocName(words []word, i int) bool {
+	if words[i].op || !isName(words[i].text) || i+1 == len(words) {
+		return false
+	}
+	switch next := words[i+1]; next.text {
+	case "(":
+		return next.op
+	case "{", "while", "until", "if", "for", "case", "select", "[[":
+		return !next.op
+	}
+	return false
+}
+
+// commandWords returns the name of every simple command in words, including
+// those in command substitutions, skipping reserved words and leading
+// variable assignments.
+func commandWords(words []word) []word {
+	var cmds []word
+	for i, isCmd := range commandPositions(words) {
+		w := words[i]
+		_, reserved := reservedWords[w.text]
+		if isCmd && !isAssignment(w.text) && !isFuncDefName(words, i) && !reserved && !isArithmeticCommand(w) {
+			cmds = append(cmds, w)
+		}
+		for _, sub := range substitutionWords(w) {
+			cmds = append(cmds, commandWords(sub)...)
+		}
+	}
+	return cmds
+}
+
+// isArithmeticCommand reports whether w is an arithmetic command, as in
+// (( i++ )) or the header of for (( i = 0; i < n; i++ )).
+func isArithmeticCommand(w word) bool {
+	return !w.op && strings.HasPrefix(w.text, "((")
+}
+
+// isProcessSubstitution reports whether w is a process substitution, as in
+// <(sort a) or >(tee log).
+func isProcessSubstitution(w word) bool {
+	return !w.op && len(w.text) > 1 && (w.text[0] == '<' || w.text[0] == '>') && w.text[1] == '('
+}
+
+// substitutionWords returns the words of each command or process
+// substitution in w, such as the contents of $(...), `...` or <(...), with
+// offsets in the same text as w's.
+func substitutionWords(w word) [][]word {
+	if w.op || !strings.Contains(w.text, "$(") && !strings.Contains(w.text, "`") && !isProcessSubstitution(w) {
+		return nil
+	}
+	var subs [][]word
+	add := func(start, end int) {
+		words := splitWords(w.text[start:end])
+		for i := range words {
+			words[i].start += w.start + start
+			words[i].end += w.start + start
+		}
+		subs = append(subs, words)
+	}
+	text := w.text
+	quoted := false
+	for i := 0; i < len(text); i++ {
+		switch ch := text[i]; {
+		case ch == '\\':
+			i++
+		case ch == '"':
+			quoted = !quoted
+		case ch == '\'' && !quoted:
+			i = scanSingleQuoted(text, i) - 1
+		case ch == '`':
+			j := i + 1
+			for j < len(text) && text[j] != '`' {
+				if text[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			if j > len(text) {
+				j = len(text)
+			}
+			add(i+1, j)
+			i = j
+		case i == 0 && isProcessSubstitution(w):
+			end := scanSubstitution(text, 1)
+			close := end
+			if close > 2 && text[close-1] == ')' {
+				close--
+			}
+			add(2, close)
+			i = end - 1
+		case ch == '$' && i+1 < len(text) && (text[i+1] == '(' || text[i+1] == '{'):
+			end := scanSubstitution(text, i+1)
+			if text[i+1] == '(' && !strings.HasPrefix(text[i:], "$((") {
+				close := end
+				if close > i+2 && text[close-1] == ')' {
+					close--
+				}
+				add(i+2, close)
+			}
+			i = end - 1
+		}
+	}
+	return subs
+}
+
+// commandArgs returns the arguments of the simple command whose name is
+// cmd, which must be one of words or in a command substitution in one of
+// them. Redirections are skipped.
+func commandArgs(words []word, cmd word) []word {
+	for _, w := range words {
+		if w.start >= cmd.start || w.end < cmd.end {
+			continue
+		}
+		// cmd is in a command substitution in w.
+		for _, sub := range substitutionWords(w) {
+			if len(sub) > 0 && sub[0].start <= cmd.start && cmd.end <= sub[len(sub)-1].end {
+				return commandArgs(sub, cmd)
+			}
+		}
+	}
+
+	var args []word
+	for i, w := range words {
+		if w.start <= cmd.start {
+			continue
+		}
+		if w.isControlOp() {
+			break
+		}
+		if w.op || words[i-1].op || isIONumber(words, i) {
+			continue
+		}
+		args = append(args, w)
+	}
+	return args
+}
+
+// isAssignment reports whether s has the form NAME=value, NAME+=value or
+// NAME[subscript]=value.
+func isAssignment(s string) bool {
+	return assignmentName(s) != ""
+}
+
+// assignmentName returns the name of the variable that the assignment s
+// assigns to, or "" if s is not an assignment.
+func assignmentName(s string) string {
+	n := 0
+	for n < len(s) && isNameChar(s[n], n == 0) {
+		n++
+	}
+	if n == 0 {
+		return ""
+	}
+	rest := s[n:]
+	if strings.HasPrefix(rest, "[") {
+		close := strings.IndexByte(rest, ']')
+		if close < 0 {
+			return ""
+		}
+		rest = rest[close+1:]
+	}
+	if strings.HasPrefix(rest, "=") || strings.HasPrefix(rest, "+=") {
+		return s[:n]
+	}
+	return ""
+}
+
+// isName reports whether s is a valid shell variable name.
+func isName(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if !isNameChar(s[i], i == 0) {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// ansiCEscapes are the single-character escapes of ANSI-C quoted strings.
+var ansiCEscapes = map[byte]byte{
+	'a': '\a', 'b': '\b', 'e': 0x1b, 'E': 0x1b, 'f': '\f', 'n': '\n', 'r': '\r',
+	't': '\t', 'v': '\v', '\\': '\\', '\'': '\'', '"': '"', '?': '?',
+}
+
+// decodeANSIC decodes the escapes in s, the contents of an ANSI-C quoted
+// string: single characters, as in \n, octal bytes, as in \101, and
+// hexadecimal bytes, as in \x41. Other escapes are left as they are.
+func decodeANSIC(s string) string {
+	var b bytes.Buffer
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 == len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+		if ch, ok := ansiCEscapes[s[i]]; ok {
+			b.WriteByte(ch)
+			continue
+		}
+		digits, base, max, j := "01234567", 8, 3, i
+		if s[i] == 'x' {
+			digits, base, max, j = "0123456789abcdef", 16, 2, i+1
+		}
+		k := j
+		for k < len(s) && k-j < max && strings.IndexByte(digits, s[k]|0x20) >= 0 {
+			k++
+		}
+		if k == j {
+			b.WriteByte('\\')
+			b.WriteByte(s[i])
+			continue
+		}
+		n, _ := strconv.ParseUint(s[j:k], base, 16)
+		b.WriteByte(byte(n))
+		i = k - 1
+	}
+	return b.String()
+}
+
+// isNameChar reports whether ch may appear in a variable name, at its start
+// if first is set.
+func isNameChar(ch byte, first bool) bool {
+	return ch == '_' || 'a' <= ch && ch <= 'z' || 'A' <= ch && ch <= 'Z' || !first && '0' <= ch && ch <= '9'
+}
+
+// unquote removes shell quoting from a word that contains no expansions,
+// decoding the escapes of ANSI-C quoted strings, as in $'\x6cs'.
+func unquote(s string) string {
+	var b bytes.Buffer
+	for i := 0; i < len(s); i++ {
+		switch ch := s[i]; ch {
+		case '$':
+			switch {
+			case i+1 < len(s) && s[i+1] == '\'':
+				end := scanSingleQuoted(s, i+1)
+				b.WriteString(decodeANSIC(strings.TrimSuffix(s[i+2:end], "'")))
+				i = end - 1
+			case i+1 < len(s) && s[i+1] == '"':
+				// A locale-specific string, $"...", is a double-quoted one.
+			default:
+				b.WriteByte(ch)
+			}
+		case '\\':
+			if i+1 < len(s) {
+				i++
+				b.WriteByte(s[i])
+			}
+		case '\'':
+			j := strings.IndexByte(s[i+1:], '\'')
+			if j < 0 {
+				b.WriteString(s[i+1:])
+				return b.String()
+			}
+			b.WriteString(s[i+1 : i+1+j])
+			i += j + 1
+		case '"':
+		default:
+			b.WriteByte(ch)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,504 @@
+package bashgraph
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// gitlabShellKeys are the keys of a GitLab CI job whose values are shell
+// commands.
+var gitlabShellKeys = map[string]bool{
+	"script":        true,
+	"before_script": true,
+	"after_script":  true,
+}
+
+// gitlabGlobalKeys are the top-level keys of GitLab CI configuration that
+// aren't jobs. The before_script and after_script of default are run by
+// every job, so default is treated as a job.
+var gitlabGlobalKeys = map[string]bool{
+	"include":   true,
+	"stages":    true,
+	"variables": true,
+	"workflow":  true,
+}
+
+// ciShellSources returns the shell commands in the CI configuration file at
+// path: the run keys of the steps of GitHub Actions jobs that run in sh or
+// bash, or the scripts of GitLab CI jobs.
+func ciShellSources(path string, data []byte) []*source {
+	doc := parseYAMLDoc(data)
+	if filepath.Base(path) == ".gitlab-ci.yml" {
+		return doc.shellSources(isGitLabScript)
+	}
+	return doc.shellSources(isGitHubRun)
+}
+
+// isGitLabScript reports whether e is a script of a GitLab CI job.
+func isGitLabScript(doc *yamlDoc, e *yamlEntry) bool {
+	job := e.parent
+	return gitlabShellKeys[e.key] && job.parent == doc.root && !gitlabGlobalKeys[job.key]
+}
+
+// isGitHubRun reports whether e is the run key of a step of a GitHub
+// Actions job, jobs.<id>.steps, that is run by sh or bash. The script input
+// of actions such as actions/github-script is JavaScript, and isn't a run
+// key of a step.
+func isGitHubRun(doc *yamlDoc, e *yamlEntry) bool {
+	steps := e.parent
+	if e.key != "run" || e.item == nil || steps.key != "steps" || steps.parent == nil {
+		return false
+	}
+	job := steps.parent
+	if job.parent == nil || job.parent.key != "jobs" || job.parent.parent != doc.root {
+		return false
+	}
+	// The shell is the step's own, or the default of the job or of the
+	// workflow.
+	shell, ok := doc.itemScalar(e, "shell")
+	if !ok {
+		shell, ok = doc.scalarAt(job, "defaults", "run", "shell")
+	}
+	if !ok {
+		shell, _ = doc.scalarAt(doc.root, "defaults", "run", "shell")
+	}
+	return isShShell(shell)
+}
+
+// isShShell reports whether the shell of a GitHub Actions step, such as
+// bash or sh -e {0}, is sh or bash. Steps without one run in bash.
+func isShShell(shell string) bool {
+	fields := strings.Fields(shell)
+	if len(fields) == 0 {
+		return true
+	}
+	name := filepath.Base(fields[0])
+	return name == "bash" || name == "sh"
+}
+
+// isCIConfigFile reports whether path is a CI configuration file that
+// embeds shell commands.
+func isCIConfigFile(path string) bool {
+	path = filepath.ToSlash(path)
+	name := filepath.Base(path)
+	if name == ".gitlab-ci.yml" {
+		return true
+	}
+	ext := filepath.Ext(name)
+	return (ext == ".yml" || ext == ".yaml") &&
+		(strings.HasPrefix(path, ".github/workflows/") || strings.Contains(path, "/.github/workflows/"))
+}
+
+// A yamlLine is a line of a YAML document without its line terminator.
+type yamlLine struct {
+	start, end int // offsets of the line in the document
+	indent     int // number of leading spaces
+}
+
+func (l yamlLine) blank(data []byte) bool {
+	s := strings.TrimSpace(string(data[l.start:l.end]))
+	return s == "" || strings.HasPrefix(s, "#")
+}
+
+func splitYAMLLines(data []byte) []yamlLine {
+	var lines []yamlLine
+	start := 0
+	for i := 0; i <= len(data); i++ {
+		if i < len(data) && data[i] != '\n' {
+			continue
+		}
+		end := i
+		if end > start && data[end-1] == '\r' {
+			end--
+		}
+		l := yamlLine{start: start, end: end}
+		for l.start+l.indent < end && data[l.start+l.indent] == ' ' {
+			l.indent++
+		}
+		lines = append(lines, l)
+		start = i + 1
+	}
+	return lines
+}
+
+// A yamlEntry is a key of a block mapping in a YAML document.
+type yamlEntry struct {
+	key string
+	// line is the index of the line the key is on, and indent the column
+	// of the key.
+	line, indent int
+	// valStart is the offset of the value on the key's line, or the end of
+	// the line if the value is on the following lines.
+	valStart int
+	// parent is the entry whose value the mapping is, or the root of the
+	// document for top-level keys.
+	parent   *yamlEntry
+	children []*yamlEntry
+	// item is the first entry of the sequence item that the mapping is,
+	// or nil if it isn't in a sequence.
+	item *yamlEntry
+}
+
+// A yamlDoc is a YAML document and the keys of its block mappings.
+//
+// This is not a YAML parser; it recognizes the line-oriented subset of YAML
+// that CI and provisioning configuration files are written in.
+type yamlDoc struct {
+	data  []byte
+	lines []yamlLine
+	// root is the parent of the top-level keys.
+	root *yamlEntry
+}
+
+// parseYAMLDoc finds the keys of the block mappings of the YAML document
+// data. The lines of scalars, such as block scalars, are not searched for
+// keys.
+func parseYAMLDoc(data []byte) *yamlDoc {
+	doc := &yamlDoc{data: data, lines: splitYAMLLines(data), root: &yamlEntry{indent: -1}}
+	stack := []*yamlEntry{doc.root}
+	// Lines indented more than skip are the rest of a scalar.
+	skip := -1
+	for i, l := range doc.lines {
+		if l.blank(data) {
+			continue
+		}
+		if skip >= 0 && l.indent > skip {
+			continue
+		}
+		skip = -1
+		e, seq := yamlKeyLine(data, l)
+		if e == nil {
+			// A scalar sequence item, or the start of a scalar.
+			skip = l.indent
+			continue
+		}
+		e.line = i
+		var sibling *yamlEntry
+		for len(stack) > 1 && stack[len(stack)-1].indent >= e.indent {
+			sibling = stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+		}
+		e.parent = stack[len(stack)-1]
+		e.parent.children = append(e.parent.children, e)
+		switch {
+		case seq:
+			e.item = e
+		case sibling != nil && sibling.indent == e.indent:
+			e.item = sibling.item
+		}
+		stack = append(stack, e)
+		if e.valStart < l.end {
+			skip = e.indent
+		}
+	}
+	return doc
+}
+
+// yamlKeyLine returns the entry of the key on line, with its indent and
+// valStart set, or nil if the line has no key. seq reports whether the key
+// is the first of a sequence item.
+func yamlKeyLine(data []byte, line yamlLine) (e *yamlEntry, seq bool) {
+	pos := line.start + line.indent
+	for pos+1 < line.end && data[pos] == '-' && data[pos+1] == ' ' {
+		seq = true
+		pos += 2
+		for pos < line.end && data[pos] == ' ' {
+			pos++
+		}
+	}
+	colon := strings.IndexByte(string(data[pos:line.end]), ':')
+	if colon <= 0 {
+		return nil, false
+	}
+	key := strings.Trim(string(data[pos:pos+colon]), `"'`)
+	valStart := pos + colon + 1
+	if valStart < line.end && data[valStart] != ' ' {
+		return nil, false
+	}
+	for valStart < line.end && data[valStart] == ' ' {
+		valStart++
+	}
+	if valStart < line.end && data[valStart] == '#' {
+		valStart = line.end
+	}
+	return &yamlEntry{key: key, indent: pos - line.start, valStart: valStart}, seq
+}
+
+// shellSources returns the scalar values of the entries of doc that
+// isShell accepts as shell sources. Entries whose values are mappings, such
+// as an Ansible command task with a cmd key, are searched instead. A value may be a plain or quoted
+// scalar, a literal or folded block scalar, or a block or flow sequence of
+// those.
+func (doc *yamlDoc) shellSources(isShell func(doc *yamlDoc, e *yamlEntry) bool) []*source {
+	var sources []*source
+	var walk func(e *yamlEntry)
+	walk = func(e *yamlEntry) {
+		for _, c := range e.children {
+			if len(c.children) == 0 && isShell(doc, c) {
+				sources = append(sources, doc.values(c)...)
+				continue
+			}
+			walk(c)
+		}
+	}
+	walk(doc.root)
+	return sources
+}
+
+// values returns the scalar value of e, or the scalars of the sequence that
+// is its value, as sources.
+func (doc *yamlDoc) values(e *yamlEntry) []*source {
+	data, lines := doc.data, doc.lines
+	if e.valStart < lines[e.line].end {
+		srcs, _ := yamlScalar(data, lines, e.line, e.valStart, e.indent)
+		return srcs
+	}
+
+	// The value is on the following lines: a sequence of commands.
+	var sources []*source
+	for j := e.line + 1; j < len(lines); {
+		l := lines[j]
+		if l.blank(data) {
+			j++
+			continue
+		}
+		if l.indent < e.indent || data[l.start+l.indent] != '-' {
+			break
+		}
+		itemStart := l.start + l.indent + 1
+		for itemStart < l.end && data[itemStart] == ' ' {
+			itemStart++
+		}
+		srcs, next := yamlScalar(data, lines, j, itemStart, l.indent)
+		sources = append(sources, srcs...)
+		j = next
+	}
+	return sources
+}
+
+// scalar returns the text of the scalar value of e, and whether it has one.
+func (doc *yamlDoc) scalar(e *yamlEntry) (string, bool) {
+	if e.valStart >= doc.lines[e.line].end {
+		return "", false
+	}
+	srcs, _ := yamlScalar(doc.data, doc.lines, e.line, e.valStart, e.indent)
+	if len(srcs) != 1 {
+		return "", false
+	}
+	return srcs[0].text, true
+}
+
+// scalarAt returns the scalar value of the entry at the path of keys below
+// e, and whether there is one.
+func (doc *yamlDoc) scalarAt(e *yamlEntry, keys ...string) (string, bool) {
+	for _, key := range keys {
+		var next *yamlEntry
+		for _, c := range e.children {
+			if c.key == key {
+				next = c
+			}
+		}
+		if next == nil {
+			return "", false
+		}
+		e = next
+	}
+	return doc.scalar(e)
+}
+
+// itemScalar returns the scalar value of the named key of the sequence item
+// that e is in, and whether there is one.
+func (doc *yamlDoc) itemScalar(e *yamlEntry, key string) (string, bool) {
+	for _, c := range e.parent.children {
+		if c.item == e.item && c.key == key {
+			return doc.scalar(c)
+		}
+	}
+	return "", false
+}
+
+// yamlShellSources returns the scalar values of the given keys in a YAML
+// document, at any depth, as shell sources.
+func yamlShellSources(data []byte, keys map[string]bool) []*source {
+	return parseYAMLDoc(data).shellSources(func(doc *yamlDoc, e *yamlEntry) bool {
+		return keys[e.key]
+	})
+}
+
+// yamlScalar extracts the scalar starting at offset start on lines[i], whose
+// parent node is indented by indent, or the scalars of the flow sequence
+// starting there. It returns the scalars as sources (none if the value is
+// not a string) and the index of the first line after them.
+func yamlScalar(data []byte, lines []yamlLine, i, start, indent int) ([]*source, int) {
+	line := lines[i]
+	switch data[start] {
+	case '|', '>':
+		src, next := yamlBlockScalar(data, lines, i+1, indent, data[start] == '>')
+		if src == nil {
+			return nil, next
+		}
+		return []*source{src}, next
+	case '"', '\'':
+		src, j, _ := yamlQuoted(data, lines, i, start)
+		if src == nil {
+			return nil, len(lines)
+		}
+		return []*source{src}, j + 1
+	case '[':
+		return yamlFlowSequence(data, lines, i, start)
+	case '{':
+		return nil, i + 1
+	}
+
+	end := line.end
+	if c := strings.Index(string(data[start:end]), " #"); c >= 0 {
+		end = start + c
+	}
+	for end > start && data[end-1] == ' ' {
+		end--
+	}
+	var b sourceBuilder
+	b.add(data, start, end)
+	return []*source{b.source()}, i + 1
+}
+
+// yamlQuoted extracts the quoted scalar starting at offset start on
+// lines[i]. It returns the scalar as a source, the index of the line it
+// ends on and the offset after its closing quote, or a nil source if it is
+// not closed.
+func yamlQuoted(data []byte, lines []yamlLine, i, start int) (*source, int, int) {
+	quote := data[start]
+	var b sourceBuilder
+	for j := i; j < len(lines); j++ {
+		from := start + 1
+		if j > i {
+			from = lines[j].start + lines[j].indent
+			// The line break and indentation fold into a space.
+			b.addDecoded(" ", from, from)
+		}
+		for k := from; k < lines[j].end; k++ {
+			c := data[k]
+			if c == quote {
+				if quote == '\'' && k+1 < lines[j].end && data[k+1] == '\'' {
+					b.addDecoded("'", k, k+2)
+					k++
+					continue
+				}
+				b.end = k
+				return b.source(), j, k + 1
+			}
+			if quote == '"' && c == '\\' && k+1 < lines[j].end {
+				k++
+				switch data[k] {
+				case 'n':
+					b.addDecoded("\n", k-1, k+1)
+				case 't':
+					b.addDecoded("\t", k-1, k+1)
+				default:
+					b.addDecoded(string(data[k]), k-1, k+1)
+				}
+				continue
+			}
+			b.addByte(c, k)
+		}
+	}
+	return nil, len(lines), 0
+}
+
+// yamlFlowSequence extracts the plain and quoted scalars of the flow
+// sequence starting at offset start on lines[i], as in ["make", make test].
+// Nested collections end the sequence, since they aren't commands. It
+// returns the scalars as sources and the index of the first line after the
+// sequence.
+func yamlFlowSequence(data []byte, lines []yamlLine, i, start int) ([]*source, int) {
+	var sources []*source
+	j, pos := i, start+1
+	for j < len(lines) {
+		l := lines[j]
+		if pos >= l.end || data[pos] == '#' && (pos == l.start || data[pos-1] == ' ') {
+			if j++; j < len(lines) {
+				pos = lines[j].start
+			}
+			continue
+		}
+		switch data[pos] {
+		case ' ', '\t', ',':
+			pos++
+		case ']', '[', '{':
+			return sources, j + 1
+		case '"', '\'':
+			src, end, next := yamlQuoted(data, lines, j, pos)
+			if src == nil {
+				return sources, len(lines)
+			}
+			sources = append(sources, src)
+			j, pos = end, next
+		default:
+			end := pos
+			for end < l.end && data[end] != ',' && data[end] != ']' && !(data[end] == '#' && data[end-1] == ' ') {
+				end++
+			}
+			itemEnd := end
+			for itemEnd > pos && data[itemEnd-1] == ' ' {
+				itemEnd--
+			}
+			var b sourceBuilder
+			b.add(data, pos, itemEnd)
+			sources = append(sources, b.source())
+			pos = end
+		}
+	}
+	return sources, len(lines)
+}
+
+// yamlBlockScalar extracts the block scalar whose content starts on
+// lines[i] and is indented more than indent. In a folded scalar, the line
+// breaks between lines of the same indentation fold into spaces, as YAML
+// does, so that a command split over several lines is read as one; lines
+// that are more indented, and the breaks around them, are kept.
+func yamlBlockScalar(data []byte, lines []yamlLine, i, indent int, folded bool) (*source, int) {
+	blockIndent := -1
+	var b sourceBuilder
+	// blanks counts the blank lines since the last line of content, and
+	// lastBreak is the offset of the line break that ended it, or -1.
+	blanks, lastBreak, lastMore := 0, -1, false
+	j := i
+	for ; j < len(lines); j++ {
+		l := lines[j]
+		if l.start+l.indent == l.end {
+			// Blank lines belong to the block regardless of indentation.
+			if blockIndent >= 0 {
+				blanks++
+			}
+			continue
+		}
+		if l.indent <= indent {
+			break
+		}
+		if blockIndent < 0 {
+			blockIndent = l.indent
+		}
+		if l.indent < blockIndent {
+			break
+		}
+		more := l.indent > blockIndent
+		if lastBreak >= 0 {
+			switch {
+			case folded && blanks == 0 && !more && !lastMore:
+				b.addDecoded(" ", lastBreak, lastBreak)
+			case folded && !more && !lastMore:
+				// The break before blank lines is folded away.
+				b.addDecoded(strings.Repeat("\n", blanks), lastBreak, lastBreak)
+			default:
+				b.addDecoded(strings.Repeat("\n", blanks+1), lastBreak, lastBreak)
+			}
+		}
+		b.add(data, l.start+blockIndent, l.end)
+		blanks, lastBreak, lastMore = 0, l.end, more
+	}
+	if blockIndent < 0 {
+		return nil, j
+	}
+	b.addByte('\n', lastBreak)
+	return b.source(), j
+}
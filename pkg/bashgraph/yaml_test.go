@@ -0,0 +1,86 @@
+package bashgraph
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCIShellSources(t *testing.T) {
+	tests := []struct {
+		name, data string
+		want       []string
+	}{
+		{
+			name: ".github/workflows/build.yml",
+			data: "jobs:\n  build:\n    steps:\n      - run: >-\n          tar -czf x.tgz\n          dist\n\n          ls -l\n            x.tgz\n",
+			want: []string{"tar -czf x.tgz dist\nls -l\n  x.tgz\n"},
+		},
+		{
+			name: ".github/workflows/build.yml",
+			data: "jobs:\n  build:\n    steps:\n    - run: |\n        make\n        make test\n",
+			want: []string{"make\nmake test\n"},
+		},
+		{
+			// The script input of github-script is JavaScript.
+			name: ".github/workflows/comment.yml",
+			data: "jobs:\n  comment:\n    steps:\n      - uses: actions/github-script@v7\n        with:\n          script: |\n            github.rest.issues.createComment()\n      - run: echo done\n",
+			want: []string{"echo done"},
+		},
+		{
+			// Steps run by other shells, and run keys that aren't those of
+			// steps, aren't shell commands.
+			name: ".github/workflows/build.yml",
+			data: "env:\n  run: echo env\njobs:\n  build:\n    env:\n      run: echo job\n    steps:\n      - shell: pwsh\n        run: Get-ChildItem\n      - run: print('hi')\n        shell: python\n      - run: echo step\n        env: {run: x}\n        shell: bash -e {0}\n      - with:\n          run: echo input\n",
+			want: []string{"echo step"},
+		},
+		{
+			// The default shell of the workflow and of the job.
+			name: ".github/workflows/build.yml",
+			data: "defaults:\n  run:\n    shell: pwsh\njobs:\n  windows:\n    steps:\n      - run: Get-ChildItem\n      - run: ls\n        shell: sh\n  linux:\n    defaults:\n      run:\n        shell: bash\n    steps:\n      - run: make\n",
+			want: []string{"ls", "make"},
+		},
+		{
+			name: ".gitlab-ci.yml",
+			data: "variables:\n  script: x\ndefault:\n  before_script: [setup]\ntest:\n  variables:\n    script: y\n  script: make\n",
+			want: []string{"setup", "make"},
+		},
+		{
+			name: ".gitlab-ci.yml",
+			data: "test:\n  before_script: [\"npm ci\", 'echo ''ready''']\n  script: [make, make test  # all\n    , ./deploy.sh]\n",
+			want: []string{"npm ci", "echo 'ready'", "make", "make test", "./deploy.sh"},
+		},
+		{
+			name: ".gitlab-ci.yml",
+			data: "test:\n  script: [[nested], make]\n  after_script:\n    - rm -rf tmp\n",
+			want: []string{"rm -rf tmp"},
+		},
+	}
+	for _, test := range tests {
+		sources, err := extractSources(test.name, []byte(test.data))
+		if err != nil {
+			t.Errorf("%s: %s", test.name, err)
+			continue
+		}
+		var texts []string
+		for _, src := range sources {
+			texts = append(texts, src.text)
+		}
+		if !reflect.DeepEqual(texts, test.want) {
+			t.Errorf("%s: got sources %q, want %q", test.name, texts, test.want)
+		}
+	}
+}
+
+func TestFoldedScalarOffsets(t *testing.T) {
+	data := "jobs:\n  build:\n    steps:\n      - run: >-\n          tar -czf x.tgz\n          dist\n"
+	sources := ciShellSources(".github/workflows/build.yml", []byte(data))
+	if len(sources) != 1 {
+		t.Fatalf("got %d sources, want 1", len(sources))
+	}
+	src := sources[0]
+	i := strings.Index(src.text, "dist")
+	if got, want := src.fileOffset(i), strings.Index(data, "dist"); got != want {
+		t.Errorf("dist is at %d in the file, want %d", got, want)
+	}
+}
@@ -0,0 +1,81 @@
+// Package cli is the srclib-bash command line tool, whose commands run the
+// analysis of the bashgraph package. Programs that compile in resolvers of
+// their own run it with Main.
+package cli
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/jessevdk/go-flags"
+	"sourcegraph.com/sourcegraph/srclib-bash/pkg/bashgraph"
+)
+
+// The global options, which every command takes.
+var (
+	cacheOpts bashgraph.CacheOptions
+	logOpts   bashgraph.LogOptions
+)
+
+// newParser returns the parser of the command line, with the global options
+// and the commands.
+func newParser() (*flags.Parser, error) {
+	p := flags.NewNamedParser("srclib-bash", flags.HelpFlag|flags.PassDoubleDash)
+	p.LongDescription = "srclib-bash performs bash script analysis."
+	if _, err := p.AddGroup("Cache Options", "", &cacheOpts); err != nil {
+		return nil, err
+	}
+	if _, err := p.AddGroup("Logging Options", "", &logOpts); err != nil {
+		return nil, err
+	}
+	for _, c := range commands {
+		if err := addCommand(p.Command, c); err != nil {
+			return nil, err
+		}
+	}
+	// The global options apply once they are all parsed, before the
+	// command runs.
+	p.CommandHandler = func(cmd flags.Commander, args []string) error {
+		bashgraph.SetGlobalOptions(cacheOpts, logOpts)
+		if cmd == nil {
+			return nil
+		}
+		return cmd.Execute(args)
+	}
+	return p, nil
+}
+
+// addCommand adds c and its subcommands to parent.
+func addCommand(parent *flags.Command, c command) error {
+	cmd, err := parent.AddCommand(c.name, c.short, c.long, c.data)
+	if err != nil {
+		return err
+	}
+	for _, sub := range c.subcommands {
+		if err := addCommand(cmd, sub); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Main runs the srclib-bash command line tool with the arguments in
+// os.Args, exiting with status 1 if the command fails.
+func Main() {
+	log.SetFlags(0)
+	p, err := newParser()
+	if err != nil {
+		log.Fatal(err)
+	}
+	if _, err := p.Parse(); err != nil {
+		if ferr, ok := err.(*flags.Error); ok && ferr.Type == flags.ErrHelp {
+			fmt.Fprintln(os.Stderr, err)
+		} else {
+			// Errors are logged following the logging options.
+			bashgraph.SetGlobalOptions(cacheOpts, logOpts)
+			bashgraph.LogError(err)
+		}
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,28 @@
+package cli
+
+import "testing"
+
+func TestNewParser(t *testing.T) {
+	p, err := newParser()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range commands {
+		cmd := p.Find(c.name)
+		if cmd == nil {
+			t.Errorf("command %s isn't registered", c.name)
+			continue
+		}
+		for _, sub := range c.subcommands {
+			if cmd.Find(sub.name) == nil {
+				t.Errorf("command %s %s isn't registered", c.name, sub.name)
+			}
+		}
+	}
+	if _, err := p.ParseArgs([]string{"--log-level", "warn", "cache", "stats", "--json", "--help"}); err == nil {
+		t.Error("parsing --help didn't stop with the help message")
+	}
+	if logOpts.LogLevel != "warn" {
+		t.Errorf("got log level %q, want warn", logOpts.LogLevel)
+	}
+}
@@ -0,0 +1,185 @@
+package cli
+
+import "sourcegraph.com/sourcegraph/srclib-bash/pkg/bashgraph"
+
+// A command is a command of srclib-bash, which go-flags runs with the
+// arguments left after its flags, and its subcommands.
+type command struct {
+	name, short, long string
+	data              interface{}
+	subcommands       []command
+}
+
+// commands are the commands of srclib-bash.
+var commands = []command{
+	{
+		name:  "cache",
+		short: "manage the cache of analyzed files",
+		long:  "Manage the cache of analyzed files that all commands share, in the directory given by --cache-dir.",
+		data:  &bashgraph.CacheCmd{},
+		subcommands: []command{
+			{
+				name:  "clean",
+				short: "remove cached files",
+				long:  "Remove the entries of the cache of analyzed files, or only those unused for the given duration.",
+				data:  &bashgraph.CacheCleanCmd{},
+			},
+			{
+				name:  "stats",
+				short: "show the size of the cache",
+				long:  "Show the directory of the cache of analyzed files, how many entries it holds and their size.",
+				data:  &bashgraph.CacheStatsCmd{},
+			},
+		},
+	},
+	{
+		name:  "coverage",
+		short: "report how much of the code the analyzer recognizes",
+		long:  "Graph the source units read from STDIN and report, for each file, the fraction of the non-whitespace bytes of its shell code covered by recognized constructs (defs, refs, strings and comments), and how much was skipped.",
+		data:  &bashgraph.CoverageCmd{},
+	},
+	{
+		name:  "deadcode",
+		short: "find functions that are never called",
+		long:  "Find the functions in the source units read from STDIN that are never called, producing diagnostic annotations.",
+		data:  &bashgraph.DeadcodeCmd{},
+	},
+	{
+		name:  "deps-graph",
+		short: "output the graph of which scripts source which",
+		long:  "Output the graph of source statements between the files of the source units read from STDIN, along with any cycles in it.",
+		data:  &bashgraph.DepsGraphCmd{},
+	},
+	{
+		name:  "duplicates",
+		short: "find functions defined in more than one file",
+		long:  "Find the functions that are defined in more than one file of the source units read from STDIN, producing a diagnostic annotation for every definition.",
+		data:  &bashgraph.DuplicatesCmd{},
+	},
+	{
+		name:  "env-vars",
+		short: "list the environment variables the scripts read and export",
+		long:  "List, for each source unit read from STDIN, the environment variables its scripts read or export, whether they are defined in the unit, and where they are read and exported.",
+		data:  &bashgraph.EnvVarsCmd{},
+	},
+	{
+		name:  "graph",
+		short: "graph a Bash script",
+		long:  "Graph a Bash script, producing all defs, refs, and docs.",
+		data:  &bashgraph.GraphCmd{},
+	},
+	{
+		name:  "diff",
+		short: "compare two graph outputs",
+		long:  "Compare the graph outputs in the files OLD and NEW, listing the defs and refs that were added, removed or moved, regardless of their order.",
+		data:  &bashgraph.DiffCmd{},
+	},
+	{
+		name:  "images",
+		short: "list the container images scripts use",
+		long:  "List the container images that the files of the source units read from STDIN run or pull, as in docker run IMAGE, docker pull IMAGE and FROM IMAGE lines of Dockerfiles written by scripts.",
+		data:  &bashgraph.ImagesCmd{},
+	},
+	{
+		name:  "impact",
+		short: "find scripts and functions affected by changed files",
+		long:  "Find the files and functions in the source units read from STDIN that are transitively affected by changes to the files given as arguments (or changed in a git revision range), through source statements and function calls.",
+		data:  &bashgraph.ImpactCmd{},
+	},
+	{
+		name:  "index",
+		short: "scan, resolve deps and graph in one step",
+		long:  "Scan the current directory and graph the source units found, several at once, writing each unit with its resolved deps and graph output as one JSON document. The source tree config is read from STDIN, as scan reads it.",
+		data:  &bashgraph.IndexCmd{},
+	},
+	{
+		name:  "lint",
+		short: "lint Bash scripts with ShellCheck",
+		long:  "Run ShellCheck over the files of the source units read from STDIN, producing diagnostic annotations.",
+		data:  &bashgraph.LintCmd{},
+	},
+	{
+		name:  "lsp",
+		short: "run a language server",
+		long:  "Run a Language Server Protocol server on STDIN and STDOUT, offering go-to-definition, find-references, document symbols and hover for the Bash scripts in the workspace.",
+		data:  &bashgraph.LSPCmd{},
+	},
+	{
+		name:  "man-coverage",
+		short: "report which external commands are linked to man pages",
+		long:  "List every external command run in the source units read from STDIN, most used first, along with the man page it is linked to, if any.",
+		data:  &bashgraph.ManCoverageCmd{},
+	},
+	{
+		name:  "metrics",
+		short: "compute shell code metrics",
+		long:  "Compute per-file and per-function metrics for the source units read from STDIN.",
+		data:  &bashgraph.MetricsCmd{},
+	},
+	{
+		name:  "network",
+		short: "report which scripts reach the network",
+		long:  "List the commands that reach the network, such as curl, ssh and dig, in each file of the source units read from STDIN, with the literal URLs and hosts they are given.",
+		data:  &bashgraph.NetworkCmd{},
+	},
+	{
+		name:  "privileged",
+		short: "report which scripts require elevated privileges",
+		long:  "List the operations that require elevated privileges, such as sudo, setcap, chown root and writes to system paths, in each file of the source units read from STDIN.",
+		data:  &bashgraph.PrivilegedCmd{},
+	},
+	{
+		name:  "query",
+		short: "look up defs and refs in a SQLite index",
+		long:  "List the defs in the SQLite index written by graph --sqlite or index --sqlite whose names match the given glob pattern, or all defs if no pattern is given. With --refs, list the refs to those defs instead.",
+		data:  &bashgraph.QueryCmd{},
+	},
+	{
+		name:  "refs",
+		short: "list references to a def",
+		long:  "List the references to the def with the given DefPath, or to the function NAME defined in FILE. The graph output cached by srclib for the current commit is used if available; otherwise the source units read from STDIN are graphed.",
+		data:  &bashgraph.RefsCmd{},
+	},
+	{
+		name:  "sbom",
+		short: "list the external commands each unit runs as JSON",
+		long:  "Output, for each source unit read from STDIN, the external commands its scripts run, how often and in which files, and whether each resolves to a known package: a command map or resolver target, or a man page.",
+		data:  &bashgraph.SBOMCmd{},
+	},
+	{
+		name:  "scan",
+		short: "scan for Bash scripts",
+		long:  "Scan the directory tree rooted at the current directory for Bash scripts.",
+		data:  &bashgraph.ScanCmd{},
+	},
+	{
+		name:  "selftest",
+		short: "check that this toolchain binary works",
+		long:  "Graph a small fixture script built into the binary and check that the output matches the expected defs and refs, to confirm that a deployed binary works. It doesn't read standard input or any files.",
+		data:  &bashgraph.SelftestCmd{},
+	},
+	{
+		name:  "serve",
+		short: "serve scan and graph requests",
+		long:  "Serve scan and graph requests as JSON-RPC 2.0 messages, one per line, on STDIN and STDOUT or a unix socket, keeping parsed files between requests.",
+		data:  &bashgraph.ServeCmd{},
+	},
+	{
+		name:  "symbols",
+		short: "list defs matching a pattern",
+		long:  "List the defs (functions, variables and aliases) whose names match the given glob pattern, or all defs if no pattern is given. Like refs, the graph output cached by srclib is used if available.",
+		data:  &bashgraph.SymbolsCmd{},
+	},
+	{
+		name:  "validate",
+		short: "check graph output invariants",
+		long:  "Graph the source units read from STDIN (or read their graph output from a file) and check that the output is consistent: files exist, spans are within their files, def refs have defs and DefPaths are unique. With --schema, also check that the units and graph output survive a round trip through the srclib types and pass their stricter checks. Violations are output as JSON.",
+		data:  &bashgraph.ValidateCmd{},
+	},
+	{
+		name:  "varcheck",
+		short: "find unused and undefined variables",
+		long:  "Find the variables in the source units read from STDIN that are assigned but never read, or read but never assigned, producing a diagnostic annotation for each.",
+		data:  &bashgraph.VarcheckCmd{},
+	},
+}